@@ -2,14 +2,28 @@ package main
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	anz "github.com/dmdhrumilmistry/defect-detect/pkg/analyzer"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/blobstore"
 	"github.com/dmdhrumilmistry/defect-detect/pkg/config"
 	"github.com/dmdhrumilmistry/defect-detect/pkg/db"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/service/attestation"
 	"github.com/dmdhrumilmistry/defect-detect/pkg/service/auth"
 	"github.com/dmdhrumilmistry/defect-detect/pkg/service/component"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/service/github"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/service/job"
 	"github.com/dmdhrumilmistry/defect-detect/pkg/service/project"
 	"github.com/dmdhrumilmistry/defect-detect/pkg/service/sbom"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/service/scan"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/service/search"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/service/vex"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
 )
@@ -30,6 +44,11 @@ func main() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
+	blobStore, err := blobstore.NewBlobStore(config.DefaultConfig, mgo.Db)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to init blob storage")
+	}
+
 	// Analyzers
 	analyzer := anz.NewAnalyzer()
 
@@ -43,21 +62,78 @@ func main() {
 	// add auth for remaining service endpoints
 	r.Use(authStore.WithJwtAuth())
 
-	sbomStore := sbom.NewComponentSbomStore(mgo.Db)
-	sbomHandler := sbom.NewComponentSbomHandler(sbomStore, authStore)
+	sbomStore := sbom.NewComponentSbomStore(mgo.Db, blobStore)
+	vexStore := vex.NewVexStore(mgo.Db)
+	componentStore := component.NewComponentStore(mgo.Db, analyzer, vexStore)
+	jobStore := job.NewJobStore(mgo.Db)
+	projectStore := project.NewProjectStore(mgo.Db)
+
+	sbomHandler := sbom.NewComponentSbomHandler(sbomStore, componentStore, projectStore, jobStore, authStore)
 	sbomHandler.RegisterRoutes(r, authStore)
 
-	componentStore := component.NewComponentStore(mgo.Db, analyzer)
-	componentHandler := component.NewComponentHandler(componentStore, sbomStore, authStore)
+	jobHandler := job.NewJobHandler(jobStore, authStore)
+	jobHandler.RegisterRoutes(r)
+
+	componentHandler := component.NewComponentHandler(componentStore, sbomStore, jobStore, authStore)
 	componentHandler.RegisterRoutes(r)
 
-	projectStore := project.NewProjectStore(mgo.Db)
-	projectHandler := project.NewProjectHandler(projectStore, sbomStore, componentStore, authStore)
+	attestationStore := attestation.NewAttestationStore(mgo.Db)
+	attestationHandler := attestation.NewAttestationHandler(attestationStore, authStore)
+	attestationHandler.RegisterRoutes(r)
+
+	projectHandler := project.NewProjectHandler(projectStore, sbomStore, componentStore, jobStore, attestationStore, authStore)
 	projectHandler.RegisterRoutes(r)
+
+	githubIntegrationStore := github.NewGithubIntegrationStore(mgo.Db)
+	githubImporter := github.NewImporter(githubIntegrationStore, sbomStore, projectStore, project.NewSbomManager(projectStore, sbomStore, componentStore, jobStore))
+	githubHandler := github.NewGithubHandler(githubIntegrationStore, githubImporter, jobStore, authStore)
+	githubHandler.RegisterRoutes(r)
+
+	vexHandler := vex.NewVexHandler(vexStore, componentStore, authStore)
+	vexHandler.RegisterRoutes(r)
+
+	searchHandler := search.NewSearchHandler(sbomStore, componentStore, authStore)
+	searchHandler.RegisterRoutes(r)
+
+	scanStore := scan.NewScanStore(mgo.Db)
+	scanHandler := scan.NewScanHandler(scanStore, authStore)
+	scanHandler.RegisterRoutes(r)
 	log.Info().Msg("Routes Registered Successfully")
 
+	// Background jobs are stopped on SIGINT or SIGTERM, interrupted jobs are resumed once their
+	// heartbeat goes stale
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	scheduler := scan.NewScheduler(scanStore, projectStore, sbomStore, componentStore, jobStore)
+	runner := job.NewRunner(jobStore, sbomStore, componentStore, githubImporter, scheduler)
+
+	var background sync.WaitGroup
+	background.Add(2)
+	go func() {
+		defer background.Done()
+		scheduler.Start(ctx)
+	}()
+	go func() {
+		defer background.Done()
+		runner.Start(ctx)
+	}()
+
 	// Start the server
-	if err := r.Run(":" + config.DefaultConfig.HostPort); err != nil {
-		log.Fatal().Err(err).Msgf("Failed to start server on port %s", config.DefaultConfig.HostPort)
+	server := &http.Server{Addr: ":" + config.DefaultConfig.HostPort, Handler: r}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal().Err(err).Msgf("Failed to start server on port %s", config.DefaultConfig.HostPort)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Info().Msg("Shutting down server")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Error().Err(err).Msg("failed to shutdown server gracefully")
 	}
+	background.Wait()
 }
@@ -1,6 +1,17 @@
 package analyzer
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/dmdhrumilmistry/defect-detect/pkg/analyzer/distro"
 	"github.com/dmdhrumilmistry/defect-detect/pkg/analyzer/epss"
 	"github.com/dmdhrumilmistry/defect-detect/pkg/analyzer/mpaf"
 	"github.com/dmdhrumilmistry/defect-detect/pkg/analyzer/osv"
@@ -9,14 +20,19 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+// source of vulns detected by osv analyzer
+var osvVulnSource = types.VulnSource{Origin: types.VulnOriginAnalyzer, Name: "osv"}
+
 type Analyzer struct {
-	RunOsv  bool
-	RunMpaf bool
-	RunEpss bool
+	RunOsv    bool
+	RunMpaf   bool
+	RunEpss   bool
+	RunDistro bool
 
-	OsvAnalyzer  *osv.OsvAnalyzer
-	MpafAnalyzer *mpaf.MpafAnalyzer
-	EpssAnalyzer *epss.EpssAnalyzer
+	OsvAnalyzer    *osv.OsvAnalyzer
+	MpafAnalyzer   *mpaf.MpafAnalyzer
+	EpssAnalyzer   *epss.EpssAnalyzer
+	DistroAnalyzer *distro.DistroAnalyzer
 }
 
 func NewAnalyzer() *Analyzer {
@@ -27,21 +43,30 @@ func NewAnalyzer() *Analyzer {
 
 	return &Analyzer{
 		// config
-		RunOsv:  config.DefaultConfig.RunOsv,
-		RunMpaf: config.DefaultConfig.RunMpaf,
-		RunEpss: config.DefaultConfig.RunEpss,
+		RunOsv:    config.DefaultConfig.RunOsv,
+		RunMpaf:   config.DefaultConfig.RunMpaf,
+		RunEpss:   config.DefaultConfig.RunEpss,
+		RunDistro: config.DefaultConfig.RunDistro,
 
 		// Analyzers
-		OsvAnalyzer:  osv.NewOsvAnalyzer(),
-		MpafAnalyzer: mpafAnalyzer,
-		EpssAnalyzer: epss.NewEpssAnalyzer(),
+		OsvAnalyzer:    osv.NewOsvAnalyzer(),
+		MpafAnalyzer:   mpafAnalyzer,
+		EpssAnalyzer:   epss.NewEpssAnalyzer(),
+		DistroAnalyzer: distro.NewDistroAnalyzer(config.DefaultConfig.DebianTrackerFile, config.DefaultConfig.AlpineSecdbDir, config.DefaultConfig.OvalDir),
 	}
 }
 
 func (a *Analyzer) GetPackageInfo(purl string) (pkgInfos []types.PackageInfo, err error) {
 	if a.RunMpaf {
+		if a.MpafAnalyzer == nil {
+			return pkgInfos, newAnalyzerErr("mpaf", fmt.Errorf("mpaf analyzer is not initialized"))
+		}
+
 		log.Info().Msgf("Fetching package info for purl: %s", purl)
-		return a.MpafAnalyzer.GetPackageInfo(purl)
+		pkgInfos, err = a.MpafAnalyzer.GetPackageInfo(purl)
+		if err != nil {
+			return pkgInfos, newAnalyzerErr("mpaf", err)
+		}
 	} else {
 		log.Error().Msgf("mpaf analyzer is not enabled. Skipping fetching package info for purl: %s", purl)
 	}
@@ -49,22 +74,140 @@ func (a *Analyzer) GetPackageInfo(purl string) (pkgInfos []types.PackageInfo, er
 	return pkgInfos, nil
 }
 
+// returns vulns detected by enabled analyzers. Errors of each analyzer are
+// wrapped in types.AnalyzerErr and joined.
 func (a *Analyzer) GetVulns(purl string) (vulns []types.Vuln, err error) {
+	var errs []error
+
 	log.Info().Msgf("Running analyzers for purl: %s", purl)
 	if a.RunOsv {
 		vulns, err = a.OsvAnalyzer.GetVulns(purl)
 		if err != nil {
 			log.Error().Err(err).Msgf("failed to retrieve osv vulns for purl: %s", purl)
+			errs = append(errs, newAnalyzerErr("osv", err))
+		}
+	}
+
+	if a.RunDistro {
+		distroVulns, fixed, err := a.DistroAnalyzer.GetVulns(purl)
+		if err != nil {
+			log.Error().Err(err).Msgf("failed to retrieve distro vulns for purl: %s", purl)
+			errs = append(errs, newAnalyzerErr("distro", err))
 		}
+		vulns = mergeDistroVulns(vulns, distroVulns, fixed)
 	}
 
 	// concurrently update epss for cvss
 	if a.RunEpss && len(vulns) > 0 {
 		log.Info().Msgf("running epss analyzer on vulns for purl: %s", purl)
-		vulns = a.EpssAnalyzer.ProcessEpssForVulns(vulns, config.DefaultConfig.DefaultWorkersCount)
+		vulns, err = a.EpssAnalyzer.ProcessEpssForVulns(vulns, config.DefaultConfig.DefaultWorkersCount)
+		if err != nil {
+			errs = append(errs, newAnalyzerErr("epss", err))
+		}
 	}
 
 	log.Info().Msgf("Completed analysis for purl: %s", purl)
 
-	return vulns, nil
+	return vulns, errors.Join(errs...)
+}
+
+// returns true when vuln id or one of its aliases is in ids
+func vulnMatchesIds(vuln types.Vuln, ids []string) bool {
+	for _, id := range append([]string{vuln.ID}, vuln.Aliases...) {
+		if id != "" && slices.Contains(ids, id) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// returns true when vulns share id or alias, related vulns are distinct issues
+func vulnsMatch(a, b types.Vuln) bool {
+	return vulnMatchesIds(b, append([]string{a.ID}, a.Aliases...))
+}
+
+// merges distro analyzer findings with osv findings. Findings reported by both keep distro details
+// as fixed versions of distro data account for backports and record osv as additional source.
+// Osv findings of advisories which distro data marks fixed in installed version are dropped.
+func mergeDistroVulns(osvVulns, distroVulns []types.Vuln, fixed []string) []types.Vuln {
+	if len(distroVulns) == 0 && len(fixed) == 0 {
+		return osvVulns
+	}
+
+	vulns := distroVulns
+	for _, osvVuln := range osvVulns {
+		matched := false
+		for i := range distroVulns {
+			if vulnsMatch(distroVulns[i], osvVuln) {
+				if !slices.Contains(vulns[i].Sources, osvVulnSource) {
+					vulns[i].Sources = append(vulns[i].Sources, osvVulnSource)
+				}
+				matched = true
+			}
+		}
+
+		if !matched && !vulnMatchesIds(osvVuln, fixed) {
+			vulns = append(vulns, osvVuln)
+		}
+	}
+
+	return vulns
+}
+
+func newAnalyzerErr(analyzer string, err error) *types.AnalyzerErr {
+	return &types.AnalyzerErr{
+		Analyzer: analyzer,
+		Class:    ClassifyError(err),
+		Err:      err,
+	}
+}
+
+// ClassifyError returns error class of analyzer error
+func ClassifyError(err error) string {
+	var statusErr *types.ApiStatusErr
+	var netErr net.Error
+	var syntaxErr *json.SyntaxError
+	var unmarshalErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.As(err, &statusErr):
+		if statusErr.StatusCode == http.StatusTooManyRequests {
+			return types.AnalyzerErrClassRateLimit
+		}
+		return types.AnalyzerErrClassHttpStatus
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return types.AnalyzerErrClassTimeout
+	case errors.As(err, &netErr):
+		return types.AnalyzerErrClassNetwork
+	case errors.As(err, &syntaxErr), errors.As(err, &unmarshalErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return types.AnalyzerErrClassDecode
+	}
+
+	return types.AnalyzerErrClassUnknown
+}
+
+// ToAnalyzerErrors flattens joined analyzer errors into storable analyzer errors
+func ToAnalyzerErrors(err error, timestamp time.Time) []types.AnalyzerError {
+	var analyzerErrors []types.AnalyzerError
+	if err == nil {
+		return analyzerErrors
+	}
+
+	if joinedErr, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joinedErr.Unwrap() {
+			analyzerErrors = append(analyzerErrors, ToAnalyzerErrors(e, timestamp)...)
+		}
+		return analyzerErrors
+	}
+
+	analyzerErr := &types.AnalyzerErr{Analyzer: "unknown", Class: ClassifyError(err), Err: err}
+	errors.As(err, &analyzerErr)
+
+	return append(analyzerErrors, types.AnalyzerError{
+		Analyzer:  analyzerErr.Analyzer,
+		Class:     analyzerErr.Class,
+		Message:   analyzerErr.Err.Error(),
+		Timestamp: timestamp,
+	})
 }
@@ -0,0 +1,28 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+)
+
+func TestMergeDistroVulns(t *testing.T) {
+	osvVulns := []types.Vuln{
+		{ID: "DSA-5532-1", Aliases: []string{"CVE-2023-5363"}},
+		{ID: "GHSA-backported", Aliases: []string{"CVE-2023-2650"}},
+		{ID: "OSV-related", Related: []string{"CVE-2023-5363"}},
+		{ID: "OSV-unknown"},
+	}
+	distroVulns := []types.Vuln{{ID: "CVE-2023-5363"}}
+	fixed := []string{"CVE-2023-2650"}
+
+	ids := []string{}
+	for _, vuln := range mergeDistroVulns(osvVulns, distroVulns, fixed) {
+		ids = append(ids, vuln.ID)
+	}
+	// aliases merge into distro finding, backported fixes are dropped and related vulns are kept
+	if want := []string{"CVE-2023-5363", "OSV-related", "OSV-unknown"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("merged vulns = %v, want %v", ids, want)
+	}
+}
@@ -0,0 +1,101 @@
+package distro
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const alpineSecurityUrl = "https://security.alpinelinux.org/vuln/"
+
+// alpineSecdbFile is a secdb file such as v3.19/main.json of https://secdb.alpinelinux.org
+type alpineSecdbFile struct {
+	DistroVersion string `json:"distroversion"`
+	Packages      []struct {
+		Pkg struct {
+			Name string `json:"name"`
+			// issue ids keyed by version fixing them, issues fixed in version 0 never affected
+			// the package
+			Secfixes map[string][]string `json:"secfixes"`
+		} `json:"pkg"`
+	} `json:"packages"`
+}
+
+// alpineSecdb holds fixed versions of issues keyed by distro version, origin package and issue id
+type alpineSecdb map[string]map[string]map[string]string
+
+// loads secdb json files of directory and its sub directories
+func loadAlpineSecdb(dir string) (alpineSecdb, error) {
+	secdb := alpineSecdb{}
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".json" {
+			return err
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var file alpineSecdbFile
+		if err := json.Unmarshal(content, &file); err != nil {
+			return err
+		}
+
+		if secdb[file.DistroVersion] == nil {
+			secdb[file.DistroVersion] = map[string]map[string]string{}
+		}
+		for _, pkg := range file.Packages {
+			fixes := secdb[file.DistroVersion][pkg.Pkg.Name]
+			if fixes == nil {
+				fixes = map[string]string{}
+				secdb[file.DistroVersion][pkg.Pkg.Name] = fixes
+			}
+
+			for version, ids := range pkg.Pkg.Secfixes {
+				for _, id := range ids {
+					// entries may list aliases such as "CVE-2023-1234 XSA-123"
+					if fields := strings.Fields(id); len(fields) > 0 {
+						fixes[strings.Join(fields, " ")] = version
+					}
+				}
+			}
+		}
+		return nil
+	})
+
+	return secdb, err
+}
+
+// returns secdb distro version such as v3.19 of alpine-3.19.1 distro qualifier
+func alpineDistroVersion(distro string) string {
+	release, ok := strings.CutPrefix(distro, "alpine-")
+	if !ok {
+		return ""
+	} else if release == "edge" {
+		return release
+	}
+
+	parts := strings.SplitN(release, ".", 3)
+	if len(parts) < 2 {
+		return ""
+	}
+	return "v" + parts[0] + "." + parts[1]
+}
+
+// returns advisories of origin package in alpine release, secdb only lists fixed issues. Issues
+// fixed in version 0 are below every installed version, so they are reported as fixed.
+func (s alpineSecdb) advisories(distroVersion, origin string) []advisory {
+	advisories := []advisory{}
+	for ids, version := range s[distroVersion][origin] {
+		fields := strings.Fields(ids)
+		advisories = append(advisories, advisory{
+			id:      fields[0],
+			aliases: fields[1:],
+			fixed:   version,
+			url:     alpineSecurityUrl + fields[0],
+		})
+	}
+
+	return advisories
+}
@@ -0,0 +1,99 @@
+package distro
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+)
+
+const debianTrackerUrl = "https://security-tracker.debian.org/tracker/"
+
+// codenames of debian releases, security tracker data is keyed by codename
+var debianCodenames = map[string]string{
+	"9":  "stretch",
+	"10": "buster",
+	"11": "bullseye",
+	"12": "bookworm",
+	"13": "trixie",
+	"14": "forky",
+}
+
+// debianRelease is status of an issue in a debian release as per security tracker json
+type debianRelease struct {
+	Status       string `json:"status"`
+	FixedVersion string `json:"fixed_version"`
+	Urgency      string `json:"urgency"`
+}
+
+type debianIssue struct {
+	Description string                   `json:"description"`
+	Releases    map[string]debianRelease `json:"releases"`
+}
+
+// debianTracker is the security tracker json of https://security-tracker.debian.org/tracker/data/json
+// keyed by source package name and issue id
+type debianTracker map[string]map[string]debianIssue
+
+func loadDebianTracker(file string) (debianTracker, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	tracker := debianTracker{}
+	return tracker, json.NewDecoder(f).Decode(&tracker)
+}
+
+// returns codename of debian-12 or debian-bookworm distro qualifier
+func debianCodename(distro string) string {
+	release, ok := strings.CutPrefix(distro, "debian-")
+	if !ok {
+		return ""
+	}
+
+	major, _, _ := strings.Cut(release, ".")
+	if codename, ok := debianCodenames[major]; ok {
+		return codename
+	}
+	return release
+}
+
+// returns severity of debian urgency, unimportant issues are reported as low
+func debianSeverity(urgency string) string {
+	switch strings.TrimSuffix(urgency, "*") {
+	case "high":
+		return "HIGH"
+	case "medium":
+		return "MEDIUM"
+	case "low", "unimportant":
+		return "LOW"
+	}
+	return ""
+}
+
+// returns advisories of source package in debian release. Open issues are returned without fixed
+// version and undetermined ones are skipped. Issues which never affected the release are returned
+// with fixed version 0, which is below every installed version, so they are reported as fixed.
+func (t debianTracker) advisories(codename, source string) []advisory {
+	advisories := []advisory{}
+	for id, issue := range t[source] {
+		release, ok := issue.Releases[codename]
+		if !ok {
+			continue
+		}
+		if release.FixedVersion != "0" && release.Status != "open" && release.Status != "resolved" {
+			continue
+		}
+
+		advisories = append(advisories, advisory{
+			id:          id,
+			description: issue.Description,
+			severity:    debianSeverity(release.Urgency),
+			fixed:       release.FixedVersion,
+			url:         debianTrackerUrl + id,
+		})
+	}
+
+	return advisories
+}
@@ -0,0 +1,202 @@
+package distro
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+	packageurl "github.com/package-url/packageurl-go"
+	"github.com/rs/zerolog/log"
+)
+
+// names of vuln sources reported by distro analyzer
+const (
+	SourceDebianTracker = "debian-security-tracker"
+	SourceAlpineSecdb   = "alpine-secdb"
+	SourceOval          = "oval"
+)
+
+// advisory is an issue affecting a distro package along with the distro version fixing it
+type advisory struct {
+	id          string
+	aliases     []string
+	description string
+	severity    string
+	fixed       string // empty when fix isn't released yet
+	url         string
+}
+
+// DistroAnalyzer detects vulns of deb, apk and rpm packages using local copies of distro security
+// data and compares versions using version ordering of the distro, so backported fixes are
+// detected correctly. Data files are loaded on first use, sources without configured path are
+// skipped.
+type DistroAnalyzer struct {
+	loadDebianTracker func() (debianTracker, error)
+	loadAlpineSecdb   func() (alpineSecdb, error)
+	loadOval          func() (ovalDefinitions, error)
+}
+
+func NewDistroAnalyzer(debianTrackerFile, alpineSecdbDir, ovalDir string) *DistroAnalyzer {
+	return &DistroAnalyzer{
+		loadDebianTracker: sync.OnceValues(func() (debianTracker, error) {
+			if debianTrackerFile == "" {
+				return nil, nil
+			}
+			log.Info().Msgf("loading debian security tracker data from %s", debianTrackerFile)
+			return loadDebianTracker(debianTrackerFile)
+		}),
+		loadAlpineSecdb: sync.OnceValues(func() (alpineSecdb, error) {
+			if alpineSecdbDir == "" {
+				return nil, nil
+			}
+			log.Info().Msgf("loading alpine secdb data from %s", alpineSecdbDir)
+			return loadAlpineSecdb(alpineSecdbDir)
+		}),
+		loadOval: sync.OnceValues(func() (ovalDefinitions, error) {
+			if ovalDir == "" {
+				return nil, nil
+			}
+			log.Info().Msgf("loading oval definitions from %s", ovalDir)
+			return loadOvalDefinitions(ovalDir)
+		}),
+	}
+}
+
+// returns version of package url, epoch qualifier is prepended to versions without epoch
+func purlVersion(version string, qualifiers map[string]string) string {
+	if epoch := qualifiers["epoch"]; epoch != "" && epoch != "0" && !strings.Contains(version, ":") {
+		return epoch + ":" + version
+	}
+	return version
+}
+
+// returns source package name and version of upstream qualifier such as openssl@3.0.11-1~deb12u2,
+// binary package name and version are used when qualifier is missing
+func purlSource(name, version string, qualifiers map[string]string) (string, string) {
+	upstream := qualifiers["upstream"]
+	if upstream == "" {
+		return name, version
+	}
+
+	sourceName, sourceVersion, ok := strings.Cut(upstream, "@")
+	if !ok {
+		return sourceName, version
+	}
+	return sourceName, purlVersion(sourceVersion, qualifiers)
+}
+
+// GetVulns returns vulns of deb, apk and rpm package urls with distro qualifier along with ids and
+// aliases of advisories already fixed in installed version, e.g. by backports, so findings of other
+// analyzers for them can be dropped. Packages of other types or unknown distros don't have any vulns.
+func (a *DistroAnalyzer) GetVulns(purl string) ([]types.Vuln, []string, error) {
+	vulns, fixed := []types.Vuln{}, []string{}
+	parsed, err := packageurl.FromString(purl)
+	if err != nil {
+		return vulns, fixed, err
+	}
+
+	qualifiers := parsed.Qualifiers.Map()
+	distro := qualifiers["distro"]
+	if distro == "" {
+		return vulns, fixed, nil
+	}
+	version := purlVersion(parsed.Version, qualifiers)
+
+	switch parsed.Type {
+	case packageurl.TypeDebian:
+		tracker, err := a.loadDebianTracker()
+		codename := debianCodename(distro)
+		if err != nil || tracker == nil || codename == "" {
+			return vulns, fixed, err
+		}
+
+		source, sourceVersion := purlSource(parsed.Name, version, qualifiers)
+		ecosystem := "Debian:" + strings.TrimPrefix(distro, "debian-")
+		vulns, fixed = affectedVulns(tracker.advisories(codename, source), CompareDebVersions, source, sourceVersion, ecosystem, purl, SourceDebianTracker)
+	case packageurl.TypeApk:
+		secdb, err := a.loadAlpineSecdb()
+		distroVersion := alpineDistroVersion(distro)
+		if err != nil || secdb == nil || distroVersion == "" {
+			return vulns, fixed, err
+		}
+
+		// secdb lists fixes of origin packages, version of origin is the same as its sub packages
+		origin, _ := purlSource(parsed.Name, version, qualifiers)
+		vulns, fixed = affectedVulns(secdb.advisories(distroVersion, origin), CompareApkVersions, origin, version, "Alpine:"+distroVersion, purl, SourceAlpineSecdb)
+	case packageurl.TypeRPM:
+		definitions, err := a.loadOval()
+		ovalDistro := ovalDistro(distro)
+		if err != nil || definitions == nil || ovalDistro == "" {
+			return vulns, fixed, err
+		}
+
+		// oval definitions check versions of binary packages
+		vulns, fixed = affectedVulns(definitions.advisories(ovalDistro, parsed.Name, version), CompareRpmVersions, parsed.Name, version, ovalDistro, purl, SourceOval)
+	}
+
+	return vulns, fixed, nil
+}
+
+// returns vulns of advisories not fixed in installed version along with ids and aliases of fixed advisories.
+// Advisory may be listed several times with different fixed versions, e.g. for each arch, it is fixed
+// when installed version reaches fixed version of any listing.
+func affectedVulns(advisories []advisory, compare func(a, b string) int, name, version, ecosystem, purl, sourceName string) ([]types.Vuln, []string) {
+	vulns, fixed := []types.Vuln{}, []string{}
+	ids := []string{}
+	listings := map[string][]advisory{}
+	for _, pkgAdvisory := range advisories {
+		if _, ok := listings[pkgAdvisory.id]; !ok {
+			ids = append(ids, pkgAdvisory.id)
+		}
+		listings[pkgAdvisory.id] = append(listings[pkgAdvisory.id], pkgAdvisory)
+	}
+
+	for _, id := range ids {
+		isFixed := slices.ContainsFunc(listings[id], func(pkgAdvisory advisory) bool {
+			return pkgAdvisory.fixed != "" && compare(version, pkgAdvisory.fixed) >= 0
+		})
+		if isFixed {
+			fixed = append(fixed, id)
+			for _, pkgAdvisory := range listings[id] {
+				fixed = append(fixed, pkgAdvisory.aliases...)
+			}
+			continue
+		}
+
+		var vuln types.Vuln
+		for i, pkgAdvisory := range listings[id] {
+			events := []types.Events{{Introduced: "0"}}
+			if pkgAdvisory.fixed != "" {
+				events = append(events, types.Events{Fixed: pkgAdvisory.fixed})
+			}
+			affected := types.Affected{
+				Package:           types.Package{Name: name, Ecosystem: ecosystem, Purl: purl},
+				Ranges:            []types.Ranges{{Type: "ECOSYSTEM", Events: events}},
+				EcosystemSpecific: types.EcosystemSpecific{Severity: pkgAdvisory.severity},
+				DatabaseSpecific:  types.DatabaseSpecific{Source: pkgAdvisory.url},
+			}
+			if i > 0 {
+				vuln.Affected = append(vuln.Affected, affected)
+				continue
+			}
+
+			vuln = types.Vuln{
+				ID:       pkgAdvisory.id,
+				Details:  pkgAdvisory.description,
+				Aliases:  append([]string{}, pkgAdvisory.aliases...),
+				Affected: []types.Affected{affected},
+				Sources:  []types.VulnSource{{Origin: types.VulnOriginAnalyzer, Name: sourceName, Url: pkgAdvisory.url}},
+			}
+			vuln.GhsaDatabaseSpecific.Severity = pkgAdvisory.severity
+			if pkgAdvisory.url != "" {
+				vuln.References = []types.References{{Type: "ADVISORY", URL: pkgAdvisory.url}}
+			}
+		}
+		vulns = append(vulns, vuln)
+	}
+	sort.Slice(vulns, func(i, j int) bool { return vulns[i].ID < vulns[j].ID })
+
+	return vulns, fixed
+}
@@ -0,0 +1,72 @@
+package distro
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAffectedVulns(t *testing.T) {
+	tests := []struct {
+		name       string
+		advisories []advisory
+		version    string
+		wantVulns  []string
+		wantFixed  []string
+	}{
+		{
+			name:       "fixed in installed version",
+			advisories: []advisory{{id: "CVE-2024-0001", aliases: []string{"RHSA-2024:0001"}, fixed: "1.2-r1"}},
+			version:    "1.2-r1",
+			wantVulns:  []string{},
+			wantFixed:  []string{"CVE-2024-0001", "RHSA-2024:0001"},
+		},
+		{
+			name:       "open advisory",
+			advisories: []advisory{{id: "CVE-2024-0001"}},
+			version:    "1.2-r1",
+			wantVulns:  []string{"CVE-2024-0001"},
+			wantFixed:  []string{},
+		},
+		{
+			// advisory listed for each arch is fixed once any listing is fixed
+			name: "listed several times",
+			advisories: []advisory{
+				{id: "CVE-2024-0001", fixed: "1.2-r2"},
+				{id: "CVE-2024-0001", fixed: "1.2-r1"},
+				{id: "CVE-2024-0002", fixed: "1.3-r0"},
+				{id: "CVE-2024-0002", fixed: "1.3-r1"},
+			},
+			version:   "1.2-r1",
+			wantVulns: []string{"CVE-2024-0002"},
+			wantFixed: []string{"CVE-2024-0001"},
+		},
+	}
+
+	for _, test := range tests {
+		vulns, fixed := affectedVulns(test.advisories, CompareApkVersions, "openssl", test.version, "Alpine:v3.20", "pkg:apk/alpine/openssl@"+test.version, SourceAlpineSecdb)
+		ids := []string{}
+		for _, vuln := range vulns {
+			ids = append(ids, vuln.ID)
+		}
+		if !slices.Equal(ids, test.wantVulns) || !slices.Equal(fixed, test.wantFixed) {
+			t.Errorf("%s: affectedVulns() = %v, %v, want %v, %v", test.name, ids, fixed, test.wantVulns, test.wantFixed)
+		}
+		for _, vuln := range vulns {
+			if vuln.ID == "CVE-2024-0002" && len(vuln.Affected) != 2 {
+				t.Errorf("%s: affected of %s = %d, want 2", test.name, vuln.ID, len(vuln.Affected))
+			}
+		}
+	}
+}
+
+// issues which never affected release are listed with fixed version 0
+func TestAffectedVulnsNeverAffected(t *testing.T) {
+	tracker := debianTracker{"openssl": {"CVE-2024-0001": {Releases: map[string]debianRelease{
+		"bookworm": {Status: "resolved", FixedVersion: "0"},
+	}}}}
+
+	vulns, fixed := affectedVulns(tracker.advisories("bookworm", "openssl"), CompareDebVersions, "openssl", "3.0.15-1~deb12u1", "Debian:12", "pkg:deb/debian/openssl@3.0.15-1~deb12u1", SourceDebianTracker)
+	if len(vulns) != 0 || !slices.Equal(fixed, []string{"CVE-2024-0001"}) {
+		t.Errorf("affectedVulns() = %v, %v, want no vulns and fixed CVE-2024-0001", vulns, fixed)
+	}
+}
@@ -0,0 +1,226 @@
+package distro
+
+import (
+	"compress/bzip2"
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// oval files are named after purl distro qualifier id and major version such as rhel-8.oval.xml,
+// rocky-9.oval.xml or almalinux-9.oval.xml.bz2
+var ovalFileRegex = regexp.MustCompile(`^([a-z]+)-(\d+)[^/]*\.xml(\.bz2)?$`)
+
+type ovalCriteria struct {
+	Criteria   []ovalCriteria `xml:"criteria"`
+	Criterions []struct {
+		TestRef string `xml:"test_ref,attr"`
+	} `xml:"criterion"`
+}
+
+type ovalDefinition struct {
+	Class    string `xml:"class,attr"`
+	Metadata struct {
+		Title      string `xml:"title"`
+		References []struct {
+			RefId  string `xml:"ref_id,attr"`
+			RefUrl string `xml:"ref_url,attr"`
+			Source string `xml:"source,attr"`
+		} `xml:"reference"`
+		Description string `xml:"description"`
+		Advisory    struct {
+			Severity string `xml:"severity"`
+		} `xml:"advisory"`
+	} `xml:"metadata"`
+	Criteria ovalCriteria `xml:"criteria"`
+}
+
+type ovalFile struct {
+	Definitions []ovalDefinition `xml:"definitions>definition"`
+	Tests       []struct {
+		Id     string `xml:"id,attr"`
+		Object struct {
+			Ref string `xml:"object_ref,attr"`
+		} `xml:"object"`
+		State struct {
+			Ref string `xml:"state_ref,attr"`
+		} `xml:"state"`
+	} `xml:"tests>rpminfo_test"`
+	Objects []struct {
+		Id   string `xml:"id,attr"`
+		Name string `xml:"name"`
+	} `xml:"objects>rpminfo_object"`
+	States []struct {
+		Id  string `xml:"id,attr"`
+		Evr struct {
+			Operation string `xml:"operation,attr"`
+			Value     string `xml:",chardata"`
+		} `xml:"evr"`
+	} `xml:"states>rpminfo_state"`
+}
+
+// ovalDefinitions holds advisories keyed by distro such as rhel-8 and package name
+type ovalDefinitions map[string]map[string][]advisory
+
+// returns tests referenced by criteria and its nested criteria. Operators of criteria aren't
+// evaluated, tests other than "package is earlier than version" checks are skipped by callers.
+func (c ovalCriteria) testRefs() []string {
+	refs := []string{}
+	for _, criterion := range c.Criterions {
+		refs = append(refs, criterion.TestRef)
+	}
+	for _, criteria := range c.Criteria {
+		refs = append(refs, criteria.testRefs()...)
+	}
+	return refs
+}
+
+// returns severity of red hat advisory severity
+func ovalSeverity(severity string) string {
+	switch strings.ToLower(severity) {
+	case "critical":
+		return "CRITICAL"
+	case "important", "high":
+		return "HIGH"
+	case "moderate", "medium":
+		return "MEDIUM"
+	case "low":
+		return "LOW"
+	}
+	return ""
+}
+
+func parseOvalFile(reader io.Reader) (map[string][]advisory, error) {
+	var file ovalFile
+	if err := xml.NewDecoder(reader).Decode(&file); err != nil {
+		return nil, err
+	}
+
+	objects := map[string]string{}
+	for _, object := range file.Objects {
+		objects[object.Id] = object.Name
+	}
+	fixedVersions := map[string]string{}
+	for _, state := range file.States {
+		if state.Evr.Operation == "less than" {
+			fixedVersions[state.Id] = strings.TrimSpace(state.Evr.Value)
+		}
+	}
+
+	type check struct{ name, fixed string }
+	checks := map[string]check{}
+	for _, test := range file.Tests {
+		name, fixed := objects[test.Object.Ref], fixedVersions[test.State.Ref]
+		if name != "" && fixed != "" {
+			checks[test.Id] = check{name: name, fixed: fixed}
+		}
+	}
+
+	advisories := map[string][]advisory{}
+	for _, definition := range file.Definitions {
+		if definition.Class != "patch" {
+			continue
+		}
+
+		base := advisory{
+			description: strings.TrimSpace(definition.Metadata.Description),
+			severity:    ovalSeverity(definition.Metadata.Advisory.Severity),
+		}
+		for _, reference := range definition.Metadata.References {
+			if base.id == "" && reference.Source != "CVE" {
+				base.id, base.url = reference.RefId, reference.RefUrl
+			} else if reference.RefId != "" {
+				base.aliases = append(base.aliases, reference.RefId)
+			}
+		}
+		if base.id == "" && len(base.aliases) > 0 {
+			base.id, base.aliases = base.aliases[0], base.aliases[1:]
+		}
+		if base.id == "" {
+			base.id, _, _ = strings.Cut(definition.Metadata.Title, ":")
+		}
+
+		seen := map[string]bool{}
+		for _, ref := range definition.Criteria.testRefs() {
+			check, ok := checks[ref]
+			if !ok || seen[check.name+"@"+check.fixed] {
+				continue
+			}
+			seen[check.name+"@"+check.fixed] = true
+
+			pkgAdvisory := base
+			pkgAdvisory.fixed = check.fixed
+			advisories[check.name] = append(advisories[check.name], pkgAdvisory)
+		}
+	}
+
+	return advisories, nil
+}
+
+// loads oval files of directory, bzip2 compressed files are decompressed while reading
+func loadOvalDefinitions(dir string) (ovalDefinitions, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	definitions := ovalDefinitions{}
+	for _, entry := range entries {
+		match := ovalFileRegex.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		f, err := os.Open(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		var reader io.Reader = f
+		if match[3] != "" {
+			reader = bzip2.NewReader(f)
+		}
+		advisories, err := parseOvalFile(reader)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		distro := match[1] + "-" + match[2]
+		if definitions[distro] == nil {
+			definitions[distro] = map[string][]advisory{}
+		}
+		for name, pkgAdvisories := range advisories {
+			definitions[distro][name] = append(definitions[distro][name], pkgAdvisories...)
+		}
+	}
+
+	return definitions, nil
+}
+
+// returns oval distro such as rocky-9 of rocky-9.3 distro qualifier
+func ovalDistro(distro string) string {
+	id, release, ok := strings.Cut(distro, "-")
+	if !ok {
+		return ""
+	}
+
+	major, _, _ := strings.Cut(release, ".")
+	return id + "-" + major
+}
+
+// returns advisories of rpm package in distro. Fixes built for a module stream are skipped for
+// packages not built from a module and vice versa, as their versions aren't comparable.
+func (d ovalDefinitions) advisories(distro, name, version string) []advisory {
+	advisories := []advisory{}
+	for _, pkgAdvisory := range d[distro][name] {
+		if strings.Contains(pkgAdvisory.fixed, ".module") == strings.Contains(version, ".module") {
+			advisories = append(advisories, pkgAdvisory)
+		}
+	}
+
+	return advisories
+}
@@ -0,0 +1,320 @@
+package distro
+
+import (
+	"strconv"
+	"strings"
+)
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func sign(n int) int {
+	if n < 0 {
+		return -1
+	} else if n > 0 {
+		return 1
+	}
+	return 0
+}
+
+// splits epoch:version-release into its parts, missing epoch is returned as 0
+func splitEvr(evr string) (int, string, string) {
+	epoch := 0
+	if before, after, ok := strings.Cut(evr, ":"); ok {
+		if n, err := strconv.Atoi(before); err == nil {
+			epoch, evr = n, after
+		}
+	}
+
+	if i := strings.LastIndex(evr, "-"); i >= 0 {
+		return epoch, evr[:i], evr[i+1:]
+	}
+	return epoch, evr, ""
+}
+
+// returns sort weight of character in dpkg versions, tilde sorts before everything even the end
+// of version and letters sort before other characters
+func dpkgOrder(s string, i int) int {
+	if i >= len(s) {
+		return 0
+	}
+
+	c := s[i]
+	switch {
+	case isDigit(c):
+		return 0
+	case isLetter(c):
+		return int(c)
+	case c == '~':
+		return -1
+	}
+	return int(c) + 256
+}
+
+// compares upstream version or debian revision as per dpkg verrevcmp
+func dpkgVerrevcmp(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		for (i < len(a) && !isDigit(a[i])) || (j < len(b) && !isDigit(b[j])) {
+			if ac, bc := dpkgOrder(a, i), dpkgOrder(b, j); ac != bc {
+				return sign(ac - bc)
+			}
+			i++
+			j++
+		}
+
+		for i < len(a) && a[i] == '0' {
+			i++
+		}
+		for j < len(b) && b[j] == '0' {
+			j++
+		}
+
+		firstDiff := 0
+		for i < len(a) && isDigit(a[i]) && j < len(b) && isDigit(b[j]) {
+			if firstDiff == 0 {
+				firstDiff = int(a[i]) - int(b[j])
+			}
+			i++
+			j++
+		}
+		if i < len(a) && isDigit(a[i]) {
+			return 1
+		} else if j < len(b) && isDigit(b[j]) {
+			return -1
+		} else if firstDiff != 0 {
+			return sign(firstDiff)
+		}
+	}
+
+	return 0
+}
+
+// CompareDebVersions compares debian package versions such as 1:2.36-9+deb12u4 using dpkg
+// ordering, returns -1, 0 or 1
+func CompareDebVersions(a, b string) int {
+	aEpoch, aVersion, aRevision := splitEvr(a)
+	bEpoch, bVersion, bRevision := splitEvr(b)
+	if aEpoch != bEpoch {
+		return sign(aEpoch - bEpoch)
+	}
+	if result := dpkgVerrevcmp(aVersion, bVersion); result != 0 {
+		return result
+	}
+
+	return dpkgVerrevcmp(aRevision, bRevision)
+}
+
+// pre-release suffixes sort before the release and post-release suffixes after it
+var apkSuffixes = map[string]int{
+	"alpha": -4,
+	"beta":  -3,
+	"pre":   -2,
+	"rc":    -1,
+	"cvs":   1,
+	"svn":   2,
+	"git":   3,
+	"hg":    4,
+	"p":     5,
+}
+
+type apkSuffix struct {
+	rank   int
+	number int
+}
+
+type apkVersion struct {
+	numbers  []int
+	letter   byte
+	suffixes []apkSuffix
+	revision int // -1 when version has no -r revision
+}
+
+// parses apk version such as 1.2.3b_rc1_p2-r4, returns false for invalid versions
+func parseApkVersion(version string) (apkVersion, bool) {
+	parsed := apkVersion{revision: -1}
+
+	if before, after, ok := strings.Cut(version, "-r"); ok {
+		revision, err := strconv.Atoi(after)
+		if err != nil {
+			return parsed, false
+		}
+		version, parsed.revision = before, revision
+	}
+
+	version, suffixes, _ := strings.Cut(version, "_")
+	if version != "" && isLetter(version[len(version)-1]) {
+		parsed.letter = version[len(version)-1]
+		version = version[:len(version)-1]
+	}
+
+	for _, part := range strings.Split(version, ".") {
+		number, err := strconv.Atoi(part)
+		if err != nil {
+			return parsed, false
+		}
+		parsed.numbers = append(parsed.numbers, number)
+	}
+
+	if suffixes != "" {
+		for _, suffix := range strings.Split(suffixes, "_") {
+			name := strings.TrimRightFunc(suffix, func(r rune) bool { return r >= '0' && r <= '9' })
+			rank, ok := apkSuffixes[name]
+			if !ok {
+				return parsed, false
+			}
+
+			number := 0
+			if name != suffix {
+				number, _ = strconv.Atoi(suffix[len(name):])
+			}
+			parsed.suffixes = append(parsed.suffixes, apkSuffix{rank: rank, number: number})
+		}
+	}
+
+	return parsed, true
+}
+
+// CompareApkVersions compares alpine package versions such as 3.1.4-r5 using apk ordering, returns
+// -1, 0 or 1. Invalid versions are compared as strings.
+func CompareApkVersions(a, b string) int {
+	aVersion, aOk := parseApkVersion(a)
+	bVersion, bOk := parseApkVersion(b)
+	if !aOk || !bOk {
+		return strings.Compare(a, b)
+	}
+
+	for i := 0; i < len(aVersion.numbers) && i < len(bVersion.numbers); i++ {
+		if aVersion.numbers[i] != bVersion.numbers[i] {
+			return sign(aVersion.numbers[i] - bVersion.numbers[i])
+		}
+	}
+	// additional version component is greater than letters, suffixes and revisions
+	if len(aVersion.numbers) != len(bVersion.numbers) {
+		return sign(len(aVersion.numbers) - len(bVersion.numbers))
+	}
+
+	if aVersion.letter != bVersion.letter {
+		return sign(int(aVersion.letter) - int(bVersion.letter))
+	}
+
+	for i := 0; i < len(aVersion.suffixes) || i < len(bVersion.suffixes); i++ {
+		switch {
+		case i >= len(aVersion.suffixes):
+			return -sign(bVersion.suffixes[i].rank)
+		case i >= len(bVersion.suffixes):
+			return sign(aVersion.suffixes[i].rank)
+		case aVersion.suffixes[i].rank != bVersion.suffixes[i].rank:
+			return sign(aVersion.suffixes[i].rank - bVersion.suffixes[i].rank)
+		case aVersion.suffixes[i].number != bVersion.suffixes[i].number:
+			return sign(aVersion.suffixes[i].number - bVersion.suffixes[i].number)
+		}
+	}
+
+	return sign(aVersion.revision - bVersion.revision)
+}
+
+func isAlnum(c byte) bool {
+	return isDigit(c) || isLetter(c)
+}
+
+// compares rpm version or release as per rpmvercmp
+func rpmvercmp(a, b string) int {
+	if a == b {
+		return 0
+	}
+
+	for len(a) > 0 || len(b) > 0 {
+		a = strings.TrimLeftFunc(a, func(r rune) bool { return r < 128 && !isAlnum(byte(r)) && r != '~' && r != '^' })
+		b = strings.TrimLeftFunc(b, func(r rune) bool { return r < 128 && !isAlnum(byte(r)) && r != '~' && r != '^' })
+
+		// tilde sorts before everything
+		if strings.HasPrefix(a, "~") || strings.HasPrefix(b, "~") {
+			if !strings.HasPrefix(a, "~") {
+				return 1
+			} else if !strings.HasPrefix(b, "~") {
+				return -1
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+
+		// caret sorts after the end of version but before anything else
+		if strings.HasPrefix(a, "^") || strings.HasPrefix(b, "^") {
+			if a == "" {
+				return -1
+			} else if b == "" {
+				return 1
+			} else if !strings.HasPrefix(a, "^") {
+				return 1
+			} else if !strings.HasPrefix(b, "^") {
+				return -1
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+
+		if a == "" || b == "" {
+			break
+		}
+
+		isNum := isDigit(a[0])
+		segment := func(s string) (string, string) {
+			i := 0
+			for i < len(s) && ((isNum && isDigit(s[i])) || (!isNum && isLetter(s[i]))) {
+				i++
+			}
+			return s[:i], s[i:]
+		}
+
+		var aSegment, bSegment string
+		aSegment, a = segment(a)
+		bSegment, b = segment(b)
+
+		// numeric segments are newer than alphabetic segments
+		if bSegment == "" {
+			if isNum {
+				return 1
+			}
+			return -1
+		}
+
+		if isNum {
+			aSegment = strings.TrimLeft(aSegment, "0")
+			bSegment = strings.TrimLeft(bSegment, "0")
+			if len(aSegment) != len(bSegment) {
+				return sign(len(aSegment) - len(bSegment))
+			}
+		}
+		if result := strings.Compare(aSegment, bSegment); result != 0 {
+			return result
+		}
+	}
+
+	if a == "" && b == "" {
+		return 0
+	} else if a != "" {
+		return 1
+	}
+	return -1
+}
+
+// CompareRpmVersions compares rpm epoch:version-release strings such as 1:1.1.1k-7.el8_6, returns
+// -1, 0 or 1
+func CompareRpmVersions(a, b string) int {
+	aEpoch, aVersion, aRelease := splitEvr(a)
+	bEpoch, bVersion, bRelease := splitEvr(b)
+	if aEpoch != bEpoch {
+		return sign(aEpoch - bEpoch)
+	}
+	if result := rpmvercmp(aVersion, bVersion); result != 0 {
+		return result
+	}
+
+	return rpmvercmp(aRelease, bRelease)
+}
@@ -0,0 +1,101 @@
+package distro
+
+import "testing"
+
+type versionTest struct {
+	a, b string
+	want int
+}
+
+func testCompare(t *testing.T, compare func(a, b string) int, tests []versionTest) {
+	t.Helper()
+
+	for _, test := range tests {
+		if got := compare(test.a, test.b); got != test.want {
+			t.Errorf("compare(%q, %q) = %d, want %d", test.a, test.b, got, test.want)
+		}
+		if got := compare(test.b, test.a); got != -test.want {
+			t.Errorf("compare(%q, %q) = %d, want %d", test.b, test.a, got, -test.want)
+		}
+	}
+}
+
+func TestCompareDebVersions(t *testing.T) {
+	testCompare(t, CompareDebVersions, []versionTest{
+		{"1.0", "1.0", 0},
+		{"1.01", "1.1", 0},
+		{"0:1.0", "1.0", 0},
+		{"1.0", "1.1", -1},
+		{"1.10", "1.9", 1},
+		// tilde sorts before the end of version
+		{"1.0~rc1", "1.0", -1},
+		{"1.0~rc1", "1.0~rc2", -1},
+		{"1.0~~", "1.0~", -1},
+		{"1.0-1~bpo12+1", "1.0-1", -1},
+		// letters sort before other characters but after the end of version
+		{"1.0a", "1.0", 1},
+		{"1.0a", "1.0+", -1},
+		{"1.0+dfsg", "1.0", 1},
+		// epoch takes precedence over version
+		{"1:1.0", "2.0", 1},
+		{"2:0.1", "1:9.9", 1},
+		// debian revision is compared after upstream version
+		{"2.36-9+deb12u4", "2.36-9", 1},
+		{"2.36-9+deb12u4", "2.36-10", -1},
+		{"3.0.11-1~deb12u2", "3.0.11-1", -1},
+		{"1.2-3-4", "1.2-3-5", -1},
+	})
+}
+
+func TestCompareRpmVersions(t *testing.T) {
+	testCompare(t, CompareRpmVersions, []versionTest{
+		{"1.0", "1.0", 0},
+		{"1.010", "1.10", 0},
+		{"0:1.0", "1.0", 0},
+		{"1.0", "1.0.1", -1},
+		{"2.10", "2.9", 1},
+		{"1.1.1k", "1.1.1", 1},
+		// numeric segments are newer than alphabetic segments
+		{"1.0a", "1.0.1", -1},
+		{"1.0.a", "1.0.1", -1},
+		// tilde sorts before everything
+		{"1.0~rc1", "1.0", -1},
+		{"1.0~rc1", "1.0~rc2", -1},
+		{"1.0~rc1", "1.0~", 1},
+		// caret sorts after the end of version but before anything else
+		{"1.0^git1", "1.0", 1},
+		{"1.0^git1", "1.0.1", -1},
+		{"1.0^git1", "1.0^git2", -1},
+		{"1.0~rc1^git1", "1.0~rc1", 1},
+		// epoch takes precedence over version
+		{"1:1.0-1", "2.0-1", 1},
+		// release is compared after version
+		{"1:1.1.1k-7.el8_6", "1:1.1.1k-6.el8_5", 1},
+		{"2.28-225.el9", "2.28-225.el9_2.1", -1},
+	})
+}
+
+func TestCompareApkVersions(t *testing.T) {
+	testCompare(t, CompareApkVersions, []versionTest{
+		{"3.1.4-r5", "3.1.4-r5", 0},
+		{"1.2.10", "1.2.9", 1},
+		{"1.2.3", "1.2.3.1", -1},
+		// letter sorts after the version without it
+		{"1.2.3a", "1.2.3", 1},
+		{"1.2.3a", "1.2.3b", -1},
+		// pre-release suffixes sort before the release and post-release suffixes after it
+		{"1.2.3_rc1", "1.2.3", -1},
+		{"1.2.3_alpha", "1.2.3_beta", -1},
+		{"1.2.3_beta2", "1.2.3_pre1", -1},
+		{"1.2.3_rc1", "1.2.3_rc2", -1},
+		{"1.2.3_p1", "1.2.3", 1},
+		{"1.2.3_git20230101", "1.2.3_p1", -1},
+		{"1.2.3_rc1_p2", "1.2.3_rc1", 1},
+		// additional version component is greater than suffixes
+		{"1.2.3.1", "1.2.3_p1", 1},
+		// revision is compared last
+		{"1.2.3-r1", "1.2.3", 1},
+		{"1.2.3-r10", "1.2.3-r9", 1},
+		{"1.2.3_p1-r0", "1.2.3-r5", 1},
+	})
+}
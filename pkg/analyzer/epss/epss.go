@@ -2,7 +2,7 @@ package epss
 
 import (
 	"encoding/json"
-	"fmt"
+	"errors"
 	"net/http"
 	"net/url"
 	"sync"
@@ -45,7 +45,7 @@ func (a *EpssAnalyzer) GetEpssFromVuln(cveId string) (epss types.Epss, err error
 	defer res.Body.Close()
 
 	if res.StatusCode != http.StatusOK {
-		err = fmt.Errorf("unexpected HTTP status: %d", res.StatusCode)
+		err = &types.ApiStatusErr{Api: "EPSS", StatusCode: res.StatusCode}
 		log.Error().Err(err).Msg("failed to fetch EPSS")
 		return epss, err
 	}
@@ -53,6 +53,7 @@ func (a *EpssAnalyzer) GetEpssFromVuln(cveId string) (epss types.Epss, err error
 	var apiResp types.EpssApiResponseSchema
 	if err = json.NewDecoder(res.Body).Decode(&apiResp); err != nil {
 		log.Error().Err(err).Msg("failed to decode EPSS response")
+		return epss, err
 	}
 
 	if len(apiResp.Data) > 0 {
@@ -76,19 +77,19 @@ func (a *EpssAnalyzer) WorkerEpss(ch <-chan *types.Vuln, resultCh chan error) {
 			epss, err = a.GetEpssFromVuln(cveId)
 			if err != nil {
 				log.Error().Err(err).Msg("failed to fetch epss score")
-				resultCh <- err
 			}
 		}
 
 		vuln.Epss = epss
 		log.Info().Msgf("EPSS Score for CVE %s: %v", cveId, epss)
 
-		resultCh <- nil
+		resultCh <- err
 	}
 }
 
 // TODO: pass vulns slice by reference
-func (a *EpssAnalyzer) ProcessEpssForVulns(vulns []types.Vuln, workers int) []types.Vuln {
+// returns vulns with epss scores along with joined errors of failed epss lookups
+func (a *EpssAnalyzer) ProcessEpssForVulns(vulns []types.Vuln, workers int) ([]types.Vuln, error) {
 	vulnChan := make(chan *types.Vuln)
 	errChan := make(chan error)
 	var wg sync.WaitGroup
@@ -117,9 +118,11 @@ func (a *EpssAnalyzer) ProcessEpssForVulns(vulns []types.Vuln, workers int) []ty
 	}()
 
 	// Process errors
+	var errs []error
 	for err := range errChan {
 		if err != nil {
 			log.Error().Err(err).Msg("error occurred while fetching epss score")
+			errs = append(errs, err)
 		}
 	}
 
@@ -128,5 +131,5 @@ func (a *EpssAnalyzer) ProcessEpssForVulns(vulns []types.Vuln, workers int) []ty
 		log.Debug().Msgf("%v", vuln.Epss)
 	}
 
-	return vulns
+	return vulns, errors.Join(errs...)
 }
@@ -3,7 +3,6 @@ package osv
 import (
 	"bytes"
 	"encoding/json"
-	"fmt"
 	"net/http"
 
 	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
@@ -34,7 +33,7 @@ func (a *OsvAnalyzer) GetVulns(purl string) ([]types.Vuln, error) {
 		resp, err = a.getVuln(purl, resp.NextPageToken)
 		if err != nil {
 			log.Error().Err(err).Msgf("failed to fetch vulns for purl: %s", purl)
-			continue
+			return vulns, err
 		}
 		vulns = append(vulns, resp.Vulns...)
 	}
@@ -72,11 +71,12 @@ func (a *OsvAnalyzer) getVuln(purl string, pageToken string) (types.OsvQueryApiR
 		return osvResp, err
 	}
 
+	defer response.Body.Close()
+
 	if response.StatusCode != http.StatusOK {
 		log.Error().Err(err).Msgf("failed to fetch vuln data for purl %s with page token %s. OSV api returned status code %d instead of 200", purl, pageToken, response.StatusCode)
-		return osvResp, fmt.Errorf("OSV api returned status code %d instead of 200", response.StatusCode)
+		return osvResp, &types.ApiStatusErr{Api: "OSV", StatusCode: response.StatusCode}
 	}
-	defer response.Body.Close()
 
 	return osvResp, json.NewDecoder(response.Body).Decode(&osvResp)
 }
@@ -0,0 +1,211 @@
+package attestation
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dmdhrumilmistry/defect-detect/pkg/sbomsign"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+)
+
+const (
+	InTotoPayloadType     = "application/vnd.in-toto+json"
+	inTotoStatementPrefix = "https://in-toto.io/Statement/"
+
+	cycloneDxPredicatePrefix = "https://cyclonedx.org/bom"
+	spdxPredicatePrefix      = "https://spdx.dev/Document"
+
+	SlsaProvenanceV01 = "https://slsa.dev/provenance/v0.1"
+	SlsaProvenanceV02 = "https://slsa.dev/provenance/v0.2"
+	SlsaProvenanceV1  = "https://slsa.dev/provenance/v1"
+)
+
+var (
+	ErrInvalidEnvelope  = errors.New("invalid DSSE envelope")
+	ErrInvalidStatement = errors.New("invalid in-toto statement")
+)
+
+// Envelope is DSSE envelope carrying base64 encoded in-toto statement
+type Envelope struct {
+	PayloadType string              `json:"payloadType"`
+	Payload     string              `json:"payload"`
+	Signatures  []EnvelopeSignature `json:"signatures"`
+}
+
+type EnvelopeSignature struct {
+	KeyId string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+type Statement struct {
+	Type          string                     `json:"_type"`
+	Subject       []types.AttestationSubject `json:"subject"`
+	PredicateType string                     `json:"predicateType"`
+	Predicate     json.RawMessage            `json:"predicate"`
+}
+
+// DSSE allows both standard and url safe base64 encodings
+func decodeBase64(value string) ([]byte, error) {
+	value = strings.TrimRight(strings.TrimSpace(value), "=")
+	if decoded, err := base64.RawStdEncoding.DecodeString(value); err == nil {
+		return decoded, nil
+	}
+
+	return base64.RawURLEncoding.DecodeString(value)
+}
+
+// Parse decodes DSSE envelope and in-toto statement carried in its payload. Decoded payload is
+// returned for signature verification.
+func Parse(content []byte) (Envelope, Statement, []byte, error) {
+	var envelope Envelope
+	if err := json.Unmarshal(bytes.TrimSpace(content), &envelope); err != nil {
+		return envelope, Statement{}, nil, ErrInvalidEnvelope
+	}
+
+	if envelope.PayloadType != InTotoPayloadType {
+		return envelope, Statement{}, nil, fmt.Errorf("%w: unsupported payload type %q", ErrInvalidEnvelope, envelope.PayloadType)
+	}
+
+	if len(envelope.Signatures) == 0 {
+		return envelope, Statement{}, nil, fmt.Errorf("%w: envelope is not signed", ErrInvalidEnvelope)
+	}
+
+	payload, err := decodeBase64(envelope.Payload)
+	if err != nil {
+		return envelope, Statement{}, nil, fmt.Errorf("%w: payload is not base64 encoded", ErrInvalidEnvelope)
+	}
+
+	var statement Statement
+	if err := json.Unmarshal(payload, &statement); err != nil {
+		return envelope, statement, nil, ErrInvalidStatement
+	}
+
+	if !strings.HasPrefix(statement.Type, inTotoStatementPrefix) {
+		return envelope, statement, nil, fmt.Errorf("%w: unsupported statement type %q", ErrInvalidStatement, statement.Type)
+	}
+
+	if len(statement.SubjectDigests()) == 0 {
+		return envelope, statement, nil, fmt.Errorf("%w: statement has no subject digests", ErrInvalidStatement)
+	}
+
+	return envelope, statement, payload, nil
+}
+
+// returns DSSE pre-authentication encoding of payload which is signed by envelope signatures
+func preAuthEncoding(payloadType string, payload []byte) []byte {
+	encoded := fmt.Sprintf("DSSEv1 %d %s %d ", len(payloadType), payloadType, len(payload))
+	return append([]byte(encoded), payload...)
+}
+
+// Verify verifies envelope signatures using trusted keys, envelope is verified when any signature
+// is valid
+func Verify(envelope Envelope, payload []byte, keys []types.TrustedKey) types.SbomSignature {
+	signed := preAuthEncoding(envelope.PayloadType, payload)
+
+	var result *types.SbomSignature
+	for _, signature := range envelope.Signatures {
+		decoded, err := decodeBase64(signature.Sig)
+		if err != nil {
+			continue
+		}
+
+		result = sbomsign.VerifyDetached(signed, decoded, keys)
+		if result.Verified {
+			if result.Signer == "" {
+				result.Signer = signature.KeyId
+			}
+			break
+		}
+	}
+
+	if result == nil {
+		result = &types.SbomSignature{Error: "invalid signature value"}
+	}
+	result.Type = types.SignatureTypeDsse
+
+	return *result
+}
+
+// returns normalized digests of artifact as <algorithm>:<digest>
+func normalizeDigest(algorithm, value string) string {
+	return strings.ToLower(algorithm) + ":" + strings.ToLower(value)
+}
+
+// SubjectDigests returns sorted digests of statement subjects as <algorithm>:<digest>
+func (s Statement) SubjectDigests() []string {
+	unique := map[string]bool{}
+	for _, subject := range s.Subject {
+		for algorithm, value := range subject.Digest {
+			if algorithm != "" && value != "" {
+				unique[normalizeDigest(algorithm, value)] = true
+			}
+		}
+	}
+
+	digests := []string{}
+	for digest := range unique {
+		digests = append(digests, digest)
+	}
+	sort.Strings(digests)
+
+	return digests
+}
+
+// IsSbom returns true when predicate is cyclonedx or spdx sbom
+func (s Statement) IsSbom() bool {
+	return strings.HasPrefix(s.PredicateType, cycloneDxPredicatePrefix) || strings.HasPrefix(s.PredicateType, spdxPredicatePrefix)
+}
+
+// IsProvenance returns true when predicate is SLSA provenance
+func (s Statement) IsProvenance() bool {
+	switch s.PredicateType {
+	case SlsaProvenanceV01, SlsaProvenanceV02, SlsaProvenanceV1:
+		return true
+	}
+
+	return false
+}
+
+// SbomContent returns sbom carried as predicate. Predicates encoded as json strings, e.g. spdx
+// tag-value documents, are unquoted.
+func (s Statement) SbomContent() ([]byte, error) {
+	predicate := bytes.TrimSpace(s.Predicate)
+	if len(predicate) == 0 || bytes.Equal(predicate, []byte("null")) {
+		return nil, fmt.Errorf("%w: statement has no predicate", ErrInvalidStatement)
+	}
+
+	if predicate[0] == '"' {
+		var content string
+		if err := json.Unmarshal(predicate, &content); err != nil {
+			return nil, ErrInvalidStatement
+		}
+		return []byte(content), nil
+	}
+
+	return predicate, nil
+}
+
+// GetSbomArtifactDigests returns digests of sbom metadata component using in-toto algorithm names
+func GetSbomArtifactDigests(sbom types.Sbom) []string {
+	digests := []string{}
+	if sbom.Metadata == nil || sbom.Metadata.Component == nil || sbom.Metadata.Component.Hashes == nil {
+		return digests
+	}
+
+	for _, hash := range *sbom.Metadata.Component.Hashes {
+		// SHA-256 is sha256 and SHA3-256 is sha3_256 in in-toto digest sets
+		algorithm := strings.ToLower(string(hash.Algorithm))
+		algorithm = strings.Replace(algorithm, "sha3-", "sha3_", 1)
+		algorithm = strings.Replace(algorithm, "sha-", "sha", 1)
+		if hash.Value != "" {
+			digests = append(digests, normalizeDigest(algorithm, hash.Value))
+		}
+	}
+
+	return digests
+}
@@ -0,0 +1,147 @@
+package attestation
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+)
+
+type builder struct {
+	Id string `json:"id"`
+}
+
+// material is SLSA v0.x material or v1 resource descriptor
+type material struct {
+	Uri    string            `json:"uri"`
+	Digest map[string]string `json:"digest"`
+}
+
+type provenanceV01 struct {
+	Builder builder `json:"builder"`
+	Recipe  struct {
+		Type              string `json:"type"`
+		DefinedInMaterial *int   `json:"definedInMaterial"`
+	} `json:"recipe"`
+	Materials []material `json:"materials"`
+}
+
+type provenanceV02 struct {
+	Builder    builder `json:"builder"`
+	BuildType  string  `json:"buildType"`
+	Invocation struct {
+		ConfigSource material `json:"configSource"`
+	} `json:"invocation"`
+	Materials []material `json:"materials"`
+}
+
+type provenanceV1 struct {
+	BuildDefinition struct {
+		BuildType          string `json:"buildType"`
+		ExternalParameters struct {
+			// set by github actions builders
+			Workflow struct {
+				Repository string `json:"repository"`
+				Ref        string `json:"ref"`
+			} `json:"workflow"`
+		} `json:"externalParameters"`
+		ResolvedDependencies []material `json:"resolvedDependencies"`
+	} `json:"buildDefinition"`
+	RunDetails struct {
+		Builder builder `json:"builder"`
+	} `json:"runDetails"`
+}
+
+// returns git commit of material
+func (m material) commit() string {
+	if commit := m.Digest["gitCommit"]; commit != "" {
+		return commit
+	}
+
+	return m.Digest["sha1"]
+}
+
+// splits git uri such as git+https://github.com/org/repo@refs/heads/main into repository and ref
+func parseGitUri(uri string) (string, string) {
+	uri = strings.TrimPrefix(uri, "git+")
+
+	// ref follows @ in path, @ in authority separates user info
+	pathStart := 0
+	if schemeEnd := strings.Index(uri, "://"); schemeEnd >= 0 {
+		pathStart = schemeEnd + 3
+		if slash := strings.Index(uri[pathStart:], "/"); slash >= 0 {
+			pathStart += slash
+		}
+	}
+
+	if at := strings.Index(uri[pathStart:], "@"); at >= 0 {
+		return uri[:pathStart+at], uri[pathStart+at+1:]
+	}
+
+	return uri, ""
+}
+
+// sets source of provenance from first material having git commit
+func setSource(provenance *types.Provenance, materials ...material) {
+	for _, m := range materials {
+		if m.Uri == "" || m.commit() == "" {
+			continue
+		}
+
+		provenance.SourceRepo, provenance.Ref = parseGitUri(m.Uri)
+		provenance.Commit = m.commit()
+		return
+	}
+}
+
+// ExtractProvenance returns builder, build type and source of SLSA provenance statement
+func ExtractProvenance(statement Statement) (types.Provenance, error) {
+	provenance := types.Provenance{PredicateType: statement.PredicateType}
+
+	var err error
+	switch statement.PredicateType {
+	case SlsaProvenanceV01:
+		var predicate provenanceV01
+		if err = json.Unmarshal(statement.Predicate, &predicate); err == nil {
+			provenance.BuilderId = predicate.Builder.Id
+			provenance.BuildType = predicate.Recipe.Type
+
+			materials := predicate.Materials
+			if index := predicate.Recipe.DefinedInMaterial; index != nil && *index >= 0 && *index < len(materials) {
+				materials = append([]material{materials[*index]}, materials...)
+			}
+			setSource(&provenance, materials...)
+		}
+	case SlsaProvenanceV02:
+		var predicate provenanceV02
+		if err = json.Unmarshal(statement.Predicate, &predicate); err == nil {
+			provenance.BuilderId = predicate.Builder.Id
+			provenance.BuildType = predicate.BuildType
+			setSource(&provenance, append([]material{predicate.Invocation.ConfigSource}, predicate.Materials...)...)
+		}
+	case SlsaProvenanceV1:
+		var predicate provenanceV1
+		if err = json.Unmarshal(statement.Predicate, &predicate); err == nil {
+			definition := predicate.BuildDefinition
+			provenance.BuilderId = predicate.RunDetails.Builder.Id
+			provenance.BuildType = definition.BuildType
+			setSource(&provenance, definition.ResolvedDependencies...)
+
+			if workflow := definition.ExternalParameters.Workflow; workflow.Repository != "" {
+				provenance.SourceRepo = workflow.Repository
+				if workflow.Ref != "" {
+					provenance.Ref = workflow.Ref
+				}
+			}
+		}
+	default:
+		return provenance, fmt.Errorf("unsupported provenance predicate type %q", statement.PredicateType)
+	}
+
+	if err != nil {
+		return provenance, fmt.Errorf("%w: invalid provenance predicate", ErrInvalidStatement)
+	}
+
+	return provenance, nil
+}
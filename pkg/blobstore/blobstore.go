@@ -0,0 +1,30 @@
+package blobstore
+
+import (
+	"fmt"
+
+	"github.com/dmdhrumilmistry/defect-detect/pkg/config"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	BackendGridFs = "gridfs"
+	BackendLocal  = "local"
+	BackendS3     = "s3"
+)
+
+// NewBlobStore returns blob store of backend configured using BLOB_STORE, documents are stored
+// in gridfs bucket of the app database by default
+func NewBlobStore(cfg *config.Config, mdb *mongo.Database) (types.BlobStore, error) {
+	switch cfg.BlobStore {
+	case BackendGridFs, "":
+		return NewGridFsBlobStore(mdb), nil
+	case BackendLocal:
+		return NewLocalBlobStore(cfg.BlobDir)
+	case BackendS3:
+		return NewS3BlobStore(cfg.S3Endpoint, cfg.S3Bucket, cfg.S3Region, cfg.S3AccessKey, cfg.S3SecretKey, cfg.S3UseSsl)
+	}
+
+	return nil, fmt.Errorf("unsupported blob store %s, supported stores are %s, %s and %s", cfg.BlobStore, BackendGridFs, BackendLocal, BackendS3)
+}
@@ -0,0 +1,110 @@
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const GRIDFS_BUCKET = "blobs"
+
+// GridFsBlobStore stores blobs in gridfs bucket using key as file name. Files get generated ids,
+// so concurrent uploads of the same key never write chunks of the same file.
+type GridFsBlobStore struct {
+	db *mongo.Database
+}
+
+func NewGridFsBlobStore(mdb *mongo.Database) *GridFsBlobStore {
+	return &GridFsBlobStore{db: mdb}
+}
+
+// returns bucket with deadlines of context, buckets are created for each operation since
+// deadlines are set on the bucket
+func (g *GridFsBlobStore) bucket(ctx context.Context) (*gridfs.Bucket, error) {
+	bucket, err := gridfs.NewBucket(g.db, options.GridFSBucket().SetName(GRIDFS_BUCKET))
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		bucket.SetReadDeadline(deadline)
+		bucket.SetWriteDeadline(deadline)
+	} else {
+		bucket.SetReadDeadline(time.Time{})
+		bucket.SetWriteDeadline(time.Time{})
+	}
+
+	return bucket, nil
+}
+
+// stores content unless blob with same key exists. Keys are derived from content, so existing
+// blob is never replaced, which could remove blob referenced by a concurrent upload.
+func (g *GridFsBlobStore) Put(ctx context.Context, key string, content []byte) error {
+	bucket, err := g.bucket(ctx)
+	if err != nil {
+		return err
+	}
+
+	count, err := bucket.GetFilesCollection().CountDocuments(ctx, bson.M{"filename": key}, options.Count().SetLimit(1))
+	if err != nil {
+		return err
+	} else if count > 0 {
+		return nil
+	}
+
+	_, err = bucket.UploadFromStream(key, bytes.NewReader(content))
+	return err
+}
+
+// returns latest file with key as name, which includes blobs stored with key as their id
+func (g *GridFsBlobStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	bucket, err := g.bucket(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := bucket.OpenDownloadStreamByName(key)
+	if errors.Is(err, gridfs.ErrFileNotFound) {
+		return nil, types.ErrBlobNotFound
+	}
+
+	return stream, err
+}
+
+// deletes all files with key as name
+func (g *GridFsBlobStore) Delete(ctx context.Context, key string) error {
+	bucket, err := g.bucket(ctx)
+	if err != nil {
+		return err
+	}
+
+	cursor, err := bucket.FindContext(ctx, bson.M{"filename": key})
+	if err != nil {
+		return err
+	}
+
+	var files []struct {
+		Id interface{} `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &files); err != nil {
+		return err
+	} else if len(files) == 0 {
+		return types.ErrBlobNotFound
+	}
+
+	for _, file := range files {
+		if err := bucket.DeleteContext(ctx, file.Id); err != nil && !errors.Is(err, gridfs.ErrFileNotFound) {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/rs/zerolog/log"
+)
+
+// S3BlobStore stores blobs as objects of a bucket in aws s3 or s3 compatible storage such as minio
+type S3BlobStore struct {
+	client *minio.Client
+	bucket string
+}
+
+// creates s3 client for endpoint such as s3.amazonaws.com or localhost:9000 and creates bucket when
+// it doesn't exist
+func NewS3BlobStore(endpoint, bucket, region, accessKey, secretKey string, useSsl bool) (*S3BlobStore, error) {
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
+		Secure: useSsl,
+		Region: region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := client.BucketExists(context.TODO(), bucket)
+	if err != nil {
+		return nil, err
+	} else if !exists {
+		if err := client.MakeBucket(context.TODO(), bucket, minio.MakeBucketOptions{Region: region}); err != nil {
+			return nil, err
+		}
+		log.Info().Msgf("created blob storage bucket %s", bucket)
+	}
+
+	return &S3BlobStore{client: client, bucket: bucket}, nil
+}
+
+func isNotFound(err error) bool {
+	return minio.ToErrorResponse(err).StatusCode == http.StatusNotFound
+}
+
+func (s *S3BlobStore) Put(ctx context.Context, key string, content []byte) error {
+	_, err := s.client.PutObject(ctx, s.bucket, key, bytes.NewReader(content), int64(len(content)), minio.PutObjectOptions{})
+	return err
+}
+
+func (s *S3BlobStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	object, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	// objects are fetched lazily, stat reports missing objects
+	if _, err := object.Stat(); err != nil {
+		object.Close()
+		if isNotFound(err) {
+			return nil, types.ErrBlobNotFound
+		}
+		return nil, err
+	}
+
+	return object, nil
+}
+
+// deletes object, s3 reports success for missing objects so they are checked first
+func (s *S3BlobStore) Delete(ctx context.Context, key string) error {
+	if _, err := s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{}); isNotFound(err) {
+		return types.ErrBlobNotFound
+	} else if err != nil {
+		return err
+	}
+
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
@@ -28,9 +28,16 @@ type Config struct {
 	AppEnv              string
 	IsDevEnv            bool
 	DefaultWorkersCount int
+	AnalysisBatchSize   int // number of analyzed components written to db at once
+	AnalysisMaxInFlight int // max components being analyzed or waiting to be written
+	MaxImageSizeMb      int // max uncompressed size of uploaded container image tarballs
 
-	// Secrets
-	GithubToken string
+	// Github Config
+	GithubToken  string
+	GithubApiUrl string // https://api.github.com or github enterprise api url, e.g. https://github.example.com/api/v3
+
+	// api urls other than GithubApiUrl accepted in github import requests and integrations
+	GithubAllowedApiUrls []string
 
 	// Google Secrets for Oauth login
 	GoogleClientId     string
@@ -44,6 +51,34 @@ type Config struct {
 	RunOsv  bool
 	RunMpaf bool
 	RunEpss bool
+
+	// Distro Analyzer Config, sources without path are skipped
+	RunDistro         bool
+	DebianTrackerFile string // json of https://security-tracker.debian.org/tracker/data/json
+	AlpineSecdbDir    string // secdb json files of https://secdb.alpinelinux.org
+	OvalDir           string // oval xml files named after distro such as rhel-8.oval.xml or rocky-9.oval.xml.bz2
+
+	// Blob Storage Config
+	BlobStore   string // gridfs, local or s3
+	BlobDir     string // directory of local blob store
+	BlobTimeout int    // seconds allowed for storing or fetching a blob
+	S3Endpoint  string // s3.amazonaws.com or s3 compatible endpoint such as localhost:9000
+	S3Bucket    string
+	S3Region    string
+	S3AccessKey string
+	S3SecretKey string
+	S3UseSsl    bool
+
+	// Scheduler Config
+	ScanSchedule          string // cron expression, empty value disables scheduled rescans
+	ScanLockTtlInSeconds  int
+	ScanSchedulerInterval int // seconds between schedule evaluations
+
+	// Job Config
+	JobWorkersCount int
+	JobPollInterval int // seconds between polling for queued jobs
+	JobStaleTimeout int // seconds after which running jobs without heartbeat are requeued
+	JobMaxAttempts  int // times a job is claimed before stale job is failed instead of requeued
 }
 
 var DefaultConfig = NewConfig()
@@ -84,9 +119,15 @@ func NewConfig() *Config {
 		AppEnv:              env,
 		IsDevEnv:            env == "dev",
 		DefaultWorkersCount: getEnvInt("DEFAULT_WORKERS_COUNT", 30),
+		AnalysisBatchSize:   getEnvInt("ANALYSIS_BATCH_SIZE", 200),
+		AnalysisMaxInFlight: getEnvInt("ANALYSIS_MAX_IN_FLIGHT", 1000),
+		MaxImageSizeMb:      getEnvInt("MAX_IMAGE_SIZE_MB", 2048),
 
-		// Github Secret
-		GithubToken: getEnvString("GITHUB_TOKEN", ""),
+		// Github Config
+		GithubToken:  getEnvString("GITHUB_TOKEN", ""),
+		GithubApiUrl: strings.TrimRight(getEnvString("GITHUB_API_URL", "https://api.github.com"), "/"),
+
+		GithubAllowedApiUrls: getEnvList("GITHUB_ALLOWED_API_URLS"),
 
 		// Google secrets
 		GoogleClientId:     getEnvString("GOOGLE_CLIENT_ID", ""),
@@ -100,6 +141,34 @@ func NewConfig() *Config {
 		RunOsv:  getEnvBool("RUN_OSV_ANALYZER"),
 		RunMpaf: getEnvBool("RUN_MPAF_ANALYZER"),
 		RunEpss: getEnvBool("RUN_EPSS_ANALYZER"),
+
+		// Distro Analyzer Config
+		RunDistro:         getEnvBool("RUN_DISTRO_ANALYZER"),
+		DebianTrackerFile: getEnvString("DEBIAN_TRACKER_FILE", ""),
+		AlpineSecdbDir:    getEnvString("ALPINE_SECDB_DIR", ""),
+		OvalDir:           getEnvString("OVAL_DIR", ""),
+
+		// Blob Storage Config
+		BlobStore:   strings.ToLower(getEnvString("BLOB_STORE", "gridfs")),
+		BlobDir:     getEnvString("BLOB_DIR", "data/blobs"),
+		BlobTimeout: getEnvInt("BLOB_TIMEOUT", 60),
+		S3Endpoint:  getEnvString("S3_ENDPOINT", "s3.amazonaws.com"),
+		S3Bucket:    getEnvString("S3_BUCKET", "defect-detect"),
+		S3Region:    getEnvString("S3_REGION", ""),
+		S3AccessKey: getEnvString("S3_ACCESS_KEY", ""),
+		S3SecretKey: getEnvString("S3_SECRET_KEY", ""),
+		S3UseSsl:    getEnvBool("S3_USE_SSL"),
+
+		// Scheduler Config
+		ScanSchedule:          getEnvString("SCAN_SCHEDULE", ""),
+		ScanLockTtlInSeconds:  getEnvInt("SCAN_LOCK_TTL", 3600),
+		ScanSchedulerInterval: getEnvInt("SCAN_SCHEDULER_INTERVAL", 60),
+
+		// Job Config
+		JobWorkersCount: getEnvInt("JOB_WORKERS_COUNT", 2),
+		JobPollInterval: getEnvInt("JOB_POLL_INTERVAL", 5),
+		JobStaleTimeout: getEnvInt("JOB_STALE_TIMEOUT", 120),
+		JobMaxAttempts:  getEnvInt("JOB_MAX_ATTEMPTS", 3),
 	}
 }
 
@@ -120,6 +189,18 @@ func getEnvString(key, defaultValue string) string {
 	return value
 }
 
+// returns comma separated values of env var, trailing slashes of values are removed
+func getEnvList(key string) []string {
+	values := []string{}
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimRight(strings.TrimSpace(value), "/"); value != "" {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
 func getEnvBool(key string) bool {
 	value := strings.ToLower(os.Getenv(key))
 	return value == "true" || value == "1" || value == "yes"
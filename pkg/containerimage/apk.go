@@ -0,0 +1,92 @@
+package containerimage
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+)
+
+const apkInstalledPath = "lib/apk/db/installed"
+
+// returns name of apk dependency or provide such as so:libc.musl-x86_64.so.1=1 or musl>=1.2,
+// conflicts are skipped
+func apkName(value string) string {
+	if strings.HasPrefix(value, "!") {
+		return ""
+	}
+
+	if i := strings.IndexAny(value, "=<>~"); i >= 0 {
+		value = value[:i]
+	}
+	return value
+}
+
+// parses apk installed database, packages are separated by blank lines and fields are prefixed
+// with single letter keys. Files are listed as F: directory lines followed by R: file lines.
+func parseApkPackages(content []byte) []Package {
+	packages := []Package{}
+	pkg := Package{Type: PackageTypeApk}
+	dir := ""
+
+	addPackage := func() {
+		if pkg.Name != "" && pkg.Version != "" {
+			if pkg.Source == "" {
+				pkg.Source = pkg.Name
+			}
+			pkg.SourceVersion = pkg.Version
+			pkg.provides = append(pkg.provides, pkg.Name)
+			packages = append(packages, pkg)
+		}
+		pkg, dir = Package{Type: PackageTypeApk}, ""
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			addPackage()
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+
+		switch key {
+		case "P":
+			pkg.Name = value
+		case "V":
+			pkg.Version = value
+		case "A":
+			pkg.Arch = value
+		case "o":
+			pkg.Source = value
+		case "L":
+			pkg.License = value
+		case "m":
+			pkg.Supplier = value
+		case "D":
+			for _, dependency := range strings.Fields(value) {
+				if name := apkName(dependency); name != "" {
+					pkg.requires = append(pkg.requires, []string{name})
+				}
+			}
+		case "F":
+			dir = value
+		case "R":
+			// installed files satisfy dependencies such as /bin/sh
+			pkg.provides = append(pkg.provides, "/"+strings.TrimPrefix(dir+"/"+value, "/"))
+		case "p":
+			for _, provided := range strings.Fields(value) {
+				if name := apkName(provided); name != "" {
+					pkg.provides = append(pkg.provides, name)
+				}
+			}
+		}
+	}
+	addPackage()
+
+	return packages
+}
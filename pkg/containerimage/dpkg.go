@@ -0,0 +1,140 @@
+package containerimage
+
+import (
+	"bufio"
+	"bytes"
+	"sort"
+	"strings"
+)
+
+const (
+	dpkgStatusPath = "var/lib/dpkg/status"
+	// distroless images keep a status file of each package in status.d
+	dpkgStatusDir = "var/lib/dpkg/status.d/"
+)
+
+// returns paragraphs of debian control file, continuation lines are joined
+func parseControlParagraphs(content []byte) []map[string]string {
+	paragraphs := []map[string]string{}
+	paragraph := map[string]string{}
+	lastKey := ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		switch {
+		case strings.TrimSpace(line) == "":
+			if len(paragraph) > 0 {
+				paragraphs = append(paragraphs, paragraph)
+				paragraph = map[string]string{}
+			}
+			lastKey = ""
+		case line[0] == ' ' || line[0] == '\t':
+			if lastKey != "" {
+				paragraph[lastKey] += "\n" + strings.TrimSpace(line)
+			}
+		default:
+			key, value, ok := strings.Cut(line, ":")
+			if !ok {
+				continue
+			}
+			lastKey = key
+			paragraph[key] = strings.TrimSpace(value)
+		}
+	}
+	if len(paragraph) > 0 {
+		paragraphs = append(paragraphs, paragraph)
+	}
+
+	return paragraphs
+}
+
+// parses dependency field such as "libc6 (>= 2.34), debconf | debconf-2.0" into alternatives of
+// package names
+func parseDebRelations(field string) [][]string {
+	relations := [][]string{}
+	for _, relation := range strings.Split(field, ",") {
+		alternatives := []string{}
+		for _, alternative := range strings.Split(relation, "|") {
+			name, _, _ := strings.Cut(strings.TrimSpace(alternative), " ")
+			name, _, _ = strings.Cut(name, "(")
+			// multiarch qualifiers such as python3:any
+			name, _, _ = strings.Cut(name, ":")
+			if name != "" {
+				alternatives = append(alternatives, name)
+			}
+		}
+		if len(alternatives) > 0 {
+			relations = append(relations, alternatives)
+		}
+	}
+
+	return relations
+}
+
+func parseDpkgPackages(content []byte) []Package {
+	packages := []Package{}
+	for _, paragraph := range parseControlParagraphs(content) {
+		// status files of distroless images have no status field
+		if status, ok := paragraph["Status"]; ok && !strings.HasSuffix(status, " installed") {
+			continue
+		}
+		if paragraph["Package"] == "" || paragraph["Version"] == "" {
+			continue
+		}
+
+		pkg := Package{
+			Type:     PackageTypeDeb,
+			Name:     paragraph["Package"],
+			Version:  paragraph["Version"],
+			Arch:     paragraph["Architecture"],
+			Supplier: paragraph["Maintainer"],
+			provides: []string{paragraph["Package"]},
+		}
+
+		// source field holds version when it differs from binary package version
+		if source := paragraph["Source"]; source != "" {
+			name, version, _ := strings.Cut(source, " ")
+			pkg.Source = name
+			pkg.SourceVersion = strings.Trim(version, "()")
+		}
+		if pkg.Source == "" {
+			pkg.Source = pkg.Name
+		}
+		if pkg.SourceVersion == "" {
+			pkg.SourceVersion = pkg.Version
+		}
+
+		pkg.requires = append(parseDebRelations(paragraph["Pre-Depends"]), parseDebRelations(paragraph["Depends"])...)
+		for _, provided := range parseDebRelations(paragraph["Provides"]) {
+			pkg.provides = append(pkg.provides, provided...)
+		}
+		packages = append(packages, pkg)
+	}
+
+	return packages
+}
+
+// returns packages of dpkg status file or status.d files of distroless images
+func readDpkgPackages(f *filesystem) []Package {
+	packages := []Package{}
+	if content, ok := f.read(dpkgStatusPath); ok {
+		packages = append(packages, parseDpkgPackages(content)...)
+	}
+
+	files := f.readDir(dpkgStatusDir)
+	names := make([]string, 0, len(files))
+	for name := range files {
+		// md5sums of distroless packages are stored next to their status
+		if !strings.HasSuffix(name, ".md5sums") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		packages = append(packages, parseDpkgPackages(files[name])...)
+	}
+
+	return packages
+}
@@ -0,0 +1,404 @@
+package containerimage
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrInvalidImage     = errors.New("invalid container image tarball")
+	ErrUnsupportedLayer = errors.New("unsupported layer compression")
+	ErrNoPackages       = errors.New("no os package database found in image")
+	ErrImageTooLarge    = errors.New("container image tarball is too large")
+)
+
+// max size of a tracked file read from layers and of all tracked files held in memory, distroless
+// images keep a dpkg status file of each package
+const (
+	maxTrackedFileSize  = 512 << 20
+	maxTrackedFilesSize = 1 << 30
+)
+
+const (
+	ociIndexMediaType        = "application/vnd.oci.image.index.v1+json"
+	dockerListMediaType      = "application/vnd.docker.distribution.manifest.list.v2+json"
+	ociRefNameAnnotation     = "org.opencontainers.image.ref.name"
+	containerdNameAnnotation = "io.containerd.image.name"
+)
+
+// entry is a regular file or symlink of image tarball
+type entry struct {
+	offset   int64
+	size     int64
+	linkname string
+}
+
+// tarball provides random access to files of uncompressed image tarball
+type tarball struct {
+	reader  io.ReaderAt
+	entries map[string]entry
+}
+
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+	return n, err
+}
+
+// indexes regular files of tarball, file contents start right after their header blocks
+func newTarball(reader io.ReaderAt, size int64) (*tarball, error) {
+	counter := &countingReader{reader: io.NewSectionReader(reader, 0, size)}
+	tarReader := tar.NewReader(counter)
+	t := &tarball{reader: reader, entries: map[string]entry{}}
+
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+		}
+
+		name := cleanPath(header.Name)
+		switch header.Typeflag {
+		case tar.TypeReg:
+			t.entries[name] = entry{offset: counter.count, size: header.Size}
+		case tar.TypeSymlink:
+			t.entries[name] = entry{linkname: cleanPath(path.Join(path.Dir(name), header.Linkname))}
+		}
+	}
+
+	return t, nil
+}
+
+// returns path relative to root without leading slash
+func cleanPath(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+// returns reader of file, symlinks are followed
+func (t *tarball) open(name string) (*io.SectionReader, error) {
+	name = cleanPath(name)
+	for i := 0; i < 10; i++ {
+		e, ok := t.entries[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s is missing", ErrInvalidImage, name)
+		} else if e.linkname == "" {
+			return io.NewSectionReader(t.reader, e.offset, e.size), nil
+		}
+		name = e.linkname
+	}
+
+	return nil, fmt.Errorf("%w: too many links for %s", ErrInvalidImage, name)
+}
+
+func (t *tarball) readJson(name string, v interface{}) error {
+	reader, err := t.open(name)
+	if err != nil {
+		return err
+	}
+
+	if err := json.NewDecoder(reader).Decode(v); err != nil {
+		return fmt.Errorf("%w: invalid %s: %v", ErrInvalidImage, name, err)
+	}
+	return nil
+}
+
+type dockerManifest struct {
+	Config   string   `json:"Config"`
+	RepoTags []string `json:"RepoTags"`
+	Layers   []string `json:"Layers"`
+}
+
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Annotations map[string]string `json:"annotations"`
+	Platform    *struct {
+		Os           string `json:"os"`
+		Architecture string `json:"architecture"`
+	} `json:"platform"`
+}
+
+type ociIndex struct {
+	Manifests []ociDescriptor `json:"manifests"`
+}
+
+type ociManifest struct {
+	MediaType string          `json:"mediaType"`
+	Config    ociDescriptor   `json:"config"`
+	Layers    []ociDescriptor `json:"layers"`
+	Manifests []ociDescriptor `json:"manifests"`
+}
+
+// returns path of blob in oci layout
+func blobPath(digest string) string {
+	algorithm, hex, _ := strings.Cut(digest, ":")
+	return path.Join("blobs", algorithm, hex)
+}
+
+// returns digest of docker save config file such as <hex>.json or blobs/sha256/<hex>
+func configDigest(config string) string {
+	config = strings.TrimSuffix(config, ".json")
+	if strings.HasPrefix(config, "blobs/") {
+		parts := strings.Split(config, "/")
+		return parts[1] + ":" + parts[len(parts)-1]
+	}
+
+	return "sha256:" + path.Base(config)
+}
+
+// selects manifest of linux/amd64 platform when index lists manifests of several platforms
+func selectManifest(manifests []ociDescriptor) (ociDescriptor, error) {
+	if len(manifests) == 0 {
+		return ociDescriptor{}, fmt.Errorf("%w: image index has no manifests", ErrInvalidImage)
+	}
+
+	for _, manifest := range manifests {
+		if manifest.Platform != nil && manifest.Platform.Os == "linux" && manifest.Platform.Architecture == "amd64" {
+			return manifest, nil
+		}
+	}
+
+	return manifests[0], nil
+}
+
+// returns name, config digest and layer paths of image, docker save manifest is preferred over oci index
+func (t *tarball) layers() (string, string, []string, error) {
+	if _, ok := t.entries["manifest.json"]; ok {
+		var manifests []dockerManifest
+		if err := t.readJson("manifest.json", &manifests); err != nil {
+			return "", "", nil, err
+		} else if len(manifests) == 0 {
+			return "", "", nil, fmt.Errorf("%w: manifest.json has no images", ErrInvalidImage)
+		}
+
+		name := ""
+		if len(manifests[0].RepoTags) > 0 {
+			name = manifests[0].RepoTags[0]
+		}
+		return name, configDigest(manifests[0].Config), manifests[0].Layers, nil
+	}
+
+	var index ociIndex
+	if err := t.readJson("index.json", &index); err != nil {
+		return "", "", nil, err
+	}
+
+	descriptor, err := selectManifest(index.Manifests)
+	if err != nil {
+		return "", "", nil, err
+	}
+	name := descriptor.Annotations[containerdNameAnnotation]
+	if name == "" {
+		name = descriptor.Annotations[ociRefNameAnnotation]
+	}
+
+	// nested indexes list manifests of platforms
+	for i := 0; i < 5; i++ {
+		var manifest ociManifest
+		if err := t.readJson(blobPath(descriptor.Digest), &manifest); err != nil {
+			return "", "", nil, err
+		}
+
+		if manifest.MediaType != ociIndexMediaType && manifest.MediaType != dockerListMediaType && len(manifest.Manifests) == 0 {
+			layers := []string{}
+			for _, layer := range manifest.Layers {
+				layers = append(layers, blobPath(layer.Digest))
+			}
+			return name, manifest.Config.Digest, layers, nil
+		}
+
+		if descriptor, err = selectManifest(manifest.Manifests); err != nil {
+			return "", "", nil, err
+		}
+	}
+
+	return "", "", nil, fmt.Errorf("%w: too many nested image indexes", ErrInvalidImage)
+}
+
+// returns reader of uncompressed layer
+func openLayer(reader io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(reader)
+	magic, _ := buffered.Peek(4)
+
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		return gzip.NewReader(buffered)
+	case bytes.HasPrefix(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		return nil, fmt.Errorf("%w: zstd", ErrUnsupportedLayer)
+	}
+
+	return buffered, nil
+}
+
+// filesystem keeps files of flattened layers which are needed for inventory
+type filesystem struct {
+	files map[string][]byte
+	links map[string]string
+	size  int64 // total size of files
+}
+
+// returns true for os-release and package database files
+func isTracked(name string) bool {
+	switch name {
+	case "etc/os-release", "usr/lib/os-release",
+		dpkgStatusPath, apkInstalledPath:
+		return true
+	}
+
+	return strings.HasPrefix(name, dpkgStatusDir) || slices.Contains(rpmDbPaths, name)
+}
+
+// removes tracked files at or below name which were not added by current layer, empty name
+// removes every file
+func (f *filesystem) remove(name string, added map[string]bool) {
+	hidden := func(file string) bool {
+		return !added[file] && (name == "" || file == name || strings.HasPrefix(file, name+"/"))
+	}
+
+	for file, content := range f.files {
+		if hidden(file) {
+			f.size -= int64(len(content))
+			delete(f.files, file)
+		}
+	}
+	for file := range f.links {
+		if hidden(file) {
+			delete(f.links, file)
+		}
+	}
+}
+
+// applies layer to filesystem, whiteout files remove files of lower layers
+func (f *filesystem) applyLayer(reader io.Reader) error {
+	tarReader := tar.NewReader(reader)
+	added := map[string]bool{}
+
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("%w: invalid layer: %v", ErrInvalidImage, err)
+		}
+
+		name := cleanPath(header.Name)
+		dir, base := path.Split(name)
+		dir = strings.TrimSuffix(dir, "/")
+
+		switch {
+		case base == ".wh..wh..opq":
+			f.remove(dir, added)
+			continue
+		case strings.HasPrefix(base, ".wh."):
+			f.remove(path.Join(dir, strings.TrimPrefix(base, ".wh.")), added)
+			continue
+		}
+
+		// directories are merged with lower layers while other entries replace them
+		if header.Typeflag == tar.TypeDir {
+			continue
+		}
+		f.remove(name, added)
+		if !isTracked(name) {
+			continue
+		}
+
+		switch header.Typeflag {
+		case tar.TypeReg:
+			if header.Size > maxTrackedFileSize {
+				return fmt.Errorf("%w: %s is too large", ErrInvalidImage, name)
+			}
+			// file may be listed more than once by the same layer
+			existing := int64(len(f.files[name]))
+			if f.size-existing+header.Size > maxTrackedFilesSize {
+				return fmt.Errorf("%w: os package databases exceed %d MB", ErrImageTooLarge, maxTrackedFilesSize>>20)
+			}
+			content, err := io.ReadAll(tarReader)
+			if err != nil {
+				return fmt.Errorf("%w: invalid layer: %v", ErrInvalidImage, err)
+			}
+			f.files[name] = content
+			f.size += int64(len(content)) - existing
+			added[name] = true
+		case tar.TypeSymlink:
+			f.links[name] = cleanPath(path.Join(path.Dir(name), header.Linkname))
+			added[name] = true
+		}
+	}
+}
+
+// returns content of file, symlinks between tracked files are followed
+func (f *filesystem) read(name string) ([]byte, bool) {
+	for i := 0; i < 10; i++ {
+		if content, ok := f.files[name]; ok {
+			return content, true
+		}
+
+		link, ok := f.links[name]
+		if !ok {
+			return nil, false
+		}
+		name = link
+	}
+
+	return nil, false
+}
+
+// returns content of tracked files below dir
+func (f *filesystem) readDir(dir string) map[string][]byte {
+	files := map[string][]byte{}
+	for name, content := range f.files {
+		if strings.HasPrefix(name, dir) {
+			files[name] = content
+		}
+	}
+
+	return files
+}
+
+// flattens layers of image tarball into filesystem of tracked files
+func flatten(reader io.ReaderAt, size int64) (*filesystem, string, string, error) {
+	t, err := newTarball(reader, size)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	name, digest, layers, err := t.layers()
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	f := &filesystem{files: map[string][]byte{}, links: map[string]string{}}
+	for _, layer := range layers {
+		layerReader, err := t.open(layer)
+		if err != nil {
+			return nil, "", "", err
+		}
+
+		uncompressed, err := openLayer(layerReader)
+		if err != nil {
+			return nil, "", "", err
+		}
+		if err := f.applyLayer(uncompressed); err != nil {
+			return nil, "", "", err
+		}
+	}
+
+	return f, name, digest, nil
+}
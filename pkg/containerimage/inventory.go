@@ -0,0 +1,291 @@
+package containerimage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/google/uuid"
+	packageurl "github.com/package-url/packageurl-go"
+)
+
+const (
+	PackageTypeDeb = "deb"
+	PackageTypeApk = "apk"
+	PackageTypeRpm = "rpm"
+)
+
+// purl namespaces used when image has no os-release file
+var defaultNamespaces = map[string]string{
+	PackageTypeDeb: "debian",
+	PackageTypeApk: "alpine",
+	PackageTypeRpm: "redhat",
+}
+
+// Package is an os package installed in image
+type Package struct {
+	Type          string
+	Name          string
+	Version       string
+	Arch          string
+	Epoch         int
+	Source        string // source package of deb and rpm packages, origin of apk packages
+	SourceVersion string
+	License       string
+	Supplier      string // maintainer or vendor
+	Purl          string
+	Dependencies  []string // purls of installed dependencies
+
+	requires [][]string // alternatives of required packages or capabilities
+	provides []string
+}
+
+// Inventory is os of image along with its installed packages
+type Inventory struct {
+	Name     string // first repo tag or reference name of image
+	Digest   string // digest of image config
+	Os       OsRelease
+	Packages []Package
+}
+
+// returns package url of os package with arch, distro, epoch and upstream qualifiers
+func packagePurl(pkg Package, osRelease OsRelease) string {
+	namespace := osRelease.Id
+	if namespace == "" {
+		namespace = defaultNamespaces[pkg.Type]
+	}
+
+	qualifiers := map[string]string{}
+	if pkg.Arch != "" {
+		qualifiers["arch"] = pkg.Arch
+	}
+	if distro := osRelease.Distro(); distro != "" {
+		qualifiers["distro"] = distro
+	}
+	if pkg.Epoch > 0 {
+		qualifiers["epoch"] = strconv.Itoa(pkg.Epoch)
+	}
+	if pkg.Source != pkg.Name || pkg.SourceVersion != pkg.Version {
+		qualifiers["upstream"] = pkg.Source
+		if pkg.SourceVersion != pkg.Version {
+			qualifiers["upstream"] += "@" + pkg.SourceVersion
+		}
+	}
+
+	return packageurl.NewPackageURL(pkg.Type, namespace, pkg.Name, pkg.Version, packageurl.QualifiersFromMap(qualifiers), "").ToString()
+}
+
+// sets purls of packages and resolves their dependencies using capabilities provided by packages
+func resolvePackages(packages []Package, osRelease OsRelease) []Package {
+	providers := map[string]string{}
+	for i := range packages {
+		packages[i].Purl = packagePurl(packages[i], osRelease)
+		for _, provided := range packages[i].provides {
+			if _, ok := providers[provided]; !ok {
+				providers[provided] = packages[i].Purl
+			}
+		}
+	}
+
+	for i := range packages {
+		seen := map[string]bool{packages[i].Purl: true}
+		for _, alternatives := range packages[i].requires {
+			for _, required := range alternatives {
+				if purl, ok := providers[required]; ok {
+					if !seen[purl] {
+						seen[purl] = true
+						packages[i].Dependencies = append(packages[i].Dependencies, purl)
+					}
+					break
+				}
+			}
+		}
+		sort.Strings(packages[i].Dependencies)
+	}
+
+	return packages
+}
+
+// Scan flattens layers of docker save or oci layout tarball and returns os packages installed in
+// image along with its distribution. Gzip compressed tarballs are decompressed to a temporary file
+// and rejected when larger than maxSize.
+func Scan(reader io.ReaderAt, size, maxSize int64) (*Inventory, error) {
+	magic := make([]byte, 2)
+	if _, err := reader.ReadAt(magic, 0); err == nil && bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		return scanCompressed(reader, size, maxSize)
+	} else if size > maxSize {
+		return nil, ErrImageTooLarge
+	}
+
+	return scanTarball(reader, size)
+}
+
+func scanTarball(reader io.ReaderAt, size int64) (*Inventory, error) {
+	f, name, digest, err := flatten(reader, size)
+	if err != nil {
+		return nil, err
+	}
+
+	inventory := &Inventory{Name: name, Digest: digest}
+	for _, osReleasePath := range []string{"etc/os-release", "usr/lib/os-release"} {
+		if content, ok := f.read(osReleasePath); ok {
+			inventory.Os = parseOsRelease(content)
+			break
+		}
+	}
+
+	packages := readDpkgPackages(f)
+	if content, ok := f.read(apkInstalledPath); ok {
+		packages = append(packages, parseApkPackages(content)...)
+	}
+	rpmPackages, err := readRpmPackages(f)
+	if err != nil {
+		return nil, err
+	}
+	packages = append(packages, rpmPackages...)
+
+	if len(packages) == 0 {
+		return nil, ErrNoPackages
+	}
+	inventory.Packages = resolvePackages(packages, inventory.Os)
+
+	return inventory, nil
+}
+
+func scanCompressed(reader io.ReaderAt, size, maxSize int64) (*Inventory, error) {
+	gzipReader, err := gzip.NewReader(io.NewSectionReader(reader, 0, size))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	defer gzipReader.Close()
+
+	tmp, err := os.CreateTemp("", "defect-detect-image-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	written, err := io.Copy(tmp, io.LimitReader(gzipReader, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	} else if written > maxSize {
+		return nil, ErrImageTooLarge
+	}
+
+	return scanTarball(tmp, written)
+}
+
+// splits image reference such as docker.io/library/nginx:1.25 into name and tag
+func splitImageReference(reference string) (string, string) {
+	reference, _, _ = strings.Cut(reference, "@")
+	if i := strings.LastIndex(reference, ":"); i > strings.LastIndex(reference, "/") {
+		return reference[:i], reference[i+1:]
+	}
+
+	return reference, ""
+}
+
+// ToBom builds cyclonedx sbom of image inventory. Image is the root component which depends on its
+// operating system, which in turn depends on packages no other package depends on.
+func ToBom(inventory *Inventory, name string) *cyclonedx.BOM {
+	if name == "" {
+		name = inventory.Name
+	}
+	imageName, tag := splitImageReference(name)
+
+	root := cyclonedx.Component{
+		BOMRef:  "image:" + name,
+		Type:    cyclonedx.ComponentTypeContainer,
+		Name:    imageName,
+		Version: tag,
+	}
+	properties := []cyclonedx.Property{}
+	if inventory.Digest != "" {
+		properties = append(properties, cyclonedx.Property{Name: "defect-detect:image:digest", Value: inventory.Digest})
+	}
+
+	osComponent := cyclonedx.Component{
+		BOMRef:      fmt.Sprintf("os:%s", inventory.Os.Distro()),
+		Type:        cyclonedx.ComponentTypeOS,
+		Name:        inventory.Os.Id,
+		Version:     inventory.Os.VersionId,
+		Description: inventory.Os.PrettyName,
+	}
+	if osComponent.Name == "" {
+		osComponent.Name = "unknown"
+		osComponent.BOMRef = "os:unknown"
+	}
+
+	components := []cyclonedx.Component{osComponent}
+	dependencies := []cyclonedx.Dependency{{Ref: root.BOMRef, Dependencies: &[]string{osComponent.BOMRef}}}
+
+	dependedOn := map[string]bool{}
+	for _, pkg := range inventory.Packages {
+		for _, dependency := range pkg.Dependencies {
+			dependedOn[dependency] = true
+		}
+	}
+
+	topLevel := []string{}
+	seen := map[string]bool{}
+	packageDependencies := []cyclonedx.Dependency{}
+	for _, pkg := range inventory.Packages {
+		// multiarch and distroless images may list a package more than once
+		if seen[pkg.Purl] {
+			continue
+		}
+		seen[pkg.Purl] = true
+
+		component := cyclonedx.Component{
+			BOMRef:     pkg.Purl,
+			Type:       cyclonedx.ComponentTypeLibrary,
+			Name:       pkg.Name,
+			Version:    pkg.Version,
+			PackageURL: pkg.Purl,
+			Publisher:  pkg.Supplier,
+			Properties: &[]cyclonedx.Property{
+				{Name: "defect-detect:os:package-type", Value: pkg.Type},
+				{Name: "defect-detect:os:source", Value: pkg.Source},
+				{Name: "defect-detect:os:source-version", Value: pkg.SourceVersion},
+			},
+		}
+		if pkg.License != "" {
+			component.Licenses = &cyclonedx.Licenses{{License: &cyclonedx.License{Name: pkg.License}}}
+		}
+		components = append(components, component)
+
+		pkgDependencies := append([]string{}, pkg.Dependencies...)
+		packageDependencies = append(packageDependencies, cyclonedx.Dependency{Ref: pkg.Purl, Dependencies: &pkgDependencies})
+		if !dependedOn[pkg.Purl] {
+			topLevel = append(topLevel, pkg.Purl)
+		}
+	}
+
+	dependencies = append(dependencies, cyclonedx.Dependency{Ref: osComponent.BOMRef, Dependencies: &topLevel})
+	dependencies = append(dependencies, packageDependencies...)
+
+	return &cyclonedx.BOM{
+		BOMFormat:    cyclonedx.BOMFormat,
+		SpecVersion:  cyclonedx.SpecVersion1_5,
+		SerialNumber: uuid.New().URN(),
+		Version:      1,
+		Metadata: &cyclonedx.Metadata{
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Tools: &cyclonedx.ToolsChoice{
+				Components: &[]cyclonedx.Component{{Type: cyclonedx.ComponentTypeApplication, Name: "defect-detect"}},
+			},
+			Component:  &root,
+			Properties: &properties,
+		},
+		Components:   &components,
+		Dependencies: &dependencies,
+	}
+}
@@ -0,0 +1,57 @@
+package containerimage
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// OsRelease is distribution of image described by os-release file
+type OsRelease struct {
+	Id         string `json:"id"`
+	VersionId  string `json:"version_id"`
+	Codename   string `json:"codename,omitempty"`
+	PrettyName string `json:"pretty_name,omitempty"`
+}
+
+// Distro returns distro qualifier of package urls such as debian-12 or alpine-3.19.1. Codename is
+// used for releases without version such as debian sid.
+func (o OsRelease) Distro() string {
+	if o.Id == "" {
+		return ""
+	} else if o.VersionId != "" {
+		return o.Id + "-" + o.VersionId
+	} else if o.Codename != "" {
+		return o.Id + "-" + o.Codename
+	}
+
+	return o.Id
+}
+
+// parses os-release file, values may be quoted using shell quoting
+func parseOsRelease(content []byte) OsRelease {
+	values := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		} else {
+			value = strings.Trim(value, `"'`)
+		}
+		values[key] = value
+	}
+
+	return OsRelease{
+		Id:         strings.ToLower(values["ID"]),
+		VersionId:  values["VERSION_ID"],
+		Codename:   values["VERSION_CODENAME"],
+		PrettyName: values["PRETTY_NAME"],
+	}
+}
@@ -0,0 +1,108 @@
+package containerimage
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	rpmdb "github.com/knqyf263/go-rpmdb/pkg"
+)
+
+// rpm databases in berkeley db, ndb and sqlite format, newer distros keep them in /usr/lib/sysimage
+var rpmDbPaths = []string{
+	"var/lib/rpm/rpmdb.sqlite",
+	"var/lib/rpm/Packages.db",
+	"var/lib/rpm/Packages",
+	"usr/lib/sysimage/rpm/rpmdb.sqlite",
+	"usr/lib/sysimage/rpm/Packages.db",
+	"usr/lib/sysimage/rpm/Packages",
+}
+
+// splits source rpm file name such as openssl-1.1.1k-7.el8.src.rpm into name and version-release
+func parseSourceRpm(sourceRpm string) (string, string) {
+	nvr := strings.TrimSuffix(strings.TrimSuffix(sourceRpm, ".rpm"), ".src")
+
+	releaseStart := strings.LastIndex(nvr, "-")
+	if releaseStart <= 0 {
+		return nvr, ""
+	}
+	versionStart := strings.LastIndex(nvr[:releaseStart], "-")
+	if versionStart <= 0 {
+		return nvr, ""
+	}
+
+	return nvr[:versionStart], nvr[versionStart+1:]
+}
+
+// returns packages of rpm database, database is written to a temporary file as rpm databases can
+// only be opened from files
+func parseRpmPackages(name string, content []byte) ([]Package, error) {
+	dir, err := os.MkdirTemp("", "defect-detect-rpmdb-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	dbPath := filepath.Join(dir, path.Base(name))
+	if err := os.WriteFile(dbPath, content, 0600); err != nil {
+		return nil, err
+	}
+
+	db, err := rpmdb.Open(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid rpm database %s: %v", ErrInvalidImage, name, err)
+	}
+	defer db.Close()
+
+	rpmPackages, err := db.ListPackages()
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid rpm database %s: %v", ErrInvalidImage, name, err)
+	}
+
+	packages := []Package{}
+	for _, rpmPackage := range rpmPackages {
+		// gpg-pubkey entries are imported signing keys
+		if rpmPackage.Name == "gpg-pubkey" {
+			continue
+		}
+
+		pkg := Package{
+			Type:     PackageTypeRpm,
+			Name:     rpmPackage.Name,
+			Version:  rpmPackage.Version + "-" + rpmPackage.Release,
+			Arch:     rpmPackage.Arch,
+			Epoch:    rpmPackage.EpochNum(),
+			License:  rpmPackage.License,
+			Supplier: rpmPackage.Vendor,
+			provides: append([]string{rpmPackage.Name}, rpmPackage.Provides...),
+		}
+		pkg.Source, pkg.SourceVersion = parseSourceRpm(rpmPackage.SourceRpm)
+		if pkg.Source == "" {
+			pkg.Source, pkg.SourceVersion = pkg.Name, pkg.Version
+		}
+
+		// file dependencies such as /bin/sh are satisfied by installed files
+		if files, err := rpmPackage.InstalledFileNames(); err == nil {
+			pkg.provides = append(pkg.provides, files...)
+		}
+		for _, required := range rpmPackage.Requires {
+			pkg.requires = append(pkg.requires, []string{required})
+		}
+		packages = append(packages, pkg)
+	}
+
+	return packages, nil
+}
+
+// returns packages of first rpm database found in filesystem
+func readRpmPackages(f *filesystem) ([]Package, error) {
+	for _, name := range rpmDbPaths {
+		if content, ok := f.read(name); ok {
+			return parseRpmPackages(name, content)
+		}
+	}
+
+	return []Package{}, nil
+}
@@ -2,6 +2,9 @@ package db
 
 import (
 	"context"
+	"errors"
+	"strings"
+	"time"
 
 	"github.com/dmdhrumilmistry/defect-detect/pkg/config"
 	"github.com/rs/zerolog/log"
@@ -57,3 +60,33 @@ func EnsureIndex(collection *mongo.Collection, indexModel mongo.IndexModel) {
 		log.Info().Msgf("Index Created successfully: %s", indexName)
 	}
 }
+
+// returns true for errors of standalone servers, which only support transactions as replica set
+// members or through mongos
+func isTransactionUnsupported(err error) bool {
+	var cmdErr mongo.CommandError
+	return errors.As(err, &cmdErr) && strings.Contains(cmdErr.Message, "Transaction numbers are only allowed")
+}
+
+// WithTransaction runs fn in a transaction, operations of fn must use the context passed to fn. On
+// servers without transaction support fn is run without transaction and false is returned.
+func WithTransaction(client *mongo.Client, duration int, fn func(ctx context.Context) error) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(duration)*time.Second)
+	defer cancel()
+
+	session, err := client.StartSession()
+	if err != nil {
+		return false, err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	})
+	if isTransactionUnsupported(err) {
+		log.Warn().Msg("mongodb server does not support transactions, running operations without transaction")
+		return false, fn(ctx)
+	}
+
+	return true, err
+}
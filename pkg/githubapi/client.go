@@ -0,0 +1,182 @@
+package githubapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	apiVersion  = "2022-11-28"
+	perPage     = 100
+	maxRepoPage = 50 // at most 5000 repos are listed for an account
+)
+
+var (
+	ErrNotFound   = errors.New("github resource not found")
+	ErrInvalidUrl = errors.New("invalid github api url")
+
+	nameRegex = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+	refRegex  = regexp.MustCompile(`^[a-zA-Z0-9_./-]+$`)
+)
+
+// ApiError is returned for unexpected github api responses
+type ApiError struct {
+	StatusCode int
+	Path       string
+}
+
+func (e *ApiError) Error() string {
+	return fmt.Sprintf("github api %s responded with status %d", e.Path, e.StatusCode)
+}
+
+type Repo struct {
+	Name          string `json:"name"`
+	FullName      string `json:"full_name"`
+	Description   string `json:"description"`
+	HtmlUrl       string `json:"html_url"`
+	DefaultBranch string `json:"default_branch"`
+	Archived      bool   `json:"archived"`
+	Fork          bool   `json:"fork"`
+}
+
+// DependencyChange is dependency added or removed between two refs of a repo
+type DependencyChange struct {
+	ChangeType string `json:"change_type"` // added or removed
+	Manifest   string `json:"manifest"`
+	Ecosystem  string `json:"ecosystem"`
+	Name       string `json:"name"`
+	Version    string `json:"version"`
+	PackageUrl string `json:"package_url"`
+	License    string `json:"license"`
+}
+
+type Client struct {
+	apiUrl     string
+	token      string
+	httpClient *http.Client
+}
+
+// NewClient returns client of github api at apiUrl, e.g. https://api.github.com or
+// https://github.example.com/api/v3 for github enterprise server
+func NewClient(apiUrl, token string) *Client {
+	return &Client{
+		apiUrl:     strings.TrimRight(apiUrl, "/"),
+		token:      token,
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// ValidateApiUrl returns error when api url is not an absolute http(s) url
+func ValidateApiUrl(apiUrl string) error {
+	parsed, err := url.Parse(apiUrl)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		return ErrInvalidUrl
+	}
+
+	return nil
+}
+
+// IsValidName returns true for valid github owner and repo names
+func IsValidName(name string) bool {
+	return nameRegex.MatchString(name) && name != "." && name != ".."
+}
+
+// IsValidRef returns true for valid branch and tag names
+func IsValidRef(ref string) bool {
+	return refRegex.MatchString(ref) && !strings.Contains(ref, "..")
+}
+
+// sends GET request to api path and decodes json response into v
+func (c *Client) get(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.apiUrl+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Accept", "application/vnd.github+json")
+	req.Header.Add("X-GitHub-Api-Version", apiVersion)
+	if c.token != "" {
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", c.token))
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	} else if res.StatusCode != http.StatusOK {
+		return &ApiError{StatusCode: res.StatusCode, Path: path}
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+func (c *Client) GetRepo(ctx context.Context, owner, repoName string) (Repo, error) {
+	var repo Repo
+	err := c.get(ctx, fmt.Sprintf("/repos/%s/%s", owner, repoName), &repo)
+	return repo, err
+}
+
+// ListRepos lists repos of org, falling back to repos of user account when owner is not an org
+func (c *Client) ListRepos(ctx context.Context, owner string) ([]Repo, error) {
+	repos, err := c.listRepos(ctx, fmt.Sprintf("/orgs/%s/repos?type=all", owner))
+	if errors.Is(err, ErrNotFound) {
+		log.Info().Msgf("github owner %s is not an org, listing user repos", owner)
+		repos, err = c.listRepos(ctx, fmt.Sprintf("/users/%s/repos?type=owner", owner))
+	}
+
+	return repos, err
+}
+
+func (c *Client) listRepos(ctx context.Context, path string) ([]Repo, error) {
+	repos := []Repo{}
+	for page := 1; page <= maxRepoPage; page++ {
+		var pageRepos []Repo
+		if err := c.get(ctx, fmt.Sprintf("%s&per_page=%d&page=%d", path, perPage, page), &pageRepos); err != nil {
+			return repos, err
+		}
+
+		repos = append(repos, pageRepos...)
+		if len(pageRepos) < perPage {
+			break
+		}
+	}
+
+	return repos, nil
+}
+
+// GetSbom returns spdx json sbom of repo default branch exported from dependency graph
+func (c *Client) GetSbom(ctx context.Context, owner, repoName string) ([]byte, error) {
+	var resp types.GithubRepoImportResponseSchema
+	if err := c.get(ctx, fmt.Sprintf("/repos/%s/%s/dependency-graph/sbom", owner, repoName), &resp); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(resp.Sbom)
+}
+
+// CompareDependencies returns dependencies changed from base to head ref. Refs are expected to be
+// validated using IsValidRef.
+func (c *Client) CompareDependencies(ctx context.Context, owner, repoName, base, head string) ([]DependencyChange, error) {
+	var changes []DependencyChange
+	err := c.get(ctx, fmt.Sprintf("/repos/%s/%s/dependency-graph/compare/%s...%s", owner, repoName, base, head), &changes)
+	return changes, err
+}
@@ -0,0 +1,135 @@
+package githubapi
+
+import (
+	"fmt"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+)
+
+const (
+	ChangeTypeAdded   = "added"
+	ChangeTypeRemoved = "removed"
+)
+
+// returns identity of dependency used to match components, purl when available
+func dependencyKey(purl, name, version string) string {
+	if purl != "" {
+		return purl
+	}
+
+	return fmt.Sprintf("%s@%s", name, version)
+}
+
+// ApplyDependencyChanges updates sbom of default branch with dependencies changed on another ref,
+// since dependency graph sboms can only be exported for default branch. Added dependencies depend
+// on root component.
+func ApplyDependencyChanges(bom *cyclonedx.BOM, changes []DependencyChange) types.GithubDependencyDiff {
+	diff := types.GithubDependencyDiff{}
+
+	removed := map[string]bool{}
+	for _, change := range changes {
+		if change.ChangeType == ChangeTypeRemoved {
+			removed[dependencyKey(change.PackageUrl, change.Name, change.Version)] = true
+		}
+	}
+
+	components := []cyclonedx.Component{}
+	existing := map[string]bool{}
+	refs := map[string]bool{}
+	removedRefs := map[string]bool{}
+	if bom.Components != nil {
+		for _, component := range *bom.Components {
+			key := dependencyKey(component.PackageURL, component.Name, component.Version)
+			if removed[key] {
+				removedRefs[component.BOMRef] = true
+				diff.Removed++
+				continue
+			}
+
+			existing[key] = true
+			refs[component.BOMRef] = true
+			components = append(components, component)
+		}
+	}
+
+	var rootRef string
+	if bom.Metadata != nil && bom.Metadata.Component != nil {
+		rootRef = bom.Metadata.Component.BOMRef
+	}
+
+	addedRefs := []string{}
+	for _, change := range changes {
+		key := dependencyKey(change.PackageUrl, change.Name, change.Version)
+		if change.ChangeType != ChangeTypeAdded || existing[key] {
+			continue
+		}
+		existing[key] = true
+
+		component := cyclonedx.Component{
+			Type:       cyclonedx.ComponentTypeLibrary,
+			BOMRef:     key,
+			Name:       change.Name,
+			Version:    change.Version,
+			PackageURL: change.PackageUrl,
+		}
+		for i := 1; refs[component.BOMRef]; i++ {
+			component.BOMRef = fmt.Sprintf("%s#%d", key, i)
+		}
+		refs[component.BOMRef] = true
+
+		if change.License != "" {
+			component.Licenses = &cyclonedx.Licenses{{Expression: change.License}}
+		}
+
+		components = append(components, component)
+		addedRefs = append(addedRefs, component.BOMRef)
+		diff.Added++
+	}
+	bom.Components = &components
+
+	bom.Dependencies = updateDependencies(bom.Dependencies, removedRefs, rootRef, addedRefs)
+
+	return diff
+}
+
+// removes dependencies of removed components and adds added components to dependencies of root
+func updateDependencies(dependencies *[]cyclonedx.Dependency, removedRefs map[string]bool, rootRef string, addedRefs []string) *[]cyclonedx.Dependency {
+	updated := []cyclonedx.Dependency{}
+	rootFound := false
+	if dependencies != nil {
+		for _, dependency := range *dependencies {
+			if removedRefs[dependency.Ref] {
+				continue
+			}
+
+			dependsOn := []string{}
+			if dependency.Dependencies != nil {
+				for _, ref := range *dependency.Dependencies {
+					if !removedRefs[ref] {
+						dependsOn = append(dependsOn, ref)
+					}
+				}
+			}
+
+			if rootRef != "" && dependency.Ref == rootRef {
+				rootFound = true
+				dependsOn = append(dependsOn, addedRefs...)
+			}
+
+			dependency.Dependencies = &dependsOn
+			updated = append(updated, dependency)
+		}
+	}
+
+	if rootRef != "" && !rootFound && len(addedRefs) > 0 {
+		dependsOn := append([]string{}, addedRefs...)
+		updated = append(updated, cyclonedx.Dependency{Ref: rootRef, Dependencies: &dependsOn})
+	}
+
+	if dependencies == nil && len(updated) == 0 {
+		return nil
+	}
+
+	return &updated
+}
@@ -0,0 +1,128 @@
+package lockfile
+
+import (
+	"sort"
+	"time"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/google/uuid"
+)
+
+// returns packages of lockfile which no other package of the lockfile depends on
+func undependedPackages(lockfile *Lockfile) []string {
+	dependedOn := map[string]bool{}
+	for _, pkg := range lockfile.Packages {
+		for _, dependency := range pkg.Dependencies {
+			dependedOn[dependency] = true
+		}
+	}
+
+	direct := []string{}
+	for _, pkg := range lockfile.Packages {
+		if !dependedOn[pkg.Purl] {
+			direct = append(direct, pkg.Purl)
+		}
+	}
+
+	return direct
+}
+
+// ToBom builds cyclonedx sbom of lockfiles of a project. Packages are referenced using their purl,
+// project described by root component depends on direct dependencies of every lockfile.
+func ToBom(lockfiles []*Lockfile, name, version string) *cyclonedx.BOM {
+	root := cyclonedx.Component{
+		BOMRef:  "root:" + name,
+		Type:    cyclonedx.ComponentTypeApplication,
+		Name:    name,
+		Version: version,
+	}
+	if version != "" {
+		root.BOMRef += "@" + version
+	}
+
+	components := []cyclonedx.Component{}
+	componentIndex := map[string]int{}
+	dependencies := map[string]map[string]bool{root.BOMRef: {}}
+	dependencyRefs := []string{root.BOMRef}
+	properties := []cyclonedx.Property{}
+
+	for _, lockfile := range mergeGoSum(lockfiles) {
+		properties = append(properties, cyclonedx.Property{Name: "defect-detect:lockfile", Value: string(lockfile.Type)})
+
+		for _, pkg := range lockfile.Packages {
+			if i, ok := componentIndex[pkg.Purl]; ok {
+				// package locked by another lockfile is dev only when both lock it for development
+				if !pkg.Dev {
+					components[i].Scope = ""
+				}
+			} else {
+				component := cyclonedx.Component{
+					BOMRef:     pkg.Purl,
+					Type:       cyclonedx.ComponentTypeLibrary,
+					Name:       pkg.Name,
+					Version:    pkg.Version,
+					PackageURL: pkg.Purl,
+				}
+				if pkg.Dev {
+					component.Scope = cyclonedx.ScopeOptional
+				}
+				if pkg.Version == "" {
+					// version of unpinned packages is resolved at install time, they are not analyzed for vulns
+					component.Properties = &[]cyclonedx.Property{{Name: "defect-detect:unpinned", Value: "true"}}
+				}
+				if len(pkg.Hashes) > 0 {
+					hashes := pkg.Hashes
+					component.Hashes = &hashes
+				}
+
+				componentIndex[pkg.Purl] = len(components)
+				components = append(components, component)
+				dependencies[pkg.Purl] = map[string]bool{}
+				dependencyRefs = append(dependencyRefs, pkg.Purl)
+			}
+
+			for _, dependency := range pkg.Dependencies {
+				dependencies[pkg.Purl][dependency] = true
+			}
+		}
+
+		direct := lockfile.Direct
+		if direct == nil {
+			direct = undependedPackages(lockfile)
+		}
+		for _, dependency := range direct {
+			dependencies[root.BOMRef][dependency] = true
+		}
+	}
+
+	bom := &cyclonedx.BOM{
+		BOMFormat:    cyclonedx.BOMFormat,
+		SpecVersion:  cyclonedx.SpecVersion1_5,
+		SerialNumber: uuid.New().URN(),
+		Version:      1,
+		Metadata: &cyclonedx.Metadata{
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Tools: &cyclonedx.ToolsChoice{
+				Components: &[]cyclonedx.Component{{Type: cyclonedx.ComponentTypeApplication, Name: "defect-detect"}},
+			},
+			Component:  &root,
+			Properties: &properties,
+		},
+		Components: &components,
+	}
+
+	bomDependencies := []cyclonedx.Dependency{}
+	for _, ref := range dependencyRefs {
+		dependsOn := []string{}
+		for dependency := range dependencies[ref] {
+			if _, ok := componentIndex[dependency]; ok {
+				dependsOn = append(dependsOn, dependency)
+			}
+		}
+		sort.Strings(dependsOn)
+		bomDependencies = append(bomDependencies, cyclonedx.Dependency{Ref: ref, Dependencies: &dependsOn})
+	}
+	bom.Dependencies = &bomDependencies
+
+	return bom
+}
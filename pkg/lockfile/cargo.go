@@ -0,0 +1,90 @@
+package lockfile
+
+import (
+	"strings"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/pelletier/go-toml/v2"
+)
+
+type cargoPackage struct {
+	Name         string   `toml:"name"`
+	Version      string   `toml:"version"`
+	Source       string   `toml:"source"`
+	Checksum     string   `toml:"checksum"`
+	Dependencies []string `toml:"dependencies"`
+}
+
+type cargoLock struct {
+	Packages []cargoPackage `toml:"package"`
+}
+
+// parses Cargo.lock. Packages without source are crates of the workspace, single crate is the root
+// of the project while crates of larger workspaces are kept as components.
+func parseCargoLock(content []byte) (*Lockfile, error) {
+	var lock cargoLock
+	if err := toml.Unmarshal(content, &lock); err != nil {
+		return nil, err
+	}
+
+	// dependencies are referenced by name, or by name and version when several versions are locked
+	versions := map[string][]string{}
+	local := []cargoPackage{}
+	for _, lockPackage := range lock.Packages {
+		versions[lockPackage.Name] = append(versions[lockPackage.Name], lockPackage.Version)
+		if lockPackage.Source == "" {
+			local = append(local, lockPackage)
+		}
+	}
+
+	dependencyPurls := func(dependencies []string) []string {
+		purls := []string{}
+		for _, dependency := range dependencies {
+			fields := strings.Fields(dependency)
+			if len(fields) == 0 {
+				continue
+			}
+
+			version := ""
+			if len(fields) > 1 {
+				version = fields[1]
+			} else if len(versions[fields[0]]) == 1 {
+				version = versions[fields[0]][0]
+			}
+			if version != "" {
+				purls = append(purls, newPurl("cargo", "", fields[0], version, nil))
+			}
+		}
+		return purls
+	}
+
+	lockfile := &Lockfile{Direct: []string{}}
+	result := newPackages()
+	for _, lockPackage := range lock.Packages {
+		if len(local) == 1 && lockPackage.Source == "" {
+			lockfile.RootName, lockfile.RootVersion = lockPackage.Name, lockPackage.Version
+			lockfile.Direct = dependencyPurls(lockPackage.Dependencies)
+			continue
+		}
+
+		pkg := Package{
+			Name:         lockPackage.Name,
+			Version:      lockPackage.Version,
+			Purl:         newPurl("cargo", "", lockPackage.Name, lockPackage.Version, nil),
+			Dependencies: dependencyPurls(lockPackage.Dependencies),
+			Hashes:       newHashes(cyclonedx.HashAlgoSHA256, lockPackage.Checksum),
+		}
+		if lockPackage.Source == "" {
+			lockfile.Direct = append(lockfile.Direct, pkg.Purl)
+		}
+		result.add(pkg)
+	}
+	lockfile.Packages = result.result()
+
+	// lockfiles without workspace crates do not describe direct dependencies
+	if len(local) == 0 {
+		lockfile.Direct = nil
+	}
+
+	return lockfile, nil
+}
@@ -0,0 +1,68 @@
+package lockfile
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/CycloneDX/cyclonedx-go"
+)
+
+type composerPackage struct {
+	Name    string            `json:"name"`
+	Version string            `json:"version"`
+	Require map[string]string `json:"require"`
+	Dist    struct {
+		Shasum string `json:"shasum"`
+	} `json:"dist"`
+}
+
+type composerLock struct {
+	Packages    []composerPackage `json:"packages"`
+	PackagesDev []composerPackage `json:"packages-dev"`
+}
+
+func composerPurl(name, version string) string {
+	if vendor, pkgName, ok := strings.Cut(name, "/"); ok {
+		return newPurl("composer", vendor, pkgName, version, nil)
+	}
+
+	return newPurl("composer", "", name, version, nil)
+}
+
+// parses composer.lock, lockfile does not describe direct dependencies of the project. Platform
+// requirements such as php and extensions are not locked and are dropped.
+func parseComposerLock(content []byte) (*Lockfile, error) {
+	var lock composerLock
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return nil, err
+	}
+
+	purls := map[string]string{}
+	for _, lockPackage := range append(lock.Packages, lock.PackagesDev...) {
+		purls[strings.ToLower(lockPackage.Name)] = composerPurl(lockPackage.Name, lockPackage.Version)
+	}
+
+	result := newPackages()
+	for _, section := range []struct {
+		packages []composerPackage
+		dev      bool
+	}{{lock.Packages, false}, {lock.PackagesDev, true}} {
+		for _, lockPackage := range section.packages {
+			pkg := Package{
+				Name:    lockPackage.Name,
+				Version: lockPackage.Version,
+				Purl:    purls[strings.ToLower(lockPackage.Name)],
+				Dev:     section.dev,
+				Hashes:  newHashes(cyclonedx.HashAlgoSHA1, lockPackage.Dist.Shasum),
+			}
+			for _, dependency := range sortedKeys(lockPackage.Require) {
+				if purl, ok := purls[strings.ToLower(dependency)]; ok {
+					pkg.Dependencies = append(pkg.Dependencies, purl)
+				}
+			}
+			result.add(pkg)
+		}
+	}
+
+	return &Lockfile{Packages: result.result()}, nil
+}
@@ -0,0 +1,207 @@
+package lockfile
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+func golangPurl(modulePath, version string) string {
+	namespace, name := "", modulePath
+	if i := strings.LastIndex(modulePath, "/"); i >= 0 {
+		namespace, name = modulePath[:i], modulePath[i+1:]
+	}
+
+	return newPurl("golang", namespace, name, version, nil)
+}
+
+type goRequirement struct {
+	path     string
+	version  string
+	indirect bool
+}
+
+// splits go.mod directive line into fields, comment is returned separately
+func goModFields(line string) ([]string, string) {
+	line, comment, _ := strings.Cut(line, "//")
+	fields := strings.Fields(line)
+	for i, field := range fields {
+		fields[i] = strings.Trim(field, "\"`")
+	}
+
+	return fields, strings.TrimSpace(comment)
+}
+
+// parses go.mod, requirements are direct dependencies unless marked indirect. Replacements of
+// required modules are applied.
+func parseGoMod(content []byte) (*Lockfile, error) {
+	lockfile := &Lockfile{Direct: []string{}}
+	requirements := []goRequirement{}
+	// replacements keyed by module path and by module path@version
+	replacements := map[string][2]string{}
+
+	block := ""
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields, comment := goModFields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		directive := block
+		switch {
+		case block != "" && fields[0] == ")":
+			block = ""
+			continue
+		case block == "" && len(fields) == 2 && fields[1] == "(":
+			block = fields[0]
+			continue
+		case block == "":
+			directive, fields = fields[0], fields[1:]
+		}
+
+		switch directive {
+		case "module":
+			if len(fields) > 0 {
+				lockfile.RootName = fields[0]
+			}
+		case "require":
+			if len(fields) >= 2 {
+				requirements = append(requirements, goRequirement{
+					path:     fields[0],
+					version:  fields[1],
+					indirect: comment == "indirect" || strings.HasPrefix(comment, "indirect;"),
+				})
+			}
+		case "replace":
+			// module [version] => replacement [version], local replacements have no version
+			arrow := -1
+			for i, field := range fields {
+				if field == "=>" {
+					arrow = i
+				}
+			}
+			if arrow < 1 || arrow+1 >= len(fields) {
+				continue
+			}
+
+			key := fields[0]
+			if arrow == 2 {
+				key += "@" + fields[1]
+			}
+			replacement := [2]string{fields[arrow+1], ""}
+			if arrow+2 < len(fields) {
+				replacement[1] = fields[arrow+2]
+			}
+			replacements[key] = replacement
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if lockfile.RootName == "" {
+		return nil, errors.New("module directive is missing")
+	}
+
+	result := newPackages()
+	for _, requirement := range requirements {
+		path, version := requirement.path, requirement.version
+		replacement, ok := replacements[path+"@"+version]
+		if !ok {
+			replacement, ok = replacements[path]
+		}
+		if ok {
+			// local replacements are part of the project
+			if replacement[1] == "" {
+				continue
+			}
+			path, version = replacement[0], replacement[1]
+		}
+
+		purl := result.add(Package{Name: path, Version: version, Purl: golangPurl(path, version)})
+		if !requirement.indirect {
+			lockfile.Direct = append(lockfile.Direct, purl)
+		}
+	}
+	lockfile.Packages = result.result()
+
+	return lockfile, nil
+}
+
+// parses go.sum, which lists checksums of every module version considered during version
+// selection. Highest version of each module is the selected one.
+func parseGoSum(content []byte) (*Lockfile, error) {
+	selected := map[string]string{}
+	modules := []string{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		} else if len(fields) != 3 {
+			return nil, errors.New("malformed go.sum line")
+		}
+
+		// checksums of go.mod files only are recorded for modules which are not built
+		path, version := fields[0], fields[1]
+		if strings.HasSuffix(version, "/go.mod") {
+			continue
+		}
+
+		current, ok := selected[path]
+		if !ok {
+			modules = append(modules, path)
+		}
+		if !ok || semver.Compare(version, current) > 0 {
+			selected[path] = version
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	result := newPackages()
+	for _, path := range modules {
+		result.add(Package{Name: path, Version: selected[path], Purl: golangPurl(path, selected[path])})
+	}
+
+	return &Lockfile{Packages: result.result()}, nil
+}
+
+// returns lockfiles with go.sum modules required by go.mod removed, go.mod requirements describe
+// selected versions and direct dependencies while go.sum modules are transitive dependencies
+func mergeGoSum(lockfiles []*Lockfile) []*Lockfile {
+	required := map[string]bool{}
+	for _, lockfile := range lockfiles {
+		if lockfile.Type != GoMod {
+			continue
+		}
+		for _, pkg := range lockfile.Packages {
+			required[pkg.Name] = true
+		}
+	}
+	if len(required) == 0 {
+		return lockfiles
+	}
+
+	merged := []*Lockfile{}
+	for _, lockfile := range lockfiles {
+		if lockfile.Type == GoSum {
+			sum := &Lockfile{Type: GoSum, Direct: []string{}}
+			for _, pkg := range lockfile.Packages {
+				if !required[pkg.Name] {
+					sum.Packages = append(sum.Packages, pkg)
+				}
+			}
+			lockfile = sum
+		}
+		merged = append(merged, lockfile)
+	}
+
+	return merged
+}
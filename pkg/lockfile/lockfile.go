@@ -0,0 +1,194 @@
+package lockfile
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	packageurl "github.com/package-url/packageurl-go"
+)
+
+type Type string
+
+const (
+	NpmLock      Type = "package-lock.json"
+	YarnLock     Type = "yarn.lock"
+	PnpmLock     Type = "pnpm-lock.yaml"
+	GoMod        Type = "go.mod"
+	GoSum        Type = "go.sum"
+	Requirements Type = "requirements.txt"
+	PoetryLock   Type = "poetry.lock"
+	PipfileLock  Type = "Pipfile.lock"
+	CargoLock    Type = "Cargo.lock"
+	GemfileLock  Type = "Gemfile.lock"
+	ComposerLock Type = "composer.lock"
+)
+
+var (
+	ErrUnsupportedLockfile = errors.New("unsupported lockfile")
+	ErrInvalidLockfile     = errors.New("invalid lockfile")
+)
+
+// hex digest lengths of hash algorithms used by lockfiles
+var hashLengths = map[cyclonedx.HashAlgorithm]int{
+	cyclonedx.HashAlgoSHA1:   40,
+	cyclonedx.HashAlgoSHA256: 64,
+	cyclonedx.HashAlgoSHA384: 96,
+	cyclonedx.HashAlgoSHA512: 128,
+}
+
+// requirements files are often split by environment, e.g. requirements-dev.txt or dev-requirements.txt
+var requirementsNameRegex = regexp.MustCompile(`^([\w.-]*[-_.])?requirements([-_.][\w.-]*)?\.txt$`)
+
+// Package is a dependency locked by a lockfile
+type Package struct {
+	Name         string
+	Version      string
+	Purl         string
+	Dependencies []string // purls of dependencies
+	Dev          bool     // only required for development
+	Hashes       []cyclonedx.Hash
+}
+
+// Lockfile is dependency graph of a lockfile. Direct is nil when lockfile does not describe direct
+// dependencies of the project, packages which no other package depends on are considered direct.
+type Lockfile struct {
+	Type     Type
+	Packages []Package
+	Direct   []string
+
+	// root package described by lockfile or manifest
+	RootName    string
+	RootVersion string
+}
+
+// Detect returns lockfile type using its file name
+func Detect(fileName string) (Type, error) {
+	name := filepath.Base(strings.ReplaceAll(fileName, "\\", "/"))
+
+	for _, lockfileType := range []Type{NpmLock, YarnLock, PnpmLock, GoMod, GoSum, PoetryLock, PipfileLock, CargoLock, GemfileLock, ComposerLock} {
+		if strings.EqualFold(name, string(lockfileType)) {
+			return lockfileType, nil
+		}
+	}
+
+	if requirementsNameRegex.MatchString(strings.ToLower(name)) {
+		return Requirements, nil
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUnsupportedLockfile, fileName)
+}
+
+// Parse returns dependency graph of lockfile content
+func Parse(lockfileType Type, content []byte) (*Lockfile, error) {
+	var (
+		lockfile *Lockfile
+		err      error
+	)
+
+	switch lockfileType {
+	case NpmLock:
+		lockfile, err = parseNpmLock(content)
+	case YarnLock:
+		lockfile, err = parseYarnLock(content)
+	case PnpmLock:
+		lockfile, err = parsePnpmLock(content)
+	case GoMod:
+		lockfile, err = parseGoMod(content)
+	case GoSum:
+		lockfile, err = parseGoSum(content)
+	case Requirements:
+		lockfile, err = parseRequirements(content)
+	case PoetryLock:
+		lockfile, err = parsePoetryLock(content)
+	case PipfileLock:
+		lockfile, err = parsePipfileLock(content)
+	case CargoLock:
+		lockfile, err = parseCargoLock(content)
+	case GemfileLock:
+		lockfile, err = parseGemfileLock(content)
+	case ComposerLock:
+		lockfile, err = parseComposerLock(content)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedLockfile, lockfileType)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidLockfile, lockfileType, err)
+	}
+	lockfile.Type = lockfileType
+
+	return lockfile, nil
+}
+
+// returns package url string, namespace is omitted when empty
+func newPurl(purlType, namespace, name, version string, qualifiers map[string]string) string {
+	var purlQualifiers packageurl.Qualifiers
+	if len(qualifiers) > 0 {
+		purlQualifiers = packageurl.QualifiersFromMap(qualifiers)
+	}
+
+	return packageurl.NewPackageURL(purlType, namespace, name, version, purlQualifiers, "").ToString()
+}
+
+// returns hash of hex digest, malformed digests are dropped
+func newHashes(algorithm cyclonedx.HashAlgorithm, digest string) []cyclonedx.Hash {
+	if _, err := hex.DecodeString(digest); err != nil || len(digest) != hashLengths[algorithm] {
+		return nil
+	}
+
+	return []cyclonedx.Hash{{Algorithm: algorithm, Value: strings.ToLower(digest)}}
+}
+
+// packages collects locked packages, packages locked more than once are merged
+type packages struct {
+	index map[string]int
+	list  []Package
+}
+
+func newPackages() *packages {
+	return &packages{index: map[string]int{}}
+}
+
+// adds package and returns its purl
+func (p *packages) add(pkg Package) string {
+	if i, ok := p.index[pkg.Purl]; ok {
+		existing := &p.list[i]
+		existing.Dependencies = append(existing.Dependencies, pkg.Dependencies...)
+		// package is dev only when every occurrence is dev only
+		existing.Dev = existing.Dev && pkg.Dev
+		if len(existing.Hashes) == 0 {
+			existing.Hashes = pkg.Hashes
+		}
+		return pkg.Purl
+	}
+
+	p.index[pkg.Purl] = len(p.list)
+	p.list = append(p.list, pkg)
+	return pkg.Purl
+}
+
+// returns packages with sorted and deduplicated dependencies, dependencies on packages which are
+// not locked are dropped
+func (p *packages) result() []Package {
+	for i := range p.list {
+		seen := map[string]bool{}
+		dependencies := []string{}
+		for _, dependency := range p.list[i].Dependencies {
+			if _, ok := p.index[dependency]; !ok || seen[dependency] || dependency == p.list[i].Purl {
+				continue
+			}
+			seen[dependency] = true
+			dependencies = append(dependencies, dependency)
+		}
+		sort.Strings(dependencies)
+		p.list[i].Dependencies = dependencies
+	}
+
+	return p.list
+}
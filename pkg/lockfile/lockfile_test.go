@@ -0,0 +1,210 @@
+package lockfile
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/CycloneDX/cyclonedx-go"
+)
+
+func parseFixture(t *testing.T, path string) *Lockfile {
+	t.Helper()
+
+	lockfileType, err := Detect(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(filepath.Join("testdata", path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lockfile, err := Parse(lockfileType, content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return lockfile
+}
+
+func TestParseFixtures(t *testing.T) {
+	tests := []struct {
+		fixture  string
+		rootName string
+		// dependencies keyed by purl of every locked package
+		packages map[string][]string
+		dev      []string
+		// nil when lockfile does not describe direct dependencies
+		direct []string
+		// purl of package with integrity hash
+		hashed string
+	}{
+		{
+			fixture:  "npm-v1/package-lock.json",
+			rootName: "app",
+			packages: map[string][]string{
+				"pkg:npm/debug@2.6.9":    {"pkg:npm/ms@2.0.0"},
+				"pkg:npm/express@4.18.2": {"pkg:npm/debug@2.6.9"},
+				"pkg:npm/jest@29.7.0":    {},
+				"pkg:npm/ms@2.0.0":       {},
+				"pkg:npm/ms@2.1.3":       {},
+			},
+			dev:    []string{"pkg:npm/jest@29.7.0"},
+			hashed: "pkg:npm/express@4.18.2",
+		},
+		{
+			fixture:  "npm-v2/package-lock.json",
+			rootName: "app",
+			packages: map[string][]string{
+				"pkg:npm/debug@2.6.9":    {"pkg:npm/ms@2.0.0"},
+				"pkg:npm/express@4.18.2": {"pkg:npm/debug@2.6.9"},
+				"pkg:npm/jest@29.7.0":    {},
+				"pkg:npm/ms@2.0.0":       {},
+				"pkg:npm/ms@2.1.3":       {},
+			},
+			dev:    []string{"pkg:npm/jest@29.7.0"},
+			direct: []string{"pkg:npm/express@4.18.2", "pkg:npm/jest@29.7.0"},
+			hashed: "pkg:npm/express@4.18.2",
+		},
+		{
+			fixture:  "npm-v3/package-lock.json",
+			rootName: "monorepo",
+			packages: map[string][]string{
+				"pkg:npm/%40babel/core@7.23.0": {"pkg:npm/semver@6.3.1"},
+				"pkg:npm/lodash@4.17.21":       {},
+				"pkg:npm/semver@6.3.1":         {},
+			},
+			direct: []string{"pkg:npm/%40babel/core@7.23.0", "pkg:npm/lodash@4.17.21"},
+		},
+		{
+			fixture: "yarn-v1/yarn.lock",
+			packages: map[string][]string{
+				"pkg:npm/%40babel/code-frame@7.22.13": {"pkg:npm/chalk@2.4.2"},
+				"pkg:npm/ansi-styles@3.2.1":           {},
+				"pkg:npm/chalk@2.4.2":                 {"pkg:npm/ansi-styles@3.2.1"},
+				"pkg:npm/string-width@4.2.3":          {},
+			},
+			hashed: "pkg:npm/%40babel/code-frame@7.22.13",
+		},
+		{
+			fixture:  "yarn-berry/yarn.lock",
+			rootName: "app",
+			packages: map[string][]string{
+				"pkg:npm/ansi-styles@3.2.1": {},
+				"pkg:npm/chalk@2.4.2":       {"pkg:npm/ansi-styles@3.2.1"},
+				"pkg:npm/strip-ansi@6.0.1":  {},
+			},
+			direct: []string{"pkg:npm/chalk@2.4.2", "pkg:npm/strip-ansi@6.0.1"},
+		},
+		{
+			fixture: "pnpm-v6/pnpm-lock.yaml",
+			packages: map[string][]string{
+				"pkg:npm/debug@2.6.9":      {"pkg:npm/ms@2.0.0"},
+				"pkg:npm/express@4.18.2":   {"pkg:npm/debug@2.6.9"},
+				"pkg:npm/ms@2.0.0":         {},
+				"pkg:npm/typescript@5.2.2": {},
+			},
+			dev:    []string{"pkg:npm/typescript@5.2.2"},
+			direct: []string{"pkg:npm/express@4.18.2", "pkg:npm/typescript@5.2.2"},
+			hashed: "pkg:npm/express@4.18.2",
+		},
+		{
+			fixture: "pnpm-v9/pnpm-lock.yaml",
+			packages: map[string][]string{
+				"pkg:npm/%40types/react@18.2.0": {},
+				"pkg:npm/react-dom@18.2.0":      {"pkg:npm/react@18.2.0"},
+				"pkg:npm/react@18.2.0":          {},
+			},
+			direct: []string{"pkg:npm/%40types/react@18.2.0", "pkg:npm/react-dom@18.2.0", "pkg:npm/react@18.2.0"},
+			hashed: "pkg:npm/react@18.2.0",
+		},
+		{
+			fixture:  "golang/go.mod",
+			rootName: "github.com/acme/app",
+			packages: map[string][]string{
+				"pkg:golang/github.com/gin-gonic/gin@v1.9.1": {},
+				"pkg:golang/golang.org/x/text@v0.15.0":       {},
+			},
+			direct: []string{"pkg:golang/github.com/gin-gonic/gin@v1.9.1"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.fixture, func(t *testing.T) {
+			lockfile := parseFixture(t, test.fixture)
+
+			if lockfile.RootName != test.rootName {
+				t.Errorf("root name = %q, want %q", lockfile.RootName, test.rootName)
+			}
+			if !reflect.DeepEqual(lockfile.Direct, test.direct) {
+				t.Errorf("direct = %v, want %v", lockfile.Direct, test.direct)
+			}
+
+			packages := map[string][]string{}
+			dev := []string{}
+			for _, pkg := range lockfile.Packages {
+				packages[pkg.Purl] = pkg.Dependencies
+				if pkg.Dev {
+					dev = append(dev, pkg.Purl)
+				}
+				if pkg.Purl == test.hashed && len(pkg.Hashes) != 1 {
+					t.Errorf("hashes of %s = %v, want integrity hash", pkg.Purl, pkg.Hashes)
+				}
+			}
+			if !reflect.DeepEqual(packages, test.packages) {
+				t.Errorf("packages = %v, want %v", packages, test.packages)
+			}
+			if test.dev == nil {
+				test.dev = []string{}
+			}
+			if !reflect.DeepEqual(dev, test.dev) {
+				t.Errorf("dev packages = %v, want %v", dev, test.dev)
+			}
+		})
+	}
+}
+
+func TestToBomMergesGoSum(t *testing.T) {
+	bom := ToBom([]*Lockfile{parseFixture(t, "golang/go.mod"), parseFixture(t, "golang/go.sum")}, "github.com/acme/app", "")
+
+	components := []string{}
+	for _, component := range *bom.Components {
+		components = append(components, component.PackageURL)
+	}
+	// modules required by go.mod take precedence over versions listed in go.sum, go.mod only
+	// checksums are not locked packages
+	want := []string{
+		"pkg:golang/github.com/gin-gonic/gin@v1.9.1",
+		"pkg:golang/golang.org/x/text@v0.15.0",
+		"pkg:golang/github.com/go-playground/validator/v10@v10.15.0",
+	}
+	if !reflect.DeepEqual(components, want) {
+		t.Errorf("components = %v, want %v", components, want)
+	}
+
+	for _, dependency := range *bom.Dependencies {
+		if dependency.Ref == bom.Metadata.Component.BOMRef {
+			if want := []string{"pkg:golang/github.com/gin-gonic/gin@v1.9.1"}; !reflect.DeepEqual(*dependency.Dependencies, want) {
+				t.Errorf("root dependencies = %v, want %v", *dependency.Dependencies, want)
+			}
+		}
+	}
+}
+
+func TestToBomMarksUnpinnedPackages(t *testing.T) {
+	lockfile, err := Parse(Requirements, []byte("requests>=2\nflask==3.0.0\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bom := ToBom([]*Lockfile{lockfile}, "app", "")
+
+	unpinned := map[string]bool{}
+	for _, component := range *bom.Components {
+		unpinned[component.Name] = component.Properties != nil &&
+			reflect.DeepEqual(*component.Properties, []cyclonedx.Property{{Name: "defect-detect:unpinned", Value: "true"}})
+	}
+	if want := map[string]bool{"requests": true, "flask": false}; !reflect.DeepEqual(unpinned, want) {
+		t.Errorf("unpinned = %v, want %v", unpinned, want)
+	}
+}
@@ -0,0 +1,582 @@
+package lockfile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"gopkg.in/yaml.v3"
+)
+
+var integrityAlgorithms = map[string]cyclonedx.HashAlgorithm{
+	"sha1":   cyclonedx.HashAlgoSHA1,
+	"sha256": cyclonedx.HashAlgoSHA256,
+	"sha384": cyclonedx.HashAlgoSHA384,
+	"sha512": cyclonedx.HashAlgoSHA512,
+}
+
+func npmPurl(name, version string) string {
+	if strings.HasPrefix(name, "@") {
+		if scope, pkgName, ok := strings.Cut(name, "/"); ok {
+			return newPurl("npm", scope, pkgName, version, nil)
+		}
+	}
+
+	return newPurl("npm", "", name, version, nil)
+}
+
+// returns hashes of subresource integrity string such as sha512-<base64>
+func integrityHashes(integrity string) []cyclonedx.Hash {
+	hashes := []cyclonedx.Hash{}
+	for _, value := range strings.Fields(integrity) {
+		algorithm, digest, ok := strings.Cut(value, "-")
+		if !ok || integrityAlgorithms[algorithm] == "" {
+			continue
+		}
+
+		decoded, err := base64.StdEncoding.DecodeString(digest)
+		if err != nil {
+			continue
+		}
+		hashes = append(hashes, newHashes(integrityAlgorithms[algorithm], hex.EncodeToString(decoded))...)
+	}
+
+	return hashes
+}
+
+// splits npm dependency specifier such as @scope/name@^1.0.0 into name and range
+func splitNpmSpecifier(specifier string) (string, string) {
+	if i := strings.Index(specifier[min(1, len(specifier)):], "@"); i >= 0 {
+		return specifier[:i+1], specifier[i+2:]
+	}
+
+	return specifier, ""
+}
+
+// returns package name of aliased range such as npm:string-width@^4.2.0
+func npmAliasName(name, versionRange string) string {
+	if alias, ok := strings.CutPrefix(versionRange, "npm:"); ok && strings.LastIndex(alias, "@") > 0 {
+		aliasName, _ := splitNpmSpecifier(alias)
+		return aliasName
+	}
+
+	return name
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+type npmLockPackage struct {
+	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	Resolved             string            `json:"resolved"`
+	Integrity            string            `json:"integrity"`
+	Link                 bool              `json:"link"`
+	Dev                  bool              `json:"dev"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+}
+
+type npmLockDependency struct {
+	Version      string                       `json:"version"`
+	Integrity    string                       `json:"integrity"`
+	Dev          bool                         `json:"dev"`
+	Requires     map[string]string            `json:"requires"`
+	Dependencies map[string]npmLockDependency `json:"dependencies"`
+}
+
+type npmLock struct {
+	Name            string                       `json:"name"`
+	Version         string                       `json:"version"`
+	LockfileVersion int                          `json:"lockfileVersion"`
+	Packages        map[string]npmLockPackage    `json:"packages"`
+	Dependencies    map[string]npmLockDependency `json:"dependencies"`
+}
+
+// returns path of package installed for dependency of package at path, following node module resolution
+func resolveNpmPath(lockPackages map[string]npmLockPackage, from, name string) string {
+	for {
+		candidate := "node_modules/" + name
+		if from != "" {
+			candidate = from + "/node_modules/" + name
+		}
+		if _, ok := lockPackages[candidate]; ok {
+			return candidate
+		} else if from == "" {
+			return ""
+		}
+
+		if i := strings.LastIndex(from, "node_modules/"); i > 0 {
+			from = strings.TrimSuffix(from[:i], "/")
+		} else {
+			from = ""
+		}
+	}
+}
+
+func parseNpmLock(content []byte) (*Lockfile, error) {
+	var lock npmLock
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return nil, err
+	}
+
+	lockfile := &Lockfile{RootName: lock.Name, RootVersion: lock.Version}
+	result := newPackages()
+
+	// lockfile version 1 only describes dependencies tree
+	if len(lock.Packages) == 0 {
+		addNpmDependencies(result, lock.Dependencies, nil)
+		lockfile.Packages = result.result()
+		return lockfile, nil
+	}
+
+	// returns name and version of package installed at path, links are followed to linked package
+	packageAt := func(path string) (string, string) {
+		lockPackage, ok := lock.Packages[path]
+		if ok && lockPackage.Link {
+			path = lockPackage.Resolved
+			lockPackage, ok = lock.Packages[path]
+		}
+		// packages outside node_modules are workspaces of the project
+		if !ok || lockPackage.Version == "" || !strings.Contains(path, "node_modules/") {
+			return "", ""
+		}
+
+		name := lockPackage.Name
+		if name == "" {
+			name = path[strings.LastIndex(path, "node_modules/")+len("node_modules/"):]
+		}
+		return name, lockPackage.Version
+	}
+
+	dependenciesOf := func(path string, lockPackage npmLockPackage, includeDev bool) []string {
+		dependencies := []string{}
+		dependencyMaps := []map[string]string{lockPackage.Dependencies, lockPackage.OptionalDependencies, lockPackage.PeerDependencies}
+		if includeDev {
+			dependencyMaps = append(dependencyMaps, lockPackage.DevDependencies)
+		}
+
+		for _, dependencyMap := range dependencyMaps {
+			for _, name := range sortedKeys(dependencyMap) {
+				if name, version := packageAt(resolveNpmPath(lock.Packages, path, name)); version != "" {
+					dependencies = append(dependencies, npmPurl(name, version))
+				}
+			}
+		}
+		return dependencies
+	}
+
+	lockfile.Direct = []string{}
+	for _, path := range sortedKeys(lock.Packages) {
+		lockPackage := lock.Packages[path]
+		if lockPackage.Link {
+			continue
+		}
+
+		// dev dependencies of root and workspaces are direct dependencies of the project
+		if !strings.Contains(path, "node_modules/") {
+			lockfile.Direct = append(lockfile.Direct, dependenciesOf(path, lockPackage, true)...)
+			continue
+		}
+
+		name, version := packageAt(path)
+		if version == "" {
+			continue
+		}
+
+		result.add(Package{
+			Name:         name,
+			Version:      version,
+			Purl:         npmPurl(name, version),
+			Dependencies: dependenciesOf(path, lockPackage, false),
+			Dev:          lockPackage.Dev,
+			Hashes:       integrityHashes(lockPackage.Integrity),
+		})
+	}
+	lockfile.Packages = result.result()
+
+	return lockfile, nil
+}
+
+// adds dependencies tree of lockfile version 1, requirements are resolved from nested dependencies
+// and then dependencies of ancestors
+func addNpmDependencies(result *packages, dependencies map[string]npmLockDependency, scopes []map[string]npmLockDependency) {
+	scopes = append([]map[string]npmLockDependency{dependencies}, scopes...)
+
+	for _, name := range sortedKeys(dependencies) {
+		dependency := dependencies[name]
+		pkg := Package{
+			Name:    name,
+			Version: dependency.Version,
+			Purl:    npmPurl(name, dependency.Version),
+			Dev:     dependency.Dev,
+			Hashes:  integrityHashes(dependency.Integrity),
+		}
+
+		requirementScopes := scopes
+		if len(dependency.Dependencies) > 0 {
+			requirementScopes = append([]map[string]npmLockDependency{dependency.Dependencies}, scopes...)
+		}
+		for _, required := range sortedKeys(dependency.Requires) {
+			for _, scope := range requirementScopes {
+				if resolved, ok := scope[required]; ok {
+					pkg.Dependencies = append(pkg.Dependencies, npmPurl(required, resolved.Version))
+					break
+				}
+			}
+		}
+
+		result.add(pkg)
+		addNpmDependencies(result, dependency.Dependencies, scopes)
+	}
+}
+
+type yarnEntry struct {
+	specifiers   []string
+	version      string
+	integrity    string
+	dependencies map[string]string
+}
+
+func unquote(value string) string {
+	return strings.Trim(strings.TrimSpace(value), `"'`)
+}
+
+// returns purls of yarn entries keyed by their specifiers
+func yarnSpecifierPurls(entries []yarnEntry) map[string]string {
+	purls := map[string]string{}
+	for _, entry := range entries {
+		for _, specifier := range entry.specifiers {
+			name, versionRange := splitNpmSpecifier(specifier)
+			purls[specifier] = npmPurl(npmAliasName(name, versionRange), entry.version)
+		}
+	}
+
+	return purls
+}
+
+func parseYarnLock(content []byte) (*Lockfile, error) {
+	// yarn 2+ lockfiles are yaml documents
+	if bytes.Contains(content, []byte("\n__metadata:")) || bytes.HasPrefix(content, []byte("__metadata:")) {
+		return parseYarnBerryLock(content)
+	}
+
+	entries := []yarnEntry{}
+	var (
+		entry   *yarnEntry
+		section string
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		switch indent := len(line) - len(strings.TrimLeft(line, " ")); {
+		case indent == 0:
+			entries = append(entries, yarnEntry{dependencies: map[string]string{}})
+			entry = &entries[len(entries)-1]
+			for _, specifier := range strings.Split(strings.TrimSuffix(trimmed, ":"), ",") {
+				if specifier = unquote(specifier); specifier != "" {
+					entry.specifiers = append(entry.specifiers, specifier)
+				}
+			}
+		case entry == nil:
+			return nil, errors.New("dependency field outside of entry")
+		case indent <= 2:
+			key, value, _ := strings.Cut(trimmed, " ")
+			section = ""
+			switch {
+			case strings.HasSuffix(trimmed, ":"):
+				section = strings.TrimSuffix(trimmed, ":")
+			case key == "version":
+				entry.version = unquote(value)
+			case key == "integrity":
+				entry.integrity = unquote(value)
+			}
+		case section == "dependencies" || section == "optionalDependencies":
+			name, versionRange, _ := strings.Cut(trimmed, " ")
+			entry.dependencies[unquote(name)] = unquote(versionRange)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	purls := yarnSpecifierPurls(entries)
+	result := newPackages()
+	for _, entry := range entries {
+		if len(entry.specifiers) == 0 || entry.version == "" {
+			continue
+		}
+
+		name, versionRange := splitNpmSpecifier(entry.specifiers[0])
+		pkg := Package{
+			Name:    npmAliasName(name, versionRange),
+			Version: entry.version,
+			Purl:    purls[entry.specifiers[0]],
+			Hashes:  integrityHashes(entry.integrity),
+		}
+		for _, dependency := range sortedKeys(entry.dependencies) {
+			if purl, ok := purls[dependency+"@"+entry.dependencies[dependency]]; ok {
+				pkg.Dependencies = append(pkg.Dependencies, purl)
+			}
+		}
+		result.add(pkg)
+	}
+
+	return &Lockfile{Packages: result.result()}, nil
+}
+
+type yarnBerryEntry struct {
+	Version              string            `yaml:"version"`
+	Resolution           string            `yaml:"resolution"`
+	Dependencies         map[string]string `yaml:"dependencies"`
+	OptionalDependencies map[string]string `yaml:"optionalDependencies"`
+}
+
+func parseYarnBerryLock(content []byte) (*Lockfile, error) {
+	var lock map[string]yaml.Node
+	if err := yaml.Unmarshal(content, &lock); err != nil {
+		return nil, err
+	}
+
+	lockEntries := map[string]yarnBerryEntry{}
+	entries := []yarnEntry{}
+	for _, key := range sortedKeys(lock) {
+		if key == "__metadata" {
+			continue
+		}
+
+		node := lock[key]
+		var lockEntry yarnBerryEntry
+		if err := node.Decode(&lockEntry); err != nil {
+			return nil, err
+		}
+		lockEntries[key] = lockEntry
+
+		entry := yarnEntry{version: lockEntry.Version}
+		for _, specifier := range strings.Split(key, ",") {
+			entry.specifiers = append(entry.specifiers, strings.TrimSpace(specifier))
+		}
+		entries = append(entries, entry)
+	}
+
+	purls := yarnSpecifierPurls(entries)
+	// purls of berry entries are named after resolution, which is the real name of aliased packages
+	for key, lockEntry := range lockEntries {
+		if name, _ := splitNpmSpecifier(lockEntry.Resolution); name != "" && !strings.Contains(lockEntry.Resolution, "@workspace:") {
+			for _, specifier := range strings.Split(key, ",") {
+				purls[strings.TrimSpace(specifier)] = npmPurl(name, lockEntry.Version)
+			}
+		}
+	}
+
+	dependencyPurl := func(name, versionRange string) string {
+		if purl, ok := purls[name+"@"+versionRange]; ok {
+			return purl
+		}
+		return purls[name+"@npm:"+versionRange]
+	}
+
+	lockfile := &Lockfile{Direct: []string{}}
+	result := newPackages()
+	for _, key := range sortedKeys(lockEntries) {
+		lockEntry := lockEntries[key]
+		dependencies := []string{}
+		for _, dependencyMap := range []map[string]string{lockEntry.Dependencies, lockEntry.OptionalDependencies} {
+			for _, name := range sortedKeys(dependencyMap) {
+				if purl := dependencyPurl(name, dependencyMap[name]); purl != "" {
+					dependencies = append(dependencies, purl)
+				}
+			}
+		}
+
+		// dependencies of workspaces are direct dependencies of the project
+		if name, reference := splitNpmSpecifier(lockEntry.Resolution); strings.HasPrefix(reference, "workspace:") {
+			if reference == "workspace:." {
+				lockfile.RootName = name
+			}
+			lockfile.Direct = append(lockfile.Direct, dependencies...)
+			continue
+		}
+
+		specifier := strings.TrimSpace(strings.Split(key, ",")[0])
+		name, _ := splitNpmSpecifier(lockEntry.Resolution)
+		result.add(Package{
+			Name:         name,
+			Version:      lockEntry.Version,
+			Purl:         purls[specifier],
+			Dependencies: dependencies,
+		})
+	}
+	lockfile.Packages = result.result()
+
+	// lockfiles without workspace entries do not describe direct dependencies
+	if len(lockfile.Direct) == 0 {
+		lockfile.Direct = nil
+	}
+
+	return lockfile, nil
+}
+
+// pnpmDependency is version of importer dependency, which is a plain version in lockfile version 5
+type pnpmDependency struct {
+	Version string
+}
+
+func (d *pnpmDependency) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		d.Version = value.Value
+		return nil
+	}
+
+	var dependency struct {
+		Version string `yaml:"version"`
+	}
+	if err := value.Decode(&dependency); err != nil {
+		return err
+	}
+	d.Version = dependency.Version
+
+	return nil
+}
+
+type pnpmImporter struct {
+	Dependencies         map[string]pnpmDependency `yaml:"dependencies"`
+	DevDependencies      map[string]pnpmDependency `yaml:"devDependencies"`
+	OptionalDependencies map[string]pnpmDependency `yaml:"optionalDependencies"`
+}
+
+type pnpmPackage struct {
+	Name       string `yaml:"name"`
+	Version    string `yaml:"version"`
+	Dev        bool   `yaml:"dev"`
+	Resolution struct {
+		Integrity string `yaml:"integrity"`
+	} `yaml:"resolution"`
+	Dependencies         map[string]string `yaml:"dependencies"`
+	OptionalDependencies map[string]string `yaml:"optionalDependencies"`
+}
+
+type pnpmLock struct {
+	Importers    map[string]pnpmImporter `yaml:"importers"`
+	pnpmImporter `yaml:",inline"`
+	Packages     map[string]pnpmPackage `yaml:"packages"`
+	Snapshots    map[string]pnpmPackage `yaml:"snapshots"`
+}
+
+// splits pnpm package key into name and version. Keys are /name/version in lockfile version 5,
+// /name@version in version 6 and name@version in version 9, peer dependencies are suffixed.
+func parsePnpmKey(key string) (string, string) {
+	key = strings.TrimPrefix(key, "/")
+	key, _, _ = strings.Cut(key, "(")
+
+	if at := strings.LastIndex(key, "@"); at > 0 {
+		return key[:at], key[at+1:]
+	}
+
+	slash := strings.LastIndex(key, "/")
+	if slash < 0 {
+		return key, ""
+	}
+	version, _, _ := strings.Cut(key[slash+1:], "_")
+
+	return key[:slash], version
+}
+
+// returns purl of dependency locked at version, versions may be package keys of aliased packages
+func pnpmDependencyPurl(name, version string) string {
+	if strings.HasPrefix(version, "link:") || strings.HasPrefix(version, "file:") || version == "" {
+		return ""
+	}
+
+	version, _, _ = strings.Cut(version, "(")
+	if strings.HasPrefix(version, "/") || strings.Contains(version, "@") {
+		name, version = parsePnpmKey(version)
+	} else {
+		version, _, _ = strings.Cut(version, "_")
+	}
+
+	return npmPurl(name, version)
+}
+
+func parsePnpmLock(content []byte) (*Lockfile, error) {
+	var lock pnpmLock
+	if err := yaml.Unmarshal(content, &lock); err != nil {
+		return nil, err
+	}
+
+	importers := lock.Importers
+	if len(importers) == 0 {
+		importers = map[string]pnpmImporter{".": lock.pnpmImporter}
+	}
+
+	lockfile := &Lockfile{Direct: []string{}}
+	for _, path := range sortedKeys(importers) {
+		importer := importers[path]
+		for _, dependencyMap := range []map[string]pnpmDependency{importer.Dependencies, importer.DevDependencies, importer.OptionalDependencies} {
+			for _, name := range sortedKeys(dependencyMap) {
+				if purl := pnpmDependencyPurl(name, dependencyMap[name].Version); purl != "" {
+					lockfile.Direct = append(lockfile.Direct, purl)
+				}
+			}
+		}
+	}
+
+	result := newPackages()
+	addPackages := func(lockPackages map[string]pnpmPackage) {
+		for _, key := range sortedKeys(lockPackages) {
+			lockPackage := lockPackages[key]
+			name, version := parsePnpmKey(key)
+			if lockPackage.Name != "" {
+				name, version = lockPackage.Name, lockPackage.Version
+			}
+			if version == "" {
+				continue
+			}
+
+			pkg := Package{
+				Name:    name,
+				Version: version,
+				Purl:    npmPurl(name, version),
+				Dev:     lockPackage.Dev,
+				Hashes:  integrityHashes(lockPackage.Resolution.Integrity),
+			}
+			for _, dependencyMap := range []map[string]string{lockPackage.Dependencies, lockPackage.OptionalDependencies} {
+				for _, dependency := range sortedKeys(dependencyMap) {
+					if purl := pnpmDependencyPurl(dependency, dependencyMap[dependency]); purl != "" {
+						pkg.Dependencies = append(pkg.Dependencies, purl)
+					}
+				}
+			}
+			result.add(pkg)
+		}
+	}
+
+	// lockfile version 9 describes dependencies of packages in snapshots
+	addPackages(lock.Packages)
+	addPackages(lock.Snapshots)
+	lockfile.Packages = result.result()
+
+	return lockfile, nil
+}
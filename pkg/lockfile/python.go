@@ -0,0 +1,140 @@
+package lockfile
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+var (
+	pypiNameSeparatorRegex = regexp.MustCompile(`[-_.]+`)
+	requirementRegex       = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(\[[^\]]*\])?\s*(===?|~=|!=|<=|>=|<|>)?\s*([^\s,;]*)`)
+)
+
+// returns name normalized as described by PEP 503
+func normalizePypiName(name string) string {
+	return strings.ToLower(pypiNameSeparatorRegex.ReplaceAllString(name, "-"))
+}
+
+func pypiPurl(name, version string) string {
+	return newPurl("pypi", "", normalizePypiName(name), version, nil)
+}
+
+// parses pip requirements file, only versions pinned using == are known. Requirements are
+// considered direct dependencies as requirements files have no dependency graph.
+func parseRequirements(content []byte) (*Lockfile, error) {
+	result := newPackages()
+	lockfile := &Lockfile{Direct: []string{}}
+
+	// joins lines continued using backslash
+	text := strings.ReplaceAll(strings.ReplaceAll(string(content), "\r\n", "\n"), "\\\n", " ")
+	for _, line := range strings.Split(text, "\n") {
+		line, _, _ = strings.Cut(line, " #")
+		line = strings.TrimSpace(line)
+		// options, includes and urls are skipped
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") || strings.Contains(line, "://") {
+			continue
+		}
+
+		match := requirementRegex.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		version := ""
+		if match[3] == "==" || match[3] == "===" {
+			version = match[4]
+		}
+		// wildcard pins such as ==1.2.* do not select a version
+		if strings.Contains(version, "*") {
+			version = ""
+		}
+
+		purl := result.add(Package{Name: normalizePypiName(match[1]), Version: version, Purl: pypiPurl(match[1], version)})
+		lockfile.Direct = append(lockfile.Direct, purl)
+	}
+	lockfile.Packages = result.result()
+
+	return lockfile, nil
+}
+
+type poetryPackage struct {
+	Name         string                 `toml:"name"`
+	Version      string                 `toml:"version"`
+	Category     string                 `toml:"category"`
+	Dependencies map[string]interface{} `toml:"dependencies"`
+}
+
+type poetryLock struct {
+	Packages []poetryPackage `toml:"package"`
+}
+
+// parses poetry.lock, lockfile does not describe direct dependencies of the project
+func parsePoetryLock(content []byte) (*Lockfile, error) {
+	var lock poetryLock
+	if err := toml.Unmarshal(content, &lock); err != nil {
+		return nil, err
+	}
+
+	purls := map[string]string{}
+	for _, lockPackage := range lock.Packages {
+		name := normalizePypiName(lockPackage.Name)
+		if _, ok := purls[name]; !ok {
+			purls[name] = pypiPurl(name, lockPackage.Version)
+		}
+	}
+
+	result := newPackages()
+	for _, lockPackage := range lock.Packages {
+		pkg := Package{
+			Name:    normalizePypiName(lockPackage.Name),
+			Version: lockPackage.Version,
+			Purl:    pypiPurl(lockPackage.Name, lockPackage.Version),
+			// lockfiles of poetry 1.5 and newer do not categorize packages
+			Dev: lockPackage.Category == "dev",
+		}
+		for _, dependency := range sortedKeys(lockPackage.Dependencies) {
+			if purl, ok := purls[normalizePypiName(dependency)]; ok {
+				pkg.Dependencies = append(pkg.Dependencies, purl)
+			}
+		}
+		result.add(pkg)
+	}
+
+	return &Lockfile{Packages: result.result()}, nil
+}
+
+type pipfilePackage struct {
+	Version string `json:"version"`
+}
+
+type pipfileLock struct {
+	Default map[string]pipfilePackage `json:"default"`
+	Develop map[string]pipfilePackage `json:"develop"`
+}
+
+// parses Pipfile.lock, every locked package is considered direct as lockfile has no dependency graph
+func parsePipfileLock(content []byte) (*Lockfile, error) {
+	var lock pipfileLock
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return nil, err
+	}
+
+	result := newPackages()
+	lockfile := &Lockfile{Direct: []string{}}
+	for _, section := range []struct {
+		packages map[string]pipfilePackage
+		dev      bool
+	}{{lock.Default, false}, {lock.Develop, true}} {
+		for _, name := range sortedKeys(section.packages) {
+			version := strings.TrimPrefix(section.packages[name].Version, "==")
+			purl := result.add(Package{Name: normalizePypiName(name), Version: version, Purl: pypiPurl(name, version), Dev: section.dev})
+			lockfile.Direct = append(lockfile.Direct, purl)
+		}
+	}
+	lockfile.Packages = result.result()
+
+	return lockfile, nil
+}
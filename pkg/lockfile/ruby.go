@@ -0,0 +1,99 @@
+package lockfile
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+)
+
+type gemSpec struct {
+	name         string
+	version      string
+	platform     string
+	purl         string
+	dependencies []string
+}
+
+// splits Gemfile.lock entry such as nokogiri (1.13.10-x86_64-linux) into name and version
+func splitGemEntry(entry string) (string, string) {
+	name, version, _ := strings.Cut(strings.TrimSpace(entry), " ")
+	name = strings.TrimSuffix(name, "!")
+	version = strings.Trim(version, "()")
+
+	return name, version
+}
+
+// parses Gemfile.lock, gems listed in DEPENDENCIES section are direct dependencies
+func parseGemfileLock(content []byte) (*Lockfile, error) {
+	specs := []gemSpec{}
+	directNames := []string{}
+	section := ""
+	inSpecs := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		switch {
+		case indent == 0:
+			section, inSpecs = strings.TrimSpace(line), false
+		case section == "DEPENDENCIES" && indent == 2:
+			name, _ := splitGemEntry(line)
+			directNames = append(directNames, name)
+		case section != "GEM" && section != "GIT" && section != "PATH":
+			continue
+		case indent == 2:
+			inSpecs = strings.TrimSpace(line) == "specs:"
+		case inSpecs && indent == 4:
+			name, version := splitGemEntry(line)
+			spec := gemSpec{name: name, version: version}
+			// platform specific gems are suffixed with their platform
+			if i := strings.Index(version, "-"); i > 0 {
+				spec.version, spec.platform = version[:i], version[i+1:]
+			}
+			specs = append(specs, spec)
+		case inSpecs && indent == 6 && len(specs) > 0:
+			name, _ := splitGemEntry(line)
+			specs[len(specs)-1].dependencies = append(specs[len(specs)-1].dependencies, name)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	purls := map[string]string{}
+	for i, spec := range specs {
+		var qualifiers map[string]string
+		if spec.platform != "" {
+			qualifiers = map[string]string{"platform": spec.platform}
+		}
+		specs[i].purl = newPurl("gem", "", spec.name, spec.version, qualifiers)
+		if _, ok := purls[spec.name]; !ok {
+			purls[spec.name] = specs[i].purl
+		}
+	}
+
+	result := newPackages()
+	for _, spec := range specs {
+		pkg := Package{Name: spec.name, Version: spec.version, Purl: spec.purl}
+		for _, dependency := range spec.dependencies {
+			if purl, ok := purls[dependency]; ok {
+				pkg.Dependencies = append(pkg.Dependencies, purl)
+			}
+		}
+		result.add(pkg)
+	}
+
+	lockfile := &Lockfile{Packages: result.result(), Direct: []string{}}
+	for _, name := range directNames {
+		if purl, ok := purls[name]; ok {
+			lockfile.Direct = append(lockfile.Direct, purl)
+		}
+	}
+
+	return lockfile, nil
+}
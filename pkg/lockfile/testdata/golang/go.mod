@@ -0,0 +1,13 @@
+module github.com/acme/app
+
+go 1.22
+
+require (
+	github.com/acme/lib v0.1.0
+	github.com/gin-gonic/gin v1.9.1
+	golang.org/x/text v0.14.0 // indirect
+)
+
+replace github.com/acme/lib => ../lib
+
+replace golang.org/x/text v0.14.0 => golang.org/x/text v0.15.0
@@ -0,0 +1,494 @@
+package sbomconvert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	spdxjson "github.com/spdx/tools-golang/json"
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/spdx/v2/common"
+)
+
+const (
+	spdxDocumentId  = "DOCUMENT"
+	spdxNoAssertion = "NOASSERTION"
+	spdxNone        = "NONE"
+	spdxDescribes   = "DESCRIBES"
+	spdxDescribedBy = "DESCRIBED_BY"
+	spdxContains    = "CONTAINS"
+	spdxContainedBy = "CONTAINED_BY"
+	spdxDependsOn   = "DEPENDS_ON"
+
+	// matches DEPENDENCY_OF and its variants such as DEV_DEPENDENCY_OF
+	spdxDependencyOfSuffix = "DEPENDENCY_OF"
+)
+
+// ConvertSpdxDocument converts spdx document to cyclonedx using ConvertSbom and reports spdx
+// data which could not be represented in converted sbom. Returned loss is nil when nothing was lost.
+func ConvertSpdxDocument(doc *spdx.Document) (*cyclonedx.BOM, *types.ConversionLoss, error) {
+	loss := &types.ConversionLoss{}
+	normalized := normalizeSpdxRelationships(doc, loss)
+
+	input := &bytes.Buffer{}
+	if err := spdxjson.Write(normalized, input); err != nil {
+		log.Error().Err(err).Msg("failed to write spdx document as json")
+		return nil, nil, err
+	}
+
+	outputStream := &types.WriteCloser{Buffer: &bytes.Buffer{}}
+	if err := ConvertSbom(&types.ReadSeekCloser{Reader: bytes.NewReader(input.Bytes())}, outputStream); err != nil {
+		log.Error().Err(err).Msg("failed to convert spdx sbom")
+		return nil, nil, err
+	}
+
+	var bom cyclonedx.BOM
+	if err := cyclonedx.NewBOMDecoder(outputStream, cyclonedx.BOMFileFormatJSON).Decode(&bom); err != nil {
+		log.Error().Err(err).Msg("failed to decode converted spdx sbom")
+		return nil, nil, err
+	}
+
+	restoreSpdxDependencies(normalized, &bom)
+
+	// spdx document namespace is not a valid serial number, same namespace always maps to same uuid
+	bom.SerialNumber = fmt.Sprintf("urn:uuid:%s", uuid.NewSHA1(uuid.NameSpaceURL, []byte(doc.DocumentNamespace)))
+	if bom.Version < 1 {
+		bom.Version = 1
+	}
+
+	reportSpdxLoss(normalized, &bom, loss)
+	if loss.IsEmpty() {
+		return &bom, nil, nil
+	}
+
+	return &bom, loss, nil
+}
+
+func renderRelationship(r *spdx.Relationship) string {
+	return fmt.Sprintf("%s %s %s", common.RenderDocElementID(r.RefA), r.Relationship, common.RenderDocElementID(r.RefB))
+}
+
+// returns all packages and files of the document indexed by their spdx id
+func getSpdxElements(doc *spdx.Document) map[common.ElementID]bool {
+	elements := map[common.ElementID]bool{}
+	for _, pkg := range doc.Packages {
+		elements[pkg.PackageSPDXIdentifier] = true
+		for _, file := range pkg.Files {
+			elements[file.FileSPDXIdentifier] = true
+		}
+	}
+	for _, file := range doc.Files {
+		elements[file.FileSPDXIdentifier] = true
+	}
+
+	return elements
+}
+
+// returns element described by the relationship or empty id when relationship does not describe a local element
+func getDescribedElement(r *spdx.Relationship) common.ElementID {
+	switch {
+	case r.Relationship == spdxDescribes && r.RefA.ElementRefID == spdxDocumentId && r.RefA.DocumentRefID == "":
+		return r.RefB.ElementRefID
+	case r.Relationship == spdxDescribedBy && r.RefB.ElementRefID == spdxDocumentId && r.RefB.DocumentRefID == "":
+		return r.RefA.ElementRefID
+	}
+
+	return ""
+}
+
+// cyclonedx sbom can only have a single root and relationships between local elements.
+// Returns copy of the document with relationships which can be converted, others are reported as unmapped.
+func normalizeSpdxRelationships(doc *spdx.Document, loss *types.ConversionLoss) *spdx.Document {
+	elements := getSpdxElements(doc)
+	packages := map[common.ElementID]bool{}
+	for _, pkg := range doc.Packages {
+		packages[pkg.PackageSPDXIdentifier] = true
+	}
+
+	// prefer described package over described file as root of converted sbom
+	var root common.ElementID
+	related := map[common.ElementID]bool{}
+	for _, r := range doc.Relationships {
+		described := getDescribedElement(r)
+		if described == "" {
+			related[r.RefB.ElementRefID] = true
+			continue
+		}
+
+		if !elements[described] {
+			continue
+		}
+
+		if root == "" || (!packages[root] && packages[described]) {
+			root = described
+		}
+	}
+
+	// documents without described element use first package which is not related from other elements
+	if root == "" {
+		for _, pkg := range doc.Packages {
+			if !related[pkg.PackageSPDXIdentifier] {
+				root = pkg.PackageSPDXIdentifier
+				break
+			}
+		}
+	}
+	if root == "" && len(doc.Packages) > 0 {
+		root = doc.Packages[0].PackageSPDXIdentifier
+	}
+
+	relationships := []*spdx.Relationship{}
+	if root != "" {
+		relationships = append(relationships, &spdx.Relationship{
+			RefA:         common.MakeDocElementID("", spdxDocumentId),
+			RefB:         common.MakeDocElementID("", string(root)),
+			Relationship: spdxDescribes,
+		})
+	}
+
+	for _, r := range doc.Relationships {
+		described := getDescribedElement(r)
+		switch {
+		case described != "" && described == root:
+			// already added
+		case r.RefA.DocumentRefID != "" || r.RefB.DocumentRefID != "" || r.RefA.SpecialID != "" || r.RefB.SpecialID != "",
+			!elements[r.RefA.ElementRefID] || !elements[r.RefB.ElementRefID]:
+			loss.UnmappedRelationships = append(loss.UnmappedRelationships, renderRelationship(r))
+		default:
+			relationships = append(relationships, r)
+		}
+	}
+
+	// package files are written nested in packages which is ignored during conversion,
+	// move them to document level and link them to their package instead
+	normalized := *doc
+	normalized.Packages = []*spdx.Package{}
+	normalized.Files = append([]*spdx.File{}, doc.Files...)
+	seenFiles := map[common.ElementID]bool{}
+	for _, file := range doc.Files {
+		seenFiles[file.FileSPDXIdentifier] = true
+	}
+
+	renderedRelationships := map[string]bool{}
+	for _, r := range relationships {
+		renderedRelationships[renderRelationship(r)] = true
+	}
+
+	for _, pkg := range doc.Packages {
+		flat := *pkg
+		flat.Files = nil
+		normalized.Packages = append(normalized.Packages, &flat)
+
+		for _, file := range pkg.Files {
+			if !seenFiles[file.FileSPDXIdentifier] {
+				seenFiles[file.FileSPDXIdentifier] = true
+				normalized.Files = append(normalized.Files, file)
+			}
+
+			contains := &spdx.Relationship{
+				RefA:         common.MakeDocElementID("", string(pkg.PackageSPDXIdentifier)),
+				RefB:         common.MakeDocElementID("", string(file.FileSPDXIdentifier)),
+				Relationship: spdxContains,
+			}
+			if !renderedRelationships[renderRelationship(contains)] {
+				renderedRelationships[renderRelationship(contains)] = true
+				relationships = append(relationships, contains)
+			}
+		}
+	}
+	normalized.Relationships = relationships
+
+	return &normalized
+}
+
+// returns dependency described by the relationship as dependent and dependency element ids
+func getSpdxDependency(r *spdx.Relationship) (string, string, bool) {
+	switch {
+	case r.Relationship == spdxDependsOn:
+		return string(r.RefA.ElementRefID), string(r.RefB.ElementRefID), true
+	case strings.HasSuffix(r.Relationship, spdxDependencyOfSuffix):
+		return string(r.RefB.ElementRefID), string(r.RefA.ElementRefID), true
+	}
+
+	return "", "", false
+}
+
+// conversion only keeps dependencies of elements which are not already part of the sbom tree,
+// add dependencies between converted components from spdx relationships
+func restoreSpdxDependencies(doc *spdx.Document, bom *cyclonedx.BOM) {
+	components := map[string]*cyclonedx.Component{}
+	if bom.Metadata != nil && bom.Metadata.Component != nil {
+		components[bom.Metadata.Component.BOMRef] = bom.Metadata.Component
+		indexCdxComponents(bom.Metadata.Component.Components, "", components, map[string]string{})
+	}
+	indexCdxComponents(bom.Components, "", components, map[string]string{})
+
+	dependencies := []cyclonedx.Dependency{}
+	if bom.Dependencies != nil {
+		dependencies = *bom.Dependencies
+	}
+
+	indexes := map[string]int{}
+	existing := map[string]map[string]bool{}
+	for i, dependency := range dependencies {
+		indexes[dependency.Ref] = i
+		existing[dependency.Ref] = map[string]bool{}
+		if dependency.Dependencies != nil {
+			for _, ref := range *dependency.Dependencies {
+				existing[dependency.Ref][ref] = true
+			}
+		}
+	}
+
+	for _, r := range doc.Relationships {
+		dependent, dependency, ok := getSpdxDependency(r)
+		if !ok || components[dependent] == nil || components[dependency] == nil || existing[dependent][dependency] {
+			continue
+		}
+
+		if _, exists := indexes[dependent]; !exists {
+			indexes[dependent] = len(dependencies)
+			existing[dependent] = map[string]bool{}
+			dependencies = append(dependencies, cyclonedx.Dependency{Ref: dependent, Dependencies: &[]string{}})
+		}
+
+		dependencyRefs := dependencies[indexes[dependent]].Dependencies
+		if dependencyRefs == nil {
+			dependencyRefs = &[]string{}
+			dependencies[indexes[dependent]].Dependencies = dependencyRefs
+		}
+		*dependencyRefs = append(*dependencyRefs, dependency)
+		existing[dependent][dependency] = true
+	}
+
+	if len(dependencies) > 0 {
+		bom.Dependencies = &dependencies
+	}
+}
+
+// returns json encoded value used to check whether value is present in encoded cyclonedx data
+func encodeValue(value string) string {
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return value
+	}
+
+	return strings.TrimSuffix(strings.TrimSuffix(buf.String(), "\n"), `"`)[1:]
+}
+
+func encodeCdx(v interface{}) string {
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return ""
+	}
+
+	return buf.String()
+}
+
+type lossTracker struct {
+	dropped map[string]int
+}
+
+// counts values of field which are not present in encoded cyclonedx data
+func (t *lossTracker) check(encoded, field string, values ...string) {
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" || value == spdxNoAssertion || value == spdxNone {
+			continue
+		}
+
+		if !strings.Contains(encoded, encodeValue(value)) {
+			t.dropped[field]++
+		}
+	}
+}
+
+func (t *lossTracker) fields() []types.DroppedField {
+	fields := []types.DroppedField{}
+	for field, count := range t.dropped {
+		fields = append(fields, types.DroppedField{Field: field, Count: count})
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+
+	return fields
+}
+
+// indexes cyclonedx components by bom-ref along with their parent bom-ref
+func indexCdxComponents(components *[]cyclonedx.Component, parentRef string, index map[string]*cyclonedx.Component, parents map[string]string) {
+	if components == nil {
+		return
+	}
+
+	for i := range *components {
+		component := &(*components)[i]
+		index[component.BOMRef] = component
+		if parentRef != "" {
+			parents[component.BOMRef] = parentRef
+		}
+		indexCdxComponents(component.Components, component.BOMRef, index, parents)
+	}
+}
+
+func reportSpdxLoss(doc *spdx.Document, bom *cyclonedx.BOM, loss *types.ConversionLoss) {
+	components := map[string]*cyclonedx.Component{}
+	parents := map[string]string{}
+	rootRef := ""
+	if bom.Metadata != nil && bom.Metadata.Component != nil {
+		rootRef = bom.Metadata.Component.BOMRef
+		components[rootRef] = bom.Metadata.Component
+		indexCdxComponents(bom.Metadata.Component.Components, rootRef, components, parents)
+	}
+	// top level components are contained by the sbom root component
+	indexCdxComponents(bom.Components, rootRef, components, parents)
+
+	dependencies := map[string]map[string]bool{}
+	if bom.Dependencies != nil {
+		for _, dependency := range *bom.Dependencies {
+			dependencies[dependency.Ref] = map[string]bool{}
+			if dependency.Dependencies != nil {
+				for _, ref := range *dependency.Dependencies {
+					dependencies[dependency.Ref][ref] = true
+				}
+			}
+		}
+	}
+
+	// returns component encoded without nested components, so only its own values are checked
+	encodeComponent := func(id common.ElementID) (string, bool) {
+		component, exists := components[string(id)]
+		if !exists {
+			loss.DroppedElements = append(loss.DroppedElements, common.RenderElementID(id))
+			return "", false
+		}
+
+		flat := *component
+		flat.Components = nil
+		return encodeCdx(flat), true
+	}
+
+	tracker := &lossTracker{dropped: map[string]int{}}
+	annotations := []spdx.Annotation{}
+	for _, annotation := range doc.Annotations {
+		annotations = append(annotations, *annotation)
+	}
+
+	for _, pkg := range doc.Packages {
+		annotations = append(annotations, pkg.Annotations...)
+
+		encoded, exists := encodeComponent(pkg.PackageSPDXIdentifier)
+		if !exists {
+			continue
+		}
+
+		if pkg.PackageSupplier != nil {
+			tracker.check(encoded, "packages.supplier", pkg.PackageSupplier.Supplier)
+		}
+		if pkg.PackageOriginator != nil {
+			tracker.check(encoded, "packages.originator", pkg.PackageOriginator.Originator)
+		}
+		if pkg.PackageVerificationCode != nil {
+			tracker.check(encoded, "packages.verification_code", pkg.PackageVerificationCode.Value)
+		}
+		for _, checksum := range pkg.PackageChecksums {
+			tracker.check(encoded, "packages.checksums", checksum.Value)
+		}
+		for _, ref := range pkg.PackageExternalReferences {
+			tracker.check(encoded, "packages.external_refs", ref.Locator)
+		}
+		tracker.check(encoded, "packages.version", pkg.PackageVersion)
+		tracker.check(encoded, "packages.file_name", pkg.PackageFileName)
+		tracker.check(encoded, "packages.download_location", pkg.PackageDownloadLocation)
+		tracker.check(encoded, "packages.homepage", pkg.PackageHomePage)
+		tracker.check(encoded, "packages.source_info", pkg.PackageSourceInfo)
+		tracker.check(encoded, "packages.license_concluded", pkg.PackageLicenseConcluded)
+		tracker.check(encoded, "packages.license_declared", pkg.PackageLicenseDeclared)
+		tracker.check(encoded, "packages.license_comments", pkg.PackageLicenseComments)
+		tracker.check(encoded, "packages.copyright", pkg.PackageCopyrightText)
+		tracker.check(encoded, "packages.summary", pkg.PackageSummary)
+		tracker.check(encoded, "packages.description", pkg.PackageDescription)
+		tracker.check(encoded, "packages.comment", pkg.PackageComment)
+		tracker.check(encoded, "packages.attribution_texts", pkg.PackageAttributionTexts...)
+		tracker.check(encoded, "packages.release_date", pkg.ReleaseDate)
+		tracker.check(encoded, "packages.built_date", pkg.BuiltDate)
+		tracker.check(encoded, "packages.valid_until_date", pkg.ValidUntilDate)
+	}
+
+	snippets := append([]spdx.Snippet{}, doc.Snippets...)
+	for _, file := range doc.Files {
+		annotations = append(annotations, file.Annotations...)
+		for _, snippet := range file.Snippets {
+			snippets = append(snippets, *snippet)
+		}
+
+		encoded, exists := encodeComponent(file.FileSPDXIdentifier)
+		if !exists {
+			continue
+		}
+
+		for _, checksum := range file.Checksums {
+			tracker.check(encoded, "files.checksums", checksum.Value)
+		}
+		tracker.check(encoded, "files.license_concluded", file.LicenseConcluded)
+		tracker.check(encoded, "files.license_comments", file.LicenseComments)
+		tracker.check(encoded, "files.copyright", file.FileCopyrightText)
+		tracker.check(encoded, "files.comment", file.FileComment)
+		tracker.check(encoded, "files.notice", file.FileNotice)
+		tracker.check(encoded, "files.contributors", file.FileContributors...)
+		tracker.check(encoded, "files.attribution_texts", file.FileAttributionTexts...)
+	}
+
+	// document level data can be mapped anywhere in converted sbom
+	encodedBom := encodeCdx(bom)
+	tracker.check(encodedBom, "document.comment", doc.DocumentComment)
+	if doc.CreationInfo != nil {
+		tracker.check(encodedBom, "document.creator_comment", doc.CreationInfo.CreatorComment)
+	}
+	for _, ref := range doc.ExternalDocumentReferences {
+		tracker.check(encodedBom, "document.external_document_refs", ref.URI)
+	}
+	for _, license := range doc.OtherLicenses {
+		tracker.check(encodedBom, "document.other_licenses", license.ExtractedText)
+	}
+	for _, annotation := range annotations {
+		tracker.check(encodedBom, "annotations", annotation.AnnotationComment)
+	}
+	// snippets may be listed at document level and in their file
+	seenSnippets := map[common.ElementID]bool{}
+	for _, snippet := range snippets {
+		if seenSnippets[snippet.SnippetSPDXIdentifier] {
+			continue
+		}
+		seenSnippets[snippet.SnippetSPDXIdentifier] = true
+
+		if _, exists := components[string(snippet.SnippetSPDXIdentifier)]; !exists {
+			loss.DroppedElements = append(loss.DroppedElements, common.RenderElementID(snippet.SnippetSPDXIdentifier))
+		}
+	}
+	loss.DroppedFields = tracker.fields()
+
+	// relationships can only be represented as sbom root, nested components or dependencies
+	for _, r := range doc.Relationships {
+		a, b := string(r.RefA.ElementRefID), string(r.RefB.ElementRefID)
+		switch {
+		case getDescribedElement(r) != "" && string(getDescribedElement(r)) == rootRef:
+		case r.Relationship == spdxContains && parents[b] == a:
+		case r.Relationship == spdxContainedBy && parents[a] == b:
+		default:
+			if dependent, dependency, ok := getSpdxDependency(r); ok && dependencies[dependent][dependency] {
+				continue
+			}
+
+			loss.UnmappedRelationships = append(loss.UnmappedRelationships, renderRelationship(r))
+		}
+	}
+}
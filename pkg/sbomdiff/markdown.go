@@ -0,0 +1,100 @@
+package sbomdiff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+)
+
+// keeps summary within github comment size limit for large sboms
+const maxMarkdownRows = 50
+
+// escapes values which would break markdown table cells
+func escapeCell(value string) string {
+	value = strings.ReplaceAll(value, "|", "\\|")
+	return strings.ReplaceAll(value, "\n", " ")
+}
+
+func joinCell(values []string) string {
+	if len(values) == 0 {
+		return "-"
+	}
+
+	return escapeCell(strings.Join(values, ", "))
+}
+
+func componentName(group, name string) string {
+	if group != "" {
+		return escapeCell(group + "/" + name)
+	}
+
+	return escapeCell(name)
+}
+
+// writes table of section rows, skipping empty sections
+func writeSection(b *strings.Builder, title string, total int, header string, rows func(i int) string) {
+	if total == 0 {
+		return
+	}
+
+	fmt.Fprintf(b, "\n### %s (%d)\n\n%s\n", title, total, header)
+	for i := 0; i < total && i < maxMarkdownRows; i++ {
+		b.WriteString(rows(i))
+		b.WriteString("\n")
+	}
+
+	if total > maxMarkdownRows {
+		fmt.Fprintf(b, "\n_and %d more_\n", total-maxMarkdownRows)
+	}
+}
+
+// Markdown renders sbom diff as summary suitable for a pull request comment
+func Markdown(diff types.SbomDiff) string {
+	b := &strings.Builder{}
+
+	fmt.Fprintf(b, "## SBOM diff\n\nBase `%s` → Head `%s`\n\n", diff.BaseSbomId, diff.HeadSbomId)
+	b.WriteString("| Change | Count |\n| :-- | --: |\n")
+	fmt.Fprintf(b, "| Added components | %d |\n", len(diff.Added))
+	fmt.Fprintf(b, "| Removed components | %d |\n", len(diff.Removed))
+	fmt.Fprintf(b, "| Version changes | %d |\n", len(diff.VersionChanged))
+	fmt.Fprintf(b, "| License changes | %d |\n", len(diff.LicenseChanged))
+	fmt.Fprintf(b, "| Introduced vulns | %d |\n", len(diff.IntroducedVulns))
+	fmt.Fprintf(b, "| Resolved vulns | %d |\n", len(diff.ResolvedVulns))
+
+	if !diff.BaseAnalyzed || !diff.HeadAnalyzed {
+		b.WriteString("\n> Vulns are incomplete since components of both sboms are not analyzed yet\n")
+	}
+
+	writeSection(b, "Introduced vulns", len(diff.IntroducedVulns), "| Vuln | Component | Version | Summary |\n| :-- | :-- | :-- | :-- |", func(i int) string {
+		vuln := diff.IntroducedVulns[i]
+		return fmt.Sprintf("| %s | %s | %s | %s |", escapeCell(vuln.Id), escapeCell(vuln.ComponentName), escapeCell(vuln.ComponentVersion), escapeCell(vuln.Summary))
+	})
+
+	writeSection(b, "Resolved vulns", len(diff.ResolvedVulns), "| Vuln | Component | Version | Summary |\n| :-- | :-- | :-- | :-- |", func(i int) string {
+		vuln := diff.ResolvedVulns[i]
+		return fmt.Sprintf("| %s | %s | %s | %s |", escapeCell(vuln.Id), escapeCell(vuln.ComponentName), escapeCell(vuln.ComponentVersion), escapeCell(vuln.Summary))
+	})
+
+	writeSection(b, "Added components", len(diff.Added), "| Component | Versions | Licenses |\n| :-- | :-- | :-- |", func(i int) string {
+		component := diff.Added[i]
+		return fmt.Sprintf("| %s | %s | %s |", componentName(component.Group, component.Name), joinCell(component.Versions), joinCell(component.Licenses))
+	})
+
+	writeSection(b, "Removed components", len(diff.Removed), "| Component | Versions | Licenses |\n| :-- | :-- | :-- |", func(i int) string {
+		component := diff.Removed[i]
+		return fmt.Sprintf("| %s | %s | %s |", componentName(component.Group, component.Name), joinCell(component.Versions), joinCell(component.Licenses))
+	})
+
+	writeSection(b, "Version changes", len(diff.VersionChanged), "| Component | Base | Head |\n| :-- | :-- | :-- |", func(i int) string {
+		change := diff.VersionChanged[i]
+		return fmt.Sprintf("| %s | %s | %s |", componentName(change.Group, change.Name), joinCell(change.BaseVersions), joinCell(change.HeadVersions))
+	})
+
+	writeSection(b, "License changes", len(diff.LicenseChanged), "| Component | Base | Head |\n| :-- | :-- | :-- |", func(i int) string {
+		change := diff.LicenseChanged[i]
+		return fmt.Sprintf("| %s | %s | %s |", componentName(change.Group, change.Name), joinCell(change.BaseLicenses), joinCell(change.HeadLicenses))
+	})
+
+	return b.String()
+}
@@ -0,0 +1,290 @@
+package sbomdiff
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/utils"
+)
+
+// component of an sbom along with all versions of it present in the sbom
+type diffEntry struct {
+	name     string
+	group    string
+	purl     string
+	versions map[string]bool
+	licenses map[string]bool
+}
+
+type sbomEntries struct {
+	entries map[string]*diffEntry // component key to entry
+	refKeys map[string]string     // component ref to component key
+}
+
+// returns key used to match components across sboms
+func componentKey(purl, group, name string) string {
+	if key := utils.CanonicalPurl(purl); key != "" {
+		return key
+	}
+
+	if group != "" {
+		return group + "/" + name
+	}
+
+	return name
+}
+
+// returns license ids, names or expressions of cyclonedx licenses
+func getLicenses(licenses *cyclonedx.Licenses) []string {
+	var names []string
+	if licenses == nil {
+		return names
+	}
+
+	for _, license := range *licenses {
+		switch {
+		case license.License != nil && license.License.ID != "":
+			names = append(names, license.License.ID)
+		case license.License != nil && license.License.Name != "":
+			names = append(names, license.License.Name)
+		case license.Expression != "":
+			names = append(names, license.Expression)
+		}
+	}
+
+	return names
+}
+
+func (s *sbomEntries) add(ref, purl, group, name, version string, licenses []string) {
+	key := componentKey(purl, group, name)
+	s.refKeys[ref] = key
+
+	entry, exists := s.entries[key]
+	if !exists {
+		entry = &diffEntry{
+			name:     name,
+			group:    group,
+			purl:     purl,
+			versions: map[string]bool{},
+			licenses: map[string]bool{},
+		}
+		s.entries[key] = entry
+	}
+
+	if version != "" {
+		entry.versions[version] = true
+	}
+	for _, license := range licenses {
+		entry.licenses[license] = true
+	}
+}
+
+// refs are generated the same way as component analysis so stored components can be matched
+func (s *sbomEntries) addComponents(components *[]cyclonedx.Component, parentRef string) {
+	if components == nil {
+		return
+	}
+
+	for i, component := range *components {
+		ref := strconv.Itoa(i)
+		if parentRef != "" {
+			ref = parentRef + "/" + ref
+		}
+
+		s.add(ref, component.PackageURL, component.Group, component.Name, component.Version, getLicenses(component.Licenses))
+		s.addComponents(component.Components, ref)
+	}
+}
+
+func (s *sbomEntries) addServices(services *[]cyclonedx.Service, parentRef string) {
+	if services == nil {
+		return
+	}
+
+	for i, service := range *services {
+		ref := "s" + strconv.Itoa(i)
+		if parentRef != "" {
+			ref = parentRef + "/" + ref
+		}
+
+		s.add(ref, "", service.Group, service.Name, service.Version, getLicenses(service.Licenses))
+		s.addServices(service.Services, ref)
+	}
+}
+
+func getSbomEntries(sbom types.Sbom) sbomEntries {
+	s := sbomEntries{
+		entries: map[string]*diffEntry{},
+		refKeys: map[string]string{},
+	}
+	s.addComponents(sbom.Components, "")
+	s.addServices(sbom.Services, "")
+
+	return s
+}
+
+// returns vulns of stored components keyed by vuln id and component key along with count of
+// stored components
+func getVulns(s sbomEntries, components types.ComponentsFunc) (map[string]types.DiffVuln, int, error) {
+	vulns := map[string]types.DiffVuln{}
+	count := 0
+	err := components(func(component types.Component) error {
+		count++
+		key, exists := s.refKeys[component.Ref]
+		if !exists {
+			// components analyzed before refs were stored
+			key = componentKey(component.PackageUrl, "", component.Name)
+		}
+
+		for _, vuln := range component.Vulns {
+			vulns[vuln.ID+"\x00"+key] = types.DiffVuln{
+				Id:               vuln.ID,
+				Summary:          vuln.Summary,
+				ComponentKey:     key,
+				ComponentName:    component.Name,
+				ComponentVersion: component.Version,
+			}
+		}
+
+		return nil
+	})
+
+	return vulns, count, err
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func equalSets(a, b map[string]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for key := range a {
+		if !b[key] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newDiffComponent(key string, entry *diffEntry) types.DiffComponent {
+	return types.DiffComponent{
+		Key:      key,
+		Name:     entry.name,
+		Group:    entry.group,
+		Purl:     entry.purl,
+		Versions: sortedKeys(entry.versions),
+		Licenses: sortedKeys(entry.licenses),
+	}
+}
+
+// returns vulns present in a but not in b sorted by component and vuln id
+func subtractVulns(a, b map[string]types.DiffVuln) []types.DiffVuln {
+	vulns := []types.DiffVuln{}
+	for key, vuln := range a {
+		if _, exists := b[key]; !exists {
+			vulns = append(vulns, vuln)
+		}
+	}
+
+	sort.Slice(vulns, func(i, j int) bool {
+		if vulns[i].ComponentKey != vulns[j].ComponentKey {
+			return vulns[i].ComponentKey < vulns[j].ComponentKey
+		}
+		return vulns[i].Id < vulns[j].Id
+	})
+
+	return vulns
+}
+
+// Diff compares components, licenses and vulns of head sbom against base sbom. Vulns are
+// read from analyzed components of each sbom.
+func Diff(base, head types.Sbom, baseComponents, headComponents types.ComponentsFunc) (types.SbomDiff, error) {
+	baseEntries := getSbomEntries(base)
+	headEntries := getSbomEntries(head)
+
+	baseVulns, baseCount, err := getVulns(baseEntries, baseComponents)
+	if err != nil {
+		return types.SbomDiff{}, err
+	}
+	headVulns, headCount, err := getVulns(headEntries, headComponents)
+	if err != nil {
+		return types.SbomDiff{}, err
+	}
+
+	diff := types.SbomDiff{
+		BaseSbomId:     base.Id,
+		HeadSbomId:     head.Id,
+		Added:          []types.DiffComponent{},
+		Removed:        []types.DiffComponent{},
+		VersionChanged: []types.DiffComponentChange{},
+		LicenseChanged: []types.DiffComponentChange{},
+		BaseAnalyzed:   base.Analysis != nil || baseCount > 0,
+		HeadAnalyzed:   head.Analysis != nil || headCount > 0,
+	}
+
+	headKeys := make([]string, 0, len(headEntries.entries))
+	for key := range headEntries.entries {
+		headKeys = append(headKeys, key)
+	}
+	sort.Strings(headKeys)
+
+	for _, key := range headKeys {
+		headEntry := headEntries.entries[key]
+		baseEntry, exists := baseEntries.entries[key]
+		if !exists {
+			diff.Added = append(diff.Added, newDiffComponent(key, headEntry))
+			continue
+		}
+
+		versionChanged := !equalSets(baseEntry.versions, headEntry.versions)
+		licenseChanged := !equalSets(baseEntry.licenses, headEntry.licenses)
+		if !versionChanged && !licenseChanged {
+			continue
+		}
+
+		change := types.DiffComponentChange{
+			Key:          key,
+			Name:         headEntry.name,
+			Group:        headEntry.group,
+			BaseVersions: sortedKeys(baseEntry.versions),
+			HeadVersions: sortedKeys(headEntry.versions),
+			BaseLicenses: sortedKeys(baseEntry.licenses),
+			HeadLicenses: sortedKeys(headEntry.licenses),
+		}
+		if versionChanged {
+			diff.VersionChanged = append(diff.VersionChanged, change)
+		}
+		if licenseChanged {
+			diff.LicenseChanged = append(diff.LicenseChanged, change)
+		}
+	}
+
+	baseKeys := make([]string, 0, len(baseEntries.entries))
+	for key := range baseEntries.entries {
+		if _, exists := headEntries.entries[key]; !exists {
+			baseKeys = append(baseKeys, key)
+		}
+	}
+	sort.Strings(baseKeys)
+
+	for _, key := range baseKeys {
+		diff.Removed = append(diff.Removed, newDiffComponent(key, baseEntries.entries[key]))
+	}
+
+	diff.IntroducedVulns = subtractVulns(headVulns, baseVulns)
+	diff.ResolvedVulns = subtractVulns(baseVulns, headVulns)
+
+	return diff, nil
+}
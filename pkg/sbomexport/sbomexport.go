@@ -0,0 +1,113 @@
+package sbomexport
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/sbomconvert"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/sbomformat"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+	"github.com/rs/zerolog/log"
+)
+
+// ExportFormats lists formats sboms can be exported in
+var ExportFormats = []sbomformat.Format{sbomformat.CycloneDxJson, sbomformat.CycloneDxXml, sbomformat.SpdxJson}
+
+// returns components of bom indexed by bom-ref
+func indexBomRefs(components *[]cyclonedx.Component, index map[string]*cyclonedx.Component) {
+	if components == nil {
+		return
+	}
+
+	for i := range *components {
+		component := &(*components)[i]
+		if component.BOMRef != "" {
+			index[component.BOMRef] = component
+		}
+		indexBomRefs(component.Components, index)
+	}
+}
+
+// spdx 2.x does not have vulnerabilities, so they are added to affected packages as advisory
+// external references which are converted to spdx SECURITY advisory references.
+func addAdvisoryReferences(bom *cyclonedx.BOM) {
+	if bom.Vulnerabilities == nil {
+		return
+	}
+
+	components := map[string]*cyclonedx.Component{}
+	indexBomRefs(bom.Components, components)
+
+	for _, vulnerability := range *bom.Vulnerabilities {
+		if vulnerability.Affects == nil || vulnerability.Source == nil || vulnerability.Source.URL == "" {
+			continue
+		}
+
+		for _, affects := range *vulnerability.Affects {
+			component, exists := components[affects.Ref]
+			if !exists {
+				continue
+			}
+
+			if component.ExternalReferences == nil {
+				component.ExternalReferences = &[]cyclonedx.ExternalReference{}
+			}
+			*component.ExternalReferences = append(*component.ExternalReferences, cyclonedx.ExternalReference{
+				URL:     vulnerability.Source.URL,
+				Type:    cyclonedx.ERTypeAdvisories,
+				Comment: vulnerability.ID,
+			})
+		}
+	}
+}
+
+// Export encodes vulnerability disclosure report of sbom in the format
+func Export(sbom types.Sbom, components types.ComponentsFunc, format sbomformat.Format) ([]byte, error) {
+	bom, err := BuildVdr(sbom, components)
+	if err != nil {
+		return nil, err
+	}
+
+	// vulnerabilities were introduced in spec version 1.4
+	specVersion := bom.SpecVersion
+	if specVersion < cyclonedx.SpecVersion1_4 {
+		specVersion = cyclonedx.SpecVersion1_4
+	}
+
+	buf := &bytes.Buffer{}
+	switch format {
+	case sbomformat.CycloneDxJson, sbomformat.SpdxJson:
+		bom.BOMFormat = cyclonedx.BOMFormat
+		if format == sbomformat.SpdxJson {
+			addAdvisoryReferences(bom)
+		}
+
+		if err := cyclonedx.NewBOMEncoder(buf, cyclonedx.BOMFileFormatJSON).SetPretty(true).EncodeVersion(bom, specVersion); err != nil {
+			log.Error().Err(err).Msg("failed to encode sbom as json")
+			return nil, err
+		}
+
+		if format == sbomformat.CycloneDxJson {
+			return buf.Bytes(), nil
+		}
+
+		outputStream := &types.WriteCloser{Buffer: &bytes.Buffer{}}
+		if err := sbomconvert.ConvertSbom(&types.ReadSeekCloser{Reader: bytes.NewReader(buf.Bytes())}, outputStream); err != nil {
+			log.Error().Err(err).Msg("failed to convert sbom to spdx")
+			return nil, err
+		}
+
+		return outputStream.Bytes(), nil
+
+	case sbomformat.CycloneDxXml:
+		if err := cyclonedx.NewBOMEncoder(buf, cyclonedx.BOMFileFormatXML).SetPretty(true).EncodeVersion(bom, specVersion); err != nil {
+			log.Error().Err(err).Msg("failed to encode sbom as xml")
+			return nil, err
+		}
+
+		return buf.Bytes(), nil
+	}
+
+	return nil, fmt.Errorf("unsupported export format %s", format)
+}
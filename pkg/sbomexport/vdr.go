@@ -0,0 +1,319 @@
+package sbomexport
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+)
+
+// namespace of defect-detect specific vulnerability properties
+const propertyNamespace = "defect-detect:"
+
+// bom-ref prefix of components which did not have bom-ref in uploaded sbom
+const generatedBomRefPrefix = "defect-detect-ref:"
+
+// returns source of vuln database which assigned the vuln id
+func getVulnSource(id string) *cyclonedx.Source {
+	switch {
+	case strings.HasPrefix(id, "GHSA-"):
+		return &cyclonedx.Source{Name: "GitHub", URL: "https://github.com/advisories/" + id}
+	case strings.HasPrefix(id, "CVE-"):
+		return &cyclonedx.Source{Name: "NVD", URL: "https://nvd.nist.gov/vuln/detail/" + id}
+	}
+
+	return &cyclonedx.Source{Name: "OSV", URL: "https://osv.dev/vulnerability/" + id}
+}
+
+// returns cvss scoring method of osv severity
+func getScoringMethod(severity types.CvssSeverity) cyclonedx.ScoringMethod {
+	switch {
+	case strings.HasPrefix(severity.ScoreStr, "CVSS:4.0"):
+		return cyclonedx.ScoringMethodCVSSv4
+	case strings.HasPrefix(severity.ScoreStr, "CVSS:3.1"):
+		return cyclonedx.ScoringMethodCVSSv31
+	case strings.HasPrefix(severity.ScoreStr, "CVSS:3"):
+		return cyclonedx.ScoringMethodCVSSv3
+	case severity.TypeStr == "CVSS_V2":
+		return cyclonedx.ScoringMethodCVSSv2
+	}
+
+	return cyclonedx.ScoringMethodOther
+}
+
+// returns cyclonedx severity of github advisory severity
+func getSeverity(severity string) cyclonedx.Severity {
+	switch strings.ToLower(severity) {
+	case "critical":
+		return cyclonedx.SeverityCritical
+	case "high":
+		return cyclonedx.SeverityHigh
+	case "moderate", "medium":
+		return cyclonedx.SeverityMedium
+	case "low":
+		return cyclonedx.SeverityLow
+	}
+
+	return cyclonedx.SeverityUnknown
+}
+
+func getRatings(vuln types.Vuln) []cyclonedx.VulnerabilityRating {
+	ratings := []cyclonedx.VulnerabilityRating{}
+	for _, severity := range vuln.CvssSeverity {
+		if severity.ScoreStr == "" {
+			continue
+		}
+
+		ratings = append(ratings, cyclonedx.VulnerabilityRating{
+			Source: getVulnSource(vuln.ID),
+			Method: getScoringMethod(severity),
+			Vector: severity.ScoreStr,
+		})
+	}
+
+	if vuln.GhsaDatabaseSpecific.Severity != "" {
+		ratings = append(ratings, cyclonedx.VulnerabilityRating{
+			Source:   &cyclonedx.Source{Name: "GitHub"},
+			Severity: getSeverity(vuln.GhsaDatabaseSpecific.Severity),
+			Method:   cyclonedx.ScoringMethodOther,
+		})
+	}
+
+	return ratings
+}
+
+// returns cwe ids without CWE- prefix
+func getCwes(vuln types.Vuln) []int {
+	cwes := []int{}
+	for _, cweId := range vuln.GhsaDatabaseSpecific.CweIds {
+		cwe, err := strconv.Atoi(strings.TrimPrefix(strings.ToUpper(cweId), "CWE-"))
+		if err == nil {
+			cwes = append(cwes, cwe)
+		}
+	}
+
+	return cwes
+}
+
+func getAdvisories(vuln types.Vuln) []cyclonedx.Advisory {
+	advisories := []cyclonedx.Advisory{}
+	for _, reference := range vuln.References {
+		if reference.URL != "" {
+			advisories = append(advisories, cyclonedx.Advisory{URL: reference.URL})
+		}
+	}
+
+	return advisories
+}
+
+func getEpssProperties(epss types.Epss) []cyclonedx.Property {
+	properties := []cyclonedx.Property{}
+	for _, property := range []cyclonedx.Property{
+		{Name: "epss:score", Value: epss.EpssScore},
+		{Name: "epss:percentile", Value: epss.Percentile},
+		{Name: "epss:date", Value: epss.Date},
+	} {
+		if property.Value != "" {
+			property.Name = propertyNamespace + property.Name
+			properties = append(properties, property)
+		}
+	}
+
+	return properties
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
+
+func newVulnerability(vuln types.Vuln) *cyclonedx.Vulnerability {
+	vulnerability := &cyclonedx.Vulnerability{
+		ID:          vuln.ID,
+		Source:      getVulnSource(vuln.ID),
+		Description: vuln.Summary,
+		Detail:      vuln.Details,
+		Published:   formatTime(vuln.Published),
+		Updated:     formatTime(vuln.Modified),
+		Affects:     &[]cyclonedx.Affects{},
+	}
+
+	if len(vuln.Aliases) > 0 {
+		references := []cyclonedx.VulnerabilityReference{}
+		for _, alias := range vuln.Aliases {
+			references = append(references, cyclonedx.VulnerabilityReference{ID: alias, Source: getVulnSource(alias)})
+		}
+		vulnerability.References = &references
+	}
+	if ratings := getRatings(vuln); len(ratings) > 0 {
+		vulnerability.Ratings = &ratings
+	}
+	if cwes := getCwes(vuln); len(cwes) > 0 {
+		vulnerability.CWEs = &cwes
+	}
+	if advisories := getAdvisories(vuln); len(advisories) > 0 {
+		vulnerability.Advisories = &advisories
+	}
+	if properties := getEpssProperties(vuln.Epss); len(properties) > 0 {
+		vulnerability.Properties = &properties
+	}
+
+	return vulnerability
+}
+
+// indexes bom components by analysis ref so stored components can be matched with them
+func indexComponents(components *[]cyclonedx.Component, parentRef string, index map[string]*cyclonedx.Component) {
+	if components == nil {
+		return
+	}
+
+	for i := range *components {
+		component := &(*components)[i]
+		ref := strconv.Itoa(i)
+		if parentRef != "" {
+			ref = parentRef + "/" + ref
+		}
+
+		index[ref] = component
+		indexComponents(component.Components, ref, index)
+	}
+}
+
+func indexServices(services *[]cyclonedx.Service, parentRef string, index map[string]*cyclonedx.Service) {
+	if services == nil {
+		return
+	}
+
+	for i := range *services {
+		service := &(*services)[i]
+		ref := "s" + strconv.Itoa(i)
+		if parentRef != "" {
+			ref = parentRef + "/" + ref
+		}
+
+		index[ref] = service
+		indexServices(service.Services, ref, index)
+	}
+}
+
+// returns bom-ref of bom component or service matching stored component. Bom-ref is generated
+// for components without one so vulns can refer to them. Empty string is returned when
+// component is not part of the bom.
+func getAffectedBomRef(component types.Component, components map[string]*cyclonedx.Component, services map[string]*cyclonedx.Service) string {
+	if bomComponent, exists := components[component.Ref]; exists {
+		if bomComponent.BOMRef == "" {
+			bomComponent.BOMRef = generatedBomRefPrefix + component.Ref
+		}
+		return bomComponent.BOMRef
+	}
+
+	if bomService, exists := services[component.Ref]; exists {
+		if bomService.BOMRef == "" {
+			bomService.BOMRef = generatedBomRefPrefix + component.Ref
+		}
+		return bomService.BOMRef
+	}
+
+	// components analyzed before refs were stored
+	return component.BomRef
+}
+
+// adds affects entry to vulnerability unless the referenced component is already listed
+func addAffects(vulnerability *cyclonedx.Vulnerability, affects cyclonedx.Affects) {
+	if vulnerability.Affects == nil {
+		vulnerability.Affects = &[]cyclonedx.Affects{}
+	}
+
+	for _, existing := range *vulnerability.Affects {
+		if existing.Ref == affects.Ref {
+			return
+		}
+	}
+	*vulnerability.Affects = append(*vulnerability.Affects, affects)
+}
+
+// BuildVdr returns cyclonedx bom of sbom with vulnerabilities of analyzed components, which
+// makes it a vulnerability disclosure report. Vulnerabilities already present in sbom are kept
+// and components affected by them according to analysis are added to their affects.
+func BuildVdr(sbom types.Sbom, components types.ComponentsFunc) (*cyclonedx.BOM, error) {
+	bom := sbom.ToBom()
+
+	bomComponents := map[string]*cyclonedx.Component{}
+	indexComponents(bom.Components, "", bomComponents)
+	bomServices := map[string]*cyclonedx.Service{}
+	indexServices(bom.Services, "", bomServices)
+
+	vulnerabilities := []cyclonedx.Vulnerability{}
+	if bom.Vulnerabilities != nil {
+		vulnerabilities = append(vulnerabilities, *bom.Vulnerabilities...)
+	}
+	existingVulns := map[string]*cyclonedx.Vulnerability{}
+	for i := range vulnerabilities {
+		// affects of sbom vulns are copied before analyzed components are added to them
+		if vulnerabilities[i].Affects != nil {
+			affects := append([]cyclonedx.Affects{}, *vulnerabilities[i].Affects...)
+			vulnerabilities[i].Affects = &affects
+		}
+		if _, exists := existingVulns[vulnerabilities[i].ID]; !exists {
+			existingVulns[vulnerabilities[i].ID] = &vulnerabilities[i]
+		}
+	}
+
+	// same vuln can affect multiple components
+	analyzedVulns := map[string]*cyclonedx.Vulnerability{}
+	var analyzedIds []string
+	err := components(func(component types.Component) error {
+		bomRef := getAffectedBomRef(component, bomComponents, bomServices)
+		if bomRef == "" {
+			return nil
+		}
+
+		for _, vuln := range component.Vulns {
+			if vuln.ID == "" {
+				continue
+			}
+
+			vulnerability, exists := existingVulns[vuln.ID]
+			if !exists {
+				vulnerability, exists = analyzedVulns[vuln.ID]
+			}
+			if !exists {
+				vulnerability = newVulnerability(vuln)
+				analyzedVulns[vuln.ID] = vulnerability
+				analyzedIds = append(analyzedIds, vuln.ID)
+			}
+
+			// vulns resolved by pedigree patch or vex statement do not affect component version
+			status := cyclonedx.VulnerabilityStatusAffected
+			if vuln.IsSuppressed() {
+				status = cyclonedx.VulnerabilityStatusNotAffected
+			}
+
+			affects := cyclonedx.Affects{Ref: bomRef}
+			if component.Version != "" {
+				affects.Range = &[]cyclonedx.AffectedVersions{{Version: component.Version, Status: status}}
+			}
+			addAffects(vulnerability, affects)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range analyzedIds {
+		vulnerabilities = append(vulnerabilities, *analyzedVulns[id])
+	}
+
+	if len(vulnerabilities) > 0 {
+		bom.Vulnerabilities = &vulnerabilities
+	}
+
+	return bom, nil
+}
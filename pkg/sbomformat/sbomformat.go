@@ -0,0 +1,208 @@
+package sbomformat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/rs/zerolog/log"
+	spdxjson "github.com/spdx/tools-golang/json"
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/spdx/tools-golang/tagvalue"
+	spdxyaml "github.com/spdx/tools-golang/yaml"
+)
+
+type Format string
+
+const (
+	CycloneDxJson  Format = "cyclonedx-json"
+	CycloneDxXml   Format = "cyclonedx-xml"
+	SpdxJson       Format = "spdx-json"
+	SpdxTagValue   Format = "spdx-tag-value"
+	SpdxYaml       Format = "spdx-yaml"
+	Lockfile       Format = "lockfile"        // sboms generated from lockfiles and manifests
+	ContainerImage Format = "container-image" // sboms generated from container image tarballs
+	Unknown        Format = "unknown"
+)
+
+var ErrUnknownFormat = fmt.Errorf("unable to detect sbom format")
+
+var (
+	spdxTagValueVersionRegex = regexp.MustCompile(`(?m)^SPDXVersion:\s*SPDX-`)
+	spdxYamlVersionRegex     = regexp.MustCompile(`(?m)^spdxVersion:\s*["']?SPDX-`)
+)
+
+// IsSpdx returns true for spdx formats which are converted to cyclonedx before storing
+func (f Format) IsSpdx() bool {
+	return f == SpdxJson || f == SpdxTagValue || f == SpdxYaml
+}
+
+// ContentType returns media type of documents in the format
+func (f Format) ContentType() string {
+	switch f {
+	case CycloneDxJson:
+		return "application/vnd.cyclonedx+json"
+	case CycloneDxXml:
+		return "application/vnd.cyclonedx+xml"
+	case SpdxJson:
+		return "application/spdx+json"
+	case SpdxTagValue:
+		return "text/spdx"
+	case SpdxYaml:
+		return "application/spdx+yaml"
+	}
+
+	return "application/octet-stream"
+}
+
+// Extension returns file extension of documents in the format
+func (f Format) Extension() string {
+	switch f {
+	case CycloneDxJson:
+		return "cdx.json"
+	case CycloneDxXml:
+		return "cdx.xml"
+	case SpdxJson:
+		return "spdx.json"
+	case SpdxTagValue:
+		return "spdx"
+	case SpdxYaml:
+		return "spdx.yaml"
+	}
+
+	return "bin"
+}
+
+// returns format hinted by content type or file name
+func formatFromHints(contentType, fileName string) Format {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err == nil {
+		switch {
+		case mediaType == "application/spdx+json":
+			return SpdxJson
+		case mediaType == "text/spdx":
+			return SpdxTagValue
+		case mediaType == "application/spdx+yaml":
+			return SpdxYaml
+		case strings.HasSuffix(mediaType, "json"):
+			return CycloneDxJson
+		case strings.HasSuffix(mediaType, "xml"):
+			return CycloneDxXml
+		}
+	}
+
+	name := strings.ToLower(fileName)
+	switch {
+	case strings.HasSuffix(name, ".spdx.json"):
+		return SpdxJson
+	case strings.HasSuffix(name, ".spdx.yaml"), strings.HasSuffix(name, ".spdx.yml"):
+		return SpdxYaml
+	}
+
+	switch filepath.Ext(name) {
+	case ".json":
+		return CycloneDxJson
+	case ".xml":
+		return CycloneDxXml
+	case ".spdx":
+		return SpdxTagValue
+	case ".yaml", ".yml":
+		return SpdxYaml
+	}
+
+	return Unknown
+}
+
+// returns true when json document is spdx instead of cyclonedx
+func isSpdxJson(content []byte) bool {
+	var doc struct {
+		SpdxVersion string `json:"spdxVersion"`
+	}
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return false
+	}
+
+	return strings.HasPrefix(doc.SpdxVersion, "SPDX-")
+}
+
+// Detect sniffs sbom format from content. Content type and file name are only used
+// when content itself is inconclusive.
+func Detect(content []byte, contentType, fileName string) (Format, error) {
+	trimmed := bytes.TrimLeft(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf")), " \t\r\n")
+
+	if len(trimmed) > 0 {
+		switch trimmed[0] {
+		case '{':
+			if isSpdxJson(trimmed) {
+				return SpdxJson, nil
+			}
+			return CycloneDxJson, nil
+		case '<':
+			return CycloneDxXml, nil
+		}
+	}
+
+	switch {
+	case spdxTagValueVersionRegex.Match(trimmed):
+		return SpdxTagValue, nil
+	case spdxYamlVersionRegex.Match(trimmed):
+		return SpdxYaml, nil
+	}
+
+	if format := formatFromHints(contentType, fileName); format != Unknown {
+		log.Warn().Msgf("sbom format could not be sniffed from content, using hinted format %s", format)
+		return format, nil
+	}
+
+	return Unknown, ErrUnknownFormat
+}
+
+// Decode decodes cyclonedx sbom in provided format
+func Decode(content []byte, format Format) (*cyclonedx.BOM, error) {
+	var fileFormat cyclonedx.BOMFileFormat
+	switch format {
+	case CycloneDxJson:
+		fileFormat = cyclonedx.BOMFileFormatJSON
+	case CycloneDxXml:
+		fileFormat = cyclonedx.BOMFileFormatXML
+	default:
+		return nil, ErrUnknownFormat
+	}
+
+	var bom cyclonedx.BOM
+	if err := cyclonedx.NewBOMDecoder(bytes.NewReader(content), fileFormat).Decode(&bom); err != nil {
+		log.Error().Err(err).Msgf("failed to decode %s sbom", format)
+		return nil, err
+	}
+
+	return &bom, nil
+}
+
+// ReadSpdx parses spdx 2.x document in provided format. Older spdx versions are
+// upgraded to the latest supported spdx version.
+func ReadSpdx(content []byte, format Format) (*spdx.Document, error) {
+	var doc *spdx.Document
+	var err error
+	switch format {
+	case SpdxJson:
+		doc, err = spdxjson.Read(bytes.NewReader(content))
+	case SpdxTagValue:
+		doc, err = tagvalue.Read(bytes.NewReader(content))
+	case SpdxYaml:
+		doc, err = spdxyaml.Read(bytes.NewReader(content))
+	default:
+		return nil, ErrUnknownFormat
+	}
+
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to read %s sbom", format)
+		return nil, err
+	}
+
+	return doc, nil
+}
@@ -0,0 +1,310 @@
+package sbommerge
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+	"github.com/google/uuid"
+)
+
+type merger struct {
+	components []cyclonedx.Component
+	services   []cyclonedx.Service
+
+	keyRefs  map[string]string // dedupe key of kept component or service to its bom-ref
+	usedRefs map[string]bool
+
+	dependencies   map[string]map[string]bool
+	dependencyRefs []string // keeps dependency order stable
+
+	vulns   map[string]*cyclonedx.Vulnerability
+	vulnIds []string
+}
+
+// returns keys used to deduplicate components. Components with same purl or any same hash are
+// considered same, name and version are used for components without purl and hashes.
+func componentKeys(component cyclonedx.Component) []string {
+	var keys []string
+	if component.PackageURL != "" {
+		keys = append(keys, "purl:"+component.PackageURL)
+	}
+	if component.Hashes != nil {
+		for _, hash := range *component.Hashes {
+			if hash.Value != "" {
+				keys = append(keys, "hash:"+string(hash.Algorithm)+":"+strings.ToLower(hash.Value))
+			}
+		}
+	}
+	if len(keys) == 0 {
+		keys = append(keys, fmt.Sprintf("component:%s:%s/%s@%s", component.Type, component.Group, component.Name, component.Version))
+	}
+
+	return keys
+}
+
+// returns unique bom-ref for kept component. Refs colliding with components of other sboms are
+// prefixed with source sbom id and suffixed with a counter while they still collide, components
+// without bom-ref get a generated one so that they can be part of dependency graph.
+func (m *merger) newRef(ref, sbomId string) string {
+	if ref == "" {
+		ref = fmt.Sprintf("%s:%d", sbomId, len(m.components)+len(m.services))
+	}
+	if m.usedRefs[ref] {
+		prefixed := sbomId + ":" + ref
+		ref = prefixed
+		for i := 1; m.usedRefs[ref]; i++ {
+			ref = fmt.Sprintf("%s:%d", prefixed, i)
+		}
+	}
+	m.usedRefs[ref] = true
+
+	return ref
+}
+
+// adds component without its nested components and returns its bom-ref in merged sbom
+func (m *merger) addComponent(component cyclonedx.Component, sbomId string, refMap map[string]string) string {
+	keys := componentKeys(component)
+	for _, key := range keys {
+		if ref, exists := m.keyRefs[key]; exists {
+			if component.BOMRef != "" {
+				refMap[component.BOMRef] = ref
+			}
+			return ref
+		}
+	}
+
+	ref := m.newRef(component.BOMRef, sbomId)
+	if component.BOMRef != "" {
+		refMap[component.BOMRef] = ref
+	}
+	for _, key := range keys {
+		m.keyRefs[key] = ref
+	}
+
+	component.BOMRef = ref
+	component.Components = nil
+	m.components = append(m.components, component)
+
+	return ref
+}
+
+// adds components along with nested components, returns bom-refs of top level components
+func (m *merger) addComponents(components *[]cyclonedx.Component, sbomId string, refMap map[string]string) []string {
+	var refs []string
+	if components == nil {
+		return refs
+	}
+
+	for _, component := range *components {
+		ref := m.addComponent(component, sbomId, refMap)
+		refs = append(refs, ref)
+
+		// nesting is flattened, parent depends on its nested components to keep the relationship
+		if nestedRefs := m.addComponents(component.Components, sbomId, refMap); len(nestedRefs) > 0 {
+			m.addDependency(ref, nestedRefs...)
+		}
+	}
+
+	return refs
+}
+
+func (m *merger) addServices(services *[]cyclonedx.Service, sbomId string, refMap map[string]string) []string {
+	var refs []string
+	if services == nil {
+		return refs
+	}
+
+	for _, service := range *services {
+		key := fmt.Sprintf("service:%s/%s@%s", service.Group, service.Name, service.Version)
+		ref, exists := m.keyRefs[key]
+		if !exists {
+			ref = m.newRef(service.BOMRef, sbomId)
+			m.keyRefs[key] = ref
+
+			kept := service
+			kept.BOMRef = ref
+			kept.Services = nil
+			m.services = append(m.services, kept)
+		}
+		if service.BOMRef != "" {
+			refMap[service.BOMRef] = ref
+		}
+
+		refs = append(refs, ref)
+		if nestedRefs := m.addServices(service.Services, sbomId, refMap); len(nestedRefs) > 0 {
+			m.addDependency(ref, nestedRefs...)
+		}
+	}
+
+	return refs
+}
+
+func (m *merger) addDependency(ref string, dependsOn ...string) {
+	dependencies, exists := m.dependencies[ref]
+	if !exists {
+		dependencies = map[string]bool{}
+		m.dependencies[ref] = dependencies
+		m.dependencyRefs = append(m.dependencyRefs, ref)
+	}
+
+	for _, dependency := range dependsOn {
+		if dependency != ref {
+			dependencies[dependency] = true
+		}
+	}
+}
+
+// adds dependencies of source sbom using bom-refs of merged sbom. Dependencies referring to
+// elements which are not part of the sbom are dropped.
+func (m *merger) addDependencies(dependencies *[]cyclonedx.Dependency, refMap map[string]string) {
+	if dependencies == nil {
+		return
+	}
+
+	for _, dependency := range *dependencies {
+		ref, exists := refMap[dependency.Ref]
+		if !exists {
+			continue
+		}
+
+		dependsOn := []string{}
+		if dependency.Dependencies != nil {
+			for _, dependencyRef := range *dependency.Dependencies {
+				if mappedRef, exists := refMap[dependencyRef]; exists {
+					dependsOn = append(dependsOn, mappedRef)
+				}
+			}
+		}
+		m.addDependency(ref, dependsOn...)
+	}
+}
+
+// adds vulnerabilities of source sbom, affected components of same vulnerability are merged
+func (m *merger) addVulnerabilities(vulnerabilities *[]cyclonedx.Vulnerability, refMap map[string]string) {
+	if vulnerabilities == nil {
+		return
+	}
+
+	for _, vulnerability := range *vulnerabilities {
+		if vulnerability.ID == "" {
+			continue
+		}
+
+		affects := []cyclonedx.Affects{}
+		if vulnerability.Affects != nil {
+			for _, affected := range *vulnerability.Affects {
+				if ref, exists := refMap[affected.Ref]; exists {
+					affected.Ref = ref
+					affects = append(affects, affected)
+				}
+			}
+		}
+
+		merged, exists := m.vulns[vulnerability.ID]
+		if !exists {
+			vulnerability.BOMRef = ""
+			vulnerability.Affects = &affects
+			m.vulns[vulnerability.ID] = &vulnerability
+			m.vulnIds = append(m.vulnIds, vulnerability.ID)
+			continue
+		}
+
+		for _, affected := range affects {
+			duplicate := false
+			for _, existing := range *merged.Affects {
+				if existing.Ref == affected.Ref {
+					duplicate = true
+					break
+				}
+			}
+			if !duplicate {
+				*merged.Affects = append(*merged.Affects, affected)
+			}
+		}
+	}
+}
+
+// Merge returns aggregate bom of sboms with root as its metadata component. Root component of
+// each sbom becomes a dependency of root. Nested components and services are flattened into
+// dependencies of their parent and deduplicated using purl and hashes, bom-refs are rewritten when
+// they collide.
+func Merge(sboms []types.Sbom, root cyclonedx.Component) *cyclonedx.BOM {
+	m := &merger{
+		keyRefs:      map[string]string{},
+		usedRefs:     map[string]bool{},
+		dependencies: map[string]map[string]bool{},
+		vulns:        map[string]*cyclonedx.Vulnerability{},
+	}
+
+	if root.BOMRef == "" {
+		root.BOMRef = "merged-root"
+	}
+	root.BOMRef = m.newRef(root.BOMRef, "root")
+	m.addDependency(root.BOMRef)
+
+	specVersion := cyclonedx.SpecVersion1_4
+	for _, sbom := range sboms {
+		if sbom.SpecVersion > specVersion {
+			specVersion = sbom.SpecVersion
+		}
+
+		refMap := map[string]string{}
+		var rootRefs []string
+		if sbom.Metadata != nil && sbom.Metadata.Component != nil {
+			rootRefs = m.addComponents(&[]cyclonedx.Component{*sbom.Metadata.Component}, sbom.Id, refMap)
+			m.addComponents(sbom.Components, sbom.Id, refMap)
+			m.addServices(sbom.Services, sbom.Id, refMap)
+		} else {
+			// sbom without root component is represented by its top level components and services
+			rootRefs = m.addComponents(sbom.Components, sbom.Id, refMap)
+			rootRefs = append(rootRefs, m.addServices(sbom.Services, sbom.Id, refMap)...)
+		}
+
+		m.addDependency(root.BOMRef, rootRefs...)
+		m.addDependencies(sbom.Dependencies, refMap)
+		m.addVulnerabilities(sbom.Vulnerabilities, refMap)
+	}
+
+	bom := &cyclonedx.BOM{
+		BOMFormat:    cyclonedx.BOMFormat,
+		SpecVersion:  specVersion,
+		SerialNumber: uuid.New().URN(),
+		Version:      1,
+		Metadata: &cyclonedx.Metadata{
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Component: &root,
+		},
+	}
+
+	if len(m.components) > 0 {
+		bom.Components = &m.components
+	}
+	if len(m.services) > 0 {
+		bom.Services = &m.services
+	}
+
+	dependencies := []cyclonedx.Dependency{}
+	for _, ref := range m.dependencyRefs {
+		dependsOn := []string{}
+		for dependency := range m.dependencies[ref] {
+			dependsOn = append(dependsOn, dependency)
+		}
+		sort.Strings(dependsOn)
+		dependencies = append(dependencies, cyclonedx.Dependency{Ref: ref, Dependencies: &dependsOn})
+	}
+	bom.Dependencies = &dependencies
+
+	if len(m.vulnIds) > 0 {
+		vulnerabilities := []cyclonedx.Vulnerability{}
+		for _, id := range m.vulnIds {
+			vulnerabilities = append(vulnerabilities, *m.vulns[id])
+		}
+		bom.Vulnerabilities = &vulnerabilities
+	}
+
+	return bom
+}
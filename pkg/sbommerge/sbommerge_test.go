@@ -0,0 +1,17 @@
+package sbommerge
+
+import "testing"
+
+// colliding refs stay unique even when prefixed ref is used by another sbom
+func TestNewRef(t *testing.T) {
+	m := &merger{usedRefs: map[string]bool{"pkg:npm/lodash": true, "b:pkg:npm/lodash": true}}
+
+	for _, want := range []string{"b:pkg:npm/lodash:1", "b:pkg:npm/lodash:2"} {
+		if got := m.newRef("pkg:npm/lodash", "b"); got != want {
+			t.Errorf("newRef() = %q, want %q", got, want)
+		}
+	}
+	if got := m.newRef("pkg:npm/react", "b"); got != "pkg:npm/react" {
+		t.Errorf("newRef() = %q, want %q", got, "pkg:npm/react")
+	}
+}
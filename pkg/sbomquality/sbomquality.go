@@ -0,0 +1,199 @@
+package sbomquality
+
+import (
+	"time"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+)
+
+// componentCheck returns true when component passes the check
+type componentCheck struct {
+	name     string
+	category string
+	passed   func(component cyclonedx.Component, graph dependencyGraph) bool
+}
+
+type dependencyGraph struct {
+	refs      map[string]bool // refs having dependency entry
+	dependsOn map[string]bool // refs which other elements depend on
+}
+
+func isOrganizationalEntitySet(entity *cyclonedx.OrganizationalEntity) bool {
+	return entity != nil && entity.Name != ""
+}
+
+var componentChecks = []componentCheck{
+	{
+		name:     "supplier",
+		category: types.QualityCategoryNtia,
+		passed: func(component cyclonedx.Component, _ dependencyGraph) bool {
+			return isOrganizationalEntitySet(component.Supplier) || isOrganizationalEntitySet(component.Manufacturer) || component.Publisher != ""
+		},
+	},
+	{
+		name:     "name",
+		category: types.QualityCategoryNtia,
+		passed: func(component cyclonedx.Component, _ dependencyGraph) bool {
+			return component.Name != ""
+		},
+	},
+	{
+		name:     "version",
+		category: types.QualityCategoryNtia,
+		passed: func(component cyclonedx.Component, _ dependencyGraph) bool {
+			return component.Version != ""
+		},
+	},
+	{
+		name:     "unique_identifiers",
+		category: types.QualityCategoryNtia,
+		passed: func(component cyclonedx.Component, _ dependencyGraph) bool {
+			return component.PackageURL != "" || component.CPE != "" || component.SWID != nil
+		},
+	},
+	{
+		// component is part of dependency graph, either depending on or depended upon
+		name:     "dependency_relationships",
+		category: types.QualityCategoryNtia,
+		passed: func(component cyclonedx.Component, graph dependencyGraph) bool {
+			return component.BOMRef != "" && (graph.refs[component.BOMRef] || graph.dependsOn[component.BOMRef])
+		},
+	},
+	{
+		name:     "purl",
+		category: types.QualityCategoryExtra,
+		passed: func(component cyclonedx.Component, _ dependencyGraph) bool {
+			return component.PackageURL != ""
+		},
+	},
+	{
+		name:     "hashes",
+		category: types.QualityCategoryExtra,
+		passed: func(component cyclonedx.Component, _ dependencyGraph) bool {
+			return component.Hashes != nil && len(*component.Hashes) > 0
+		},
+	},
+	{
+		name:     "licenses",
+		category: types.QualityCategoryExtra,
+		passed: func(component cyclonedx.Component, _ dependencyGraph) bool {
+			return component.Licenses != nil && len(*component.Licenses) > 0
+		},
+	},
+	{
+		// dependencies of component are declared, empty dependsOn declares that it has none
+		name:     "dependency_completeness",
+		category: types.QualityCategoryExtra,
+		passed: func(component cyclonedx.Component, graph dependencyGraph) bool {
+			return component.BOMRef != "" && graph.refs[component.BOMRef]
+		},
+	},
+}
+
+func getDependencyGraph(dependencies *[]cyclonedx.Dependency) dependencyGraph {
+	graph := dependencyGraph{refs: map[string]bool{}, dependsOn: map[string]bool{}}
+	if dependencies == nil {
+		return graph
+	}
+
+	for _, dependency := range *dependencies {
+		graph.refs[dependency.Ref] = true
+		if dependency.Dependencies != nil {
+			for _, ref := range *dependency.Dependencies {
+				graph.dependsOn[ref] = true
+			}
+		}
+	}
+
+	return graph
+}
+
+// returns components along with nested components
+func flattenComponents(components *[]cyclonedx.Component, flattened []cyclonedx.Component) []cyclonedx.Component {
+	if components == nil {
+		return flattened
+	}
+
+	for _, component := range *components {
+		flattened = append(flattened, component)
+		flattened = flattenComponents(component.Components, flattened)
+	}
+
+	return flattened
+}
+
+func newCheck(name, category string, passed, total int) types.QualityCheck {
+	check := types.QualityCheck{Name: name, Category: category, Passed: passed, Total: total}
+	if total > 0 {
+		check.Score = passed * 100 / total
+	}
+
+	return check
+}
+
+// returns 1 of 1 check when passed, 0 of 1 otherwise
+func newMetadataCheck(name, category string, passed bool) types.QualityCheck {
+	if passed {
+		return newCheck(name, category, 1, 1)
+	}
+
+	return newCheck(name, category, 0, 1)
+}
+
+// Score checks sbom components and metadata against NTIA minimum elements and extra checks.
+// Score of each check is percentage of passing elements and sbom score is average of all checks.
+// Sboms without components score 0 for component checks.
+func Score(sbom types.Sbom) types.SbomQuality {
+	components := flattenComponents(sbom.Components, nil)
+	graph := getDependencyGraph(sbom.Dependencies)
+
+	checks := []types.QualityCheck{}
+	for _, check := range componentChecks {
+		passed := 0
+		for _, component := range components {
+			if check.passed(component, graph) {
+				passed++
+			}
+		}
+		checks = append(checks, newCheck(check.name, check.category, passed, len(components)))
+	}
+
+	metadata := sbom.Metadata
+	if metadata == nil {
+		metadata = &cyclonedx.Metadata{}
+	}
+
+	_, timestampErr := time.Parse(time.RFC3339, metadata.Timestamp)
+	hasAuthor := (metadata.Authors != nil && len(*metadata.Authors) > 0) ||
+		isOrganizationalEntitySet(metadata.Supplier) ||
+		isOrganizationalEntitySet(metadata.Manufacturer) ||
+		isOrganizationalEntitySet(metadata.Manufacture)
+	hasTools := metadata.Tools != nil &&
+		((metadata.Tools.Tools != nil && len(*metadata.Tools.Tools) > 0) ||
+			(metadata.Tools.Components != nil && len(*metadata.Tools.Components) > 0) ||
+			(metadata.Tools.Services != nil && len(*metadata.Tools.Services) > 0))
+
+	checks = append(checks,
+		newMetadataCheck("author", types.QualityCategoryNtia, hasAuthor),
+		newMetadataCheck("timestamp", types.QualityCategoryNtia, timestampErr == nil),
+		newMetadataCheck("tools", types.QualityCategoryExtra, hasTools),
+	)
+
+	quality := types.SbomQuality{
+		NtiaCompliant: len(components) > 0,
+		Checks:        checks,
+		ScoredAt:      time.Now(),
+	}
+
+	total := 0
+	for _, check := range checks {
+		total += check.Score
+		if check.Category == types.QualityCategoryNtia && check.Score < 100 {
+			quality.NtiaCompliant = false
+		}
+	}
+	quality.Score = total / len(checks)
+
+	return quality
+}
@@ -0,0 +1,120 @@
+package sbomsign
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func canonicalizeJson(t *testing.T, content string) string {
+	t.Helper()
+
+	value, err := decodeJson([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	canonical, err := canonicalize(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(canonical)
+}
+
+// number serialization samples of RFC 8785 appendix B
+func TestFormatNumber(t *testing.T) {
+	tests := []struct {
+		bits uint64
+		want string
+	}{
+		{0x0000000000000000, "0"},
+		{0x8000000000000000, "0"},
+		{0x0000000000000001, "5e-324"},
+		{0x8000000000000001, "-5e-324"},
+		{0x7fefffffffffffff, "1.7976931348623157e+308"},
+		{0xffefffffffffffff, "-1.7976931348623157e+308"},
+		{0x4340000000000000, "9007199254740992"},
+		{0xc340000000000000, "-9007199254740992"},
+		{0x4430000000000000, "295147905179352830000"},
+		{0x44b52d02c7e14af5, "9.999999999999997e+22"},
+		{0x44b52d02c7e14af6, "1e+23"},
+		{0x44b52d02c7e14af7, "1.0000000000000001e+23"},
+		{0x444b1ae4d6e2ef4e, "999999999999999700000"},
+		{0x444b1ae4d6e2ef4f, "999999999999999900000"},
+		{0x444b1ae4d6e2ef50, "1e+21"},
+		{0x3eb0c6f7a0b5ed8c, "9.999999999999997e-7"},
+		{0x3eb0c6f7a0b5ed8d, "0.000001"},
+		{0x41b3de4355555553, "333333333.3333332"},
+		{0x41b3de4355555554, "333333333.33333325"},
+		{0x41b3de4355555555, "333333333.3333333"},
+		{0x41b3de4355555556, "333333333.3333334"},
+		{0x41b3de4355555557, "333333333.33333343"},
+		{0xbecbf647612f3696, "-0.0000033333333333333333"},
+		{0x43143ff3c1cb0959, "1424953923781206.2"},
+	}
+
+	for _, test := range tests {
+		number := json.Number(strconv.FormatFloat(math.Float64frombits(test.bits), 'g', -1, 64))
+		got, err := formatNumber(number)
+		if err != nil {
+			t.Errorf("formatNumber(%s) failed: %v", number, err)
+		} else if got != test.want {
+			t.Errorf("formatNumber(%s) = %s, want %s", number, got, test.want)
+		}
+	}
+
+	for _, invalid := range []json.Number{"1e400", "-1e400"} {
+		if _, err := formatNumber(invalid); err == nil {
+			t.Errorf("formatNumber(%s) succeeded, want error", invalid)
+		}
+	}
+}
+
+// sample of RFC 8785 section 3.2.2
+func TestCanonicalizeValues(t *testing.T) {
+	input := `{
+		"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+		"string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+		"literals": [null, true, false]
+	}`
+	want := `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`
+
+	if got := canonicalizeJson(t, input); got != want {
+		t.Errorf("canonicalize() = %s, want %s", got, want)
+	}
+}
+
+// sample of RFC 8785 section 3.2.3, properties are sorted by utf-16 code units so the emoji
+// encoded as surrogate pair sorts before U+FB33
+func TestCanonicalizeSortsPropertiesByUtf16(t *testing.T) {
+	input := `{
+		"€": "Euro Sign",
+		"\r": "Carriage Return",
+		"דּ": "Hebrew Letter Dalet With Dagesh",
+		"1": "One",
+		"😀": "Emoji: Grinning Face",
+		"\u0080": "Control",
+		"ö": "Latin Small Letter O With Diaeresis"
+	}`
+	want := []string{
+		"Carriage Return",
+		"One",
+		"Control",
+		"Latin Small Letter O With Diaeresis",
+		"Euro Sign",
+		"Emoji: Grinning Face",
+		"Hebrew Letter Dalet With Dagesh",
+	}
+
+	got := canonicalizeJson(t, input)
+	last := -1
+	for _, value := range want {
+		i := strings.Index(got, `"`+value+`"`)
+		if i < last {
+			t.Fatalf("canonicalize() = %s, %q is out of order", got, value)
+		}
+		last = i
+	}
+}
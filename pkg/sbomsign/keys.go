@@ -0,0 +1,82 @@
+package sbomsign
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+)
+
+// trustedKey is parsed public key of a project trusted key
+type trustedKey struct {
+	name      string
+	subject   string // subject of certificate, empty for public keys
+	publicKey crypto.PublicKey
+}
+
+// ParsePublicKey parses PEM encoded public key or certificate. Returns certificate subject along
+// with its public key for certificates.
+func ParsePublicKey(encoded string) (crypto.PublicKey, string, error) {
+	block, _ := pem.Decode([]byte(encoded))
+	if block == nil {
+		return nil, "", fmt.Errorf("public key is not PEM encoded")
+	}
+
+	var publicKey crypto.PublicKey
+	var subject string
+	var err error
+	switch block.Type {
+	case "PUBLIC KEY":
+		publicKey, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		publicKey, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		var certificate *x509.Certificate
+		certificate, err = x509.ParseCertificate(block.Bytes)
+		if err == nil {
+			publicKey = certificate.PublicKey
+			subject = certificate.Subject.String()
+		}
+	default:
+		return nil, "", fmt.Errorf("unsupported PEM block %s", block.Type)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch publicKey.(type) {
+	case ed25519.PublicKey, *ecdsa.PublicKey, *rsa.PublicKey:
+		return publicKey, subject, nil
+	}
+
+	return nil, "", fmt.Errorf("unsupported public key type %T", publicKey)
+}
+
+// ValidateTrustedKeys returns error for trusted keys which can not be used for verification
+func ValidateTrustedKeys(keys []types.TrustedKey) error {
+	for _, key := range keys {
+		if _, _, err := ParsePublicKey(key.PublicKey); err != nil {
+			return fmt.Errorf("invalid trusted key %s: %v", key.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func parseTrustedKeys(keys []types.TrustedKey) []trustedKey {
+	parsed := []trustedKey{}
+	for _, key := range keys {
+		publicKey, subject, err := ParsePublicKey(key.PublicKey)
+		if err != nil {
+			continue
+		}
+		parsed = append(parsed, trustedKey{name: key.Name, subject: subject, publicKey: publicKey})
+	}
+
+	return parsed
+}
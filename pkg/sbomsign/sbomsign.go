@@ -0,0 +1,294 @@
+package sbomsign
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+)
+
+const (
+	schemeEd25519 = "ed25519"
+	schemeEcdsa   = "ecdsa"
+	schemeRsa     = "rsa"
+	schemeRsaPss  = "rsa-pss"
+)
+
+type algorithm struct {
+	scheme string
+	hash   crypto.Hash // zero for ed25519 which signs message itself
+}
+
+// asymmetric JSF algorithms, HMAC algorithms can not be verified using public keys
+var jsfAlgorithms = map[string]algorithm{
+	"ES256":   {schemeEcdsa, crypto.SHA256},
+	"ES384":   {schemeEcdsa, crypto.SHA384},
+	"ES512":   {schemeEcdsa, crypto.SHA512},
+	"RS256":   {schemeRsa, crypto.SHA256},
+	"RS384":   {schemeRsa, crypto.SHA384},
+	"RS512":   {schemeRsa, crypto.SHA512},
+	"PS256":   {schemeRsaPss, crypto.SHA256},
+	"PS384":   {schemeRsaPss, crypto.SHA384},
+	"PS512":   {schemeRsaPss, crypto.SHA512},
+	"Ed25519": {schemeEd25519, 0},
+}
+
+func digest(hash crypto.Hash, data []byte) []byte {
+	hasher := hash.New()
+	hasher.Write(data)
+	return hasher.Sum(nil)
+}
+
+// returns true when signature of data is valid for public key. ECDSA signatures are accepted in
+// ASN.1 DER and raw r||s (used by JSF) encodings.
+func verifyWithKey(publicKey crypto.PublicKey, alg algorithm, data, signature []byte) bool {
+	switch key := publicKey.(type) {
+	case ed25519.PublicKey:
+		return alg.scheme == schemeEd25519 && ed25519.Verify(key, data, signature)
+	case *ecdsa.PublicKey:
+		if alg.scheme != schemeEcdsa {
+			return false
+		}
+
+		hashed := digest(alg.hash, data)
+		if ecdsa.VerifyASN1(key, hashed, signature) {
+			return true
+		}
+
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		return ecdsa.Verify(key, hashed, r, s)
+	case *rsa.PublicKey:
+		switch alg.scheme {
+		case schemeRsa:
+			return rsa.VerifyPKCS1v15(key, alg.hash, digest(alg.hash, data), signature) == nil
+		case schemeRsaPss:
+			return rsa.VerifyPSS(key, alg.hash, digest(alg.hash, data), signature, nil) == nil
+		}
+	}
+
+	return false
+}
+
+// verifies signature using trusted keys and updates result with matching key
+func verifyWithTrustedKeys(result *types.SbomSignature, keys []trustedKey, alg algorithm, data, signature []byte) bool {
+	for _, key := range keys {
+		if verifyWithKey(key.publicKey, alg, data, signature) {
+			result.Verified = true
+			result.KeyName = key.name
+			if key.subject != "" {
+				result.Signer = key.subject
+			}
+			return true
+		}
+	}
+
+	return false
+}
+
+// returns algorithms which can be used with detached signatures of public key
+func getDetachedAlgorithms(publicKey crypto.PublicKey) map[string]algorithm {
+	switch publicKey.(type) {
+	case ed25519.PublicKey:
+		return map[string]algorithm{"ed25519": {schemeEd25519, 0}}
+	case *ecdsa.PublicKey:
+		return map[string]algorithm{"ecdsa-sha256": {schemeEcdsa, crypto.SHA256}}
+	case *rsa.PublicKey:
+		return map[string]algorithm{
+			"rsa-pkcs1v15-sha256": {schemeRsa, crypto.SHA256},
+			"rsa-pss-sha256":      {schemeRsaPss, crypto.SHA256},
+		}
+	}
+
+	return nil
+}
+
+// verifies detached signature of uploaded sbom file. Signature can be raw or base64 encoded.
+func verifyDetached(content, signature []byte, keys []trustedKey) *types.SbomSignature {
+	result := &types.SbomSignature{Type: types.SignatureTypeDetached, VerifiedAt: time.Now()}
+
+	signatures := [][]byte{signature}
+	if decoded, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(signature))); err == nil {
+		signatures = [][]byte{decoded, signature}
+	}
+
+	for _, key := range keys {
+		for name, alg := range getDetachedAlgorithms(key.publicKey) {
+			for _, candidate := range signatures {
+				if verifyWithTrustedKeys(result, []trustedKey{key}, alg, content, candidate) {
+					result.Algorithm = name
+					return result
+				}
+			}
+		}
+	}
+
+	result.Error = "signature does not match any trusted key"
+	return result
+}
+
+// VerifyDetached verifies detached signature of content using trusted keys. Signature can be raw
+// or base64 encoded.
+func VerifyDetached(content, signature []byte, keys []types.TrustedKey) *types.SbomSignature {
+	trustedKeys := parseTrustedKeys(keys)
+	result := verifyDetached(content, signature, trustedKeys)
+	if !result.Verified && len(trustedKeys) == 0 {
+		result.Error = "no trusted keys are configured"
+	}
+
+	return result
+}
+
+// returns copy of json object without the property
+func without(object map[string]interface{}, property string) map[string]interface{} {
+	copied := map[string]interface{}{}
+	for key, value := range object {
+		if key != property {
+			copied[key] = value
+		}
+	}
+
+	return copied
+}
+
+// verifies jsf signer using canonical form of bom whose signature is replaced by signedSignature
+func verifyJsfSigner(result *types.SbomSignature, bom map[string]interface{}, signer, signedSignature map[string]interface{}, keys []trustedKey) error {
+	algorithmName, _ := signer["algorithm"].(string)
+	alg, exists := jsfAlgorithms[algorithmName]
+	if !exists {
+		return fmt.Errorf("unsupported signature algorithm %q", algorithmName)
+	}
+
+	value, _ := signer["value"].(string)
+	signature, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+	if err != nil {
+		return fmt.Errorf("invalid signature value")
+	}
+
+	signed := without(bom, "signature")
+	signed["signature"] = signedSignature
+
+	// excluded properties are not part of signed data
+	if excludes, ok := signedSignature["excludes"].([]interface{}); ok {
+		for _, exclude := range excludes {
+			if property, ok := exclude.(string); ok && property != "signature" {
+				delete(signed, property)
+			}
+		}
+	}
+
+	data, err := canonicalize(signed)
+	if err != nil {
+		return err
+	}
+
+	if !verifyWithTrustedKeys(result, keys, alg, data, signature) {
+		return fmt.Errorf("signature does not match any trusted key")
+	}
+
+	result.Algorithm = algorithmName
+	if keyId, _ := signer["keyId"].(string); keyId != "" && result.Signer == "" {
+		result.Signer = keyId
+	}
+
+	return nil
+}
+
+// verifies jsf signature embedded in cyclonedx json sbom. Multiple signatures are verified
+// independently and chained signatures include previous signers, sbom is verified when any
+// signature is valid. Returns nil for sboms without signature.
+func verifyJsf(content []byte, keys []trustedKey) *types.SbomSignature {
+	value, err := decodeJson(content)
+	if err != nil {
+		return nil
+	}
+
+	bom, ok := value.(map[string]interface{})
+	if !ok || bom["signature"] == nil {
+		return nil
+	}
+
+	result := &types.SbomSignature{Type: types.SignatureTypeJsf, VerifiedAt: time.Now()}
+	signature, ok := bom["signature"].(map[string]interface{})
+	if !ok {
+		result.Error = "invalid signature"
+		return result
+	}
+
+	signers, multiple := signature["signers"].([]interface{})
+	chain, chained := signature["chain"].([]interface{})
+	if chained {
+		signers = chain
+	}
+
+	if !multiple && !chained {
+		if err := verifyJsfSigner(result, bom, signature, without(signature, "value"), keys); err != nil {
+			result.Error = err.Error()
+		}
+		return result
+	}
+
+	var errs []string
+	for i, item := range signers {
+		signer, ok := item.(map[string]interface{})
+		if !ok {
+			errs = append(errs, fmt.Sprintf("signer %d: invalid signer", i))
+			continue
+		}
+
+		signedSigners := []interface{}{without(signer, "value")}
+		if chained {
+			signedSigners = append(append([]interface{}{}, signers[:i]...), signedSigners...)
+		}
+
+		property := "signers"
+		if chained {
+			property = "chain"
+		}
+		signedSignature := without(signature, property)
+		signedSignature[property] = signedSigners
+
+		if err := verifyJsfSigner(result, bom, signer, signedSignature, keys); err != nil {
+			errs = append(errs, fmt.Sprintf("signer %d: %v", i, err))
+			continue
+		}
+
+		return result
+	}
+
+	result.Error = strings.Join(errs, "; ")
+	return result
+}
+
+// Verify verifies detached signature of uploaded sbom when provided, otherwise signature embedded
+// in cyclonedx json sbom using trusted keys. Returns nil when sbom is not signed.
+func Verify(content, detachedSignature []byte, keys []types.TrustedKey) *types.SbomSignature {
+	trustedKeys := parseTrustedKeys(keys)
+
+	var result *types.SbomSignature
+	if len(detachedSignature) > 0 {
+		result = verifyDetached(content, detachedSignature, trustedKeys)
+	} else {
+		result = verifyJsf(content, trustedKeys)
+	}
+
+	if result != nil && !result.Verified && len(trustedKeys) == 0 {
+		result.Error = "no trusted keys are configured"
+	}
+
+	return result
+}
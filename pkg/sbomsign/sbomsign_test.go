@@ -0,0 +1,176 @@
+package sbomsign
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+)
+
+type testSigner struct {
+	algorithm string
+	keyId     string
+	sign      func(data []byte) []byte
+	key       trustedKey
+}
+
+func newEd25519Signer(t *testing.T, keyId string) testSigner {
+	t.Helper()
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return testSigner{
+		algorithm: "Ed25519",
+		keyId:     keyId,
+		sign:      func(data []byte) []byte { return ed25519.Sign(privateKey, data) },
+		key:       trustedKey{name: keyId, publicKey: publicKey},
+	}
+}
+
+// jsf ecdsa signatures are raw r||s values
+func newEs256Signer(t *testing.T, keyId string) testSigner {
+	t.Helper()
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return testSigner{
+		algorithm: "ES256",
+		keyId:     keyId,
+		sign: func(data []byte) []byte {
+			hashed := sha256.Sum256(data)
+			r, s, err := ecdsa.Sign(rand.Reader, privateKey, hashed[:])
+			if err != nil {
+				t.Fatal(err)
+			}
+			signature := make([]byte, 64)
+			r.FillBytes(signature[:32])
+			s.FillBytes(signature[32:])
+			return signature
+		},
+		key: trustedKey{name: keyId, publicKey: &privateKey.PublicKey},
+	}
+}
+
+func (s testSigner) signer() map[string]interface{} {
+	return map[string]interface{}{"algorithm": s.algorithm, "keyId": s.keyId}
+}
+
+func newTestBom() map[string]interface{} {
+	return map[string]interface{}{
+		"bomFormat":   "CycloneDX",
+		"specVersion": "1.5",
+		"version":     1,
+		"components": []interface{}{
+			map[string]interface{}{"type": "library", "name": "lodash", "version": "4.17.21"},
+		},
+	}
+}
+
+// signs canonical form of bom with signature, value of signer must not be set yet
+func signBom(t *testing.T, bom, signature map[string]interface{}, signer testSigner, signerObject map[string]interface{}) {
+	t.Helper()
+
+	bom["signature"] = signature
+	content, err := json.Marshal(bom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, err := decodeJson(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := canonicalize(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signerObject["value"] = base64.RawURLEncoding.EncodeToString(signer.sign(data))
+}
+
+func marshalBom(t *testing.T, bom map[string]interface{}) []byte {
+	t.Helper()
+
+	content, err := json.Marshal(bom)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return content
+}
+
+func TestVerifyJsfSingle(t *testing.T) {
+	signer := newEd25519Signer(t, "release")
+	bom := newTestBom()
+	signature := signer.signer()
+	signBom(t, bom, signature, signer, signature)
+	content := marshalBom(t, bom)
+
+	result := verifyJsf(content, []trustedKey{signer.key})
+	if result == nil || !result.Verified || result.Algorithm != "Ed25519" || result.Signer != "release" {
+		t.Errorf("verifyJsf() = %+v, want verified Ed25519 signature of release", result)
+	}
+
+	bom["version"] = 2
+	if result := verifyJsf(marshalBom(t, bom), []trustedKey{signer.key}); result == nil || result.Verified {
+		t.Errorf("verifyJsf() of modified bom = %+v, want unverified", result)
+	}
+}
+
+// each signer of multiple signatures signs bom with only its own signer object
+func TestVerifyJsfMultipleSigners(t *testing.T) {
+	first := newEd25519Signer(t, "first")
+	second := newEs256Signer(t, "second")
+
+	bom := newTestBom()
+	firstObject, secondObject := first.signer(), second.signer()
+	signBom(t, bom, map[string]interface{}{"signers": []interface{}{firstObject}}, first, firstObject)
+	signBom(t, bom, map[string]interface{}{"signers": []interface{}{secondObject}}, second, secondObject)
+	bom["signature"] = map[string]interface{}{"signers": []interface{}{firstObject, secondObject}}
+	content := marshalBom(t, bom)
+
+	for _, signer := range []testSigner{first, second} {
+		result := verifyJsf(content, []trustedKey{signer.key})
+		if result == nil || !result.Verified || result.KeyName != signer.keyId || result.Algorithm != signer.algorithm {
+			t.Errorf("verifyJsf() trusting %s = %+v, want verified by %s", signer.keyId, result, signer.keyId)
+		}
+	}
+
+	if result := verifyJsf(content, []trustedKey{newEd25519Signer(t, "other").key}); result == nil || result.Verified || result.Error == "" {
+		t.Errorf("verifyJsf() trusting other key = %+v, want unverified with error", result)
+	}
+}
+
+// chained signers sign bom along with signer objects of previous signers
+func TestVerifyJsfChain(t *testing.T) {
+	first := newEs256Signer(t, "first")
+	second := newEd25519Signer(t, "second")
+
+	bom := newTestBom()
+	firstObject, secondObject := first.signer(), second.signer()
+	signBom(t, bom, map[string]interface{}{"chain": []interface{}{firstObject}}, first, firstObject)
+	signBom(t, bom, map[string]interface{}{"chain": []interface{}{firstObject, secondObject}}, second, secondObject)
+	content := marshalBom(t, bom)
+
+	for _, signer := range []testSigner{first, second} {
+		result := verifyJsf(content, []trustedKey{signer.key})
+		if result == nil || !result.Verified || result.KeyName != signer.keyId {
+			t.Errorf("verifyJsf() trusting %s = %+v, want verified by %s", signer.keyId, result, signer.keyId)
+		}
+	}
+
+	// second signature covers value of first signer
+	firstObject["value"] = base64.RawURLEncoding.EncodeToString(first.sign([]byte("other data")))
+	if result := verifyJsf(marshalBom(t, bom), []trustedKey{second.key}); result == nil || result.Verified {
+		t.Errorf("verifyJsf() with replaced first signature = %+v, want unverified", result)
+	}
+}
@@ -0,0 +1,198 @@
+package sbomvalidate
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/sbomformat"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+	"github.com/rs/zerolog/log"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// official cyclonedx json schemas, copied from https://github.com/CycloneDX/specification
+//
+//go:embed schema/*.json
+var schemaFiles embed.FS
+
+const (
+	schemaBaseUrl = "http://cyclonedx.org/schema/"
+
+	// limits response size for sboms with repeated errors
+	maxValidationErrors = 100
+)
+
+var schemaFileNames = map[string]string{
+	cyclonedx.SpecVersion1_2.String(): "bom-1.2.schema.json",
+	cyclonedx.SpecVersion1_3.String(): "bom-1.3.schema.json",
+	cyclonedx.SpecVersion1_4.String(): "bom-1.4.schema.json",
+	cyclonedx.SpecVersion1_5.String(): "bom-1.5.schema.json",
+	cyclonedx.SpecVersion1_6.String(): "bom-1.6.schema.json",
+}
+
+// schemas referenced by bom schemas
+var referencedSchemaFileNames = []string{"spdx.schema.json", "jsf-0.82.schema.json"}
+
+var (
+	schemasMu sync.Mutex
+	schemas   = map[string]*gojsonschema.Schema{}
+)
+
+// returns compiled schema of spec version, schemas are compiled once on first use
+func getSchema(specVersion string) (*gojsonschema.Schema, error) {
+	schemasMu.Lock()
+	defer schemasMu.Unlock()
+
+	if schema, exists := schemas[specVersion]; exists {
+		return schema, nil
+	}
+
+	fileName, exists := schemaFileNames[specVersion]
+	if !exists {
+		return nil, fmt.Errorf("unsupported spec version %s", specVersion)
+	}
+
+	loader := gojsonschema.NewSchemaLoader()
+	for _, referencedFileName := range referencedSchemaFileNames {
+		content, err := schemaFiles.ReadFile("schema/" + referencedFileName)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := loader.AddSchema(schemaBaseUrl+referencedFileName, gojsonschema.NewBytesLoader(content)); err != nil {
+			log.Error().Err(err).Msgf("failed to load schema %s", referencedFileName)
+			return nil, err
+		}
+	}
+
+	content, err := schemaFiles.ReadFile("schema/" + fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := loader.Compile(gojsonschema.NewBytesLoader(content))
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to compile schema %s", fileName)
+		return nil, err
+	}
+	schemas[specVersion] = schema
+
+	return schema, nil
+}
+
+// escapes json pointer reference token as per RFC 6901
+func escapePointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// converts gojsonschema error context to json pointer
+func toPointer(resultErr gojsonschema.ResultError) string {
+	// use a delimiter which can not be part of json keys to split context safely
+	tokens := strings.Split(resultErr.Context().String("\x00"), "\x00")[1:]
+
+	// point to missing or unexpected property instead of its parent object
+	switch resultErr.Type() {
+	case "required", "additional_property_not_allowed":
+		if property, ok := resultErr.Details()["property"].(string); ok {
+			tokens = append(tokens, property)
+		}
+	}
+
+	pointer := ""
+	for _, token := range tokens {
+		pointer += "/" + escapePointerToken(token)
+	}
+
+	return pointer
+}
+
+// XmlSchemaLimitation is returned along with validation result of xml sboms
+const XmlSchemaLimitation = "xml sbom is validated against cyclonedx json schema after conversion to json instead of xsd, so pointers refer to json representation and xml only constraints are not checked"
+
+// returns limitation of schema validation of the format, empty string is returned for formats
+// validated against their own schema
+func GetSchemaLimitation(format sbomformat.Format) string {
+	if format == sbomformat.CycloneDxXml {
+		return XmlSchemaLimitation
+	}
+
+	return ""
+}
+
+// ValidateSchema validates cyclonedx sbom against official json schema of its spec version.
+// XML sboms are validated against json schema of the same spec version after converting them
+// to json since xsd validation is not available without cgo, so pointers refer to json representation.
+func ValidateSchema(content []byte, format sbomformat.Format) ([]types.SbomValidationError, error) {
+	document := content
+	var specVersion string
+
+	switch format {
+	case sbomformat.CycloneDxJson:
+		var header struct {
+			SpecVersion string `json:"specVersion"`
+		}
+		if err := json.Unmarshal(content, &header); err != nil {
+			return []types.SbomValidationError{{Pointer: "", Type: types.SbomValidationSchema, Message: fmt.Sprintf("invalid json: %s", err)}}, nil
+		}
+		specVersion = header.SpecVersion
+
+	case sbomformat.CycloneDxXml:
+		bom, err := sbomformat.Decode(content, format)
+		if err != nil {
+			return []types.SbomValidationError{{Pointer: "", Type: types.SbomValidationSchema, Message: fmt.Sprintf("invalid xml: %s", err)}}, nil
+		}
+		specVersion = bom.SpecVersion.String()
+
+		if _, supported := schemaFileNames[specVersion]; supported {
+			// bomFormat is only part of json representation
+			bom.BOMFormat = cyclonedx.BOMFormat
+			buf := &bytes.Buffer{}
+			if err := cyclonedx.NewBOMEncoder(buf, cyclonedx.BOMFileFormatJSON).EncodeVersion(bom, bom.SpecVersion); err != nil {
+				log.Error().Err(err).Msg("failed to convert xml sbom to json for validation")
+				return nil, err
+			}
+			document = buf.Bytes()
+		}
+
+	default:
+		return nil, sbomformat.ErrUnknownFormat
+	}
+
+	if _, supported := schemaFileNames[specVersion]; !supported {
+		return []types.SbomValidationError{{
+			Pointer: "/specVersion",
+			Type:    types.SbomValidationSchema,
+			Message: fmt.Sprintf("unsupported spec version %q, supported versions are 1.2 to 1.6", specVersion),
+		}}, nil
+	}
+
+	schema, err := getSchema(specVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := schema.Validate(gojsonschema.NewBytesLoader(document))
+	if err != nil {
+		return []types.SbomValidationError{{Pointer: "", Type: types.SbomValidationSchema, Message: err.Error()}}, nil
+	}
+
+	validationErrs := []types.SbomValidationError{}
+	for _, resultErr := range result.Errors() {
+		if len(validationErrs) == maxValidationErrors {
+			break
+		}
+
+		validationErrs = append(validationErrs, types.SbomValidationError{
+			Pointer: toPointer(resultErr),
+			Type:    types.SbomValidationSchema,
+			Message: resultErr.Description(),
+		})
+	}
+
+	return validationErrs, nil
+}
@@ -0,0 +1,109 @@
+package sbomvalidate
+
+import (
+	"fmt"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+)
+
+type semanticValidator struct {
+	errs    []types.SbomValidationError
+	bomRefs map[string]string // bom-ref to pointer of its first definition
+}
+
+func (v *semanticValidator) addError(pointer, format string, args ...interface{}) {
+	if len(v.errs) == maxValidationErrors {
+		return
+	}
+
+	v.errs = append(v.errs, types.SbomValidationError{
+		Pointer: pointer,
+		Type:    types.SbomValidationSemantic,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *semanticValidator) addBomRef(pointer, bomRef string) {
+	if bomRef == "" {
+		return
+	}
+
+	pointer += "/bom-ref"
+	if firstPointer, exists := v.bomRefs[bomRef]; exists {
+		v.addError(pointer, "duplicate bom-ref %q, already defined at %s", bomRef, firstPointer)
+		return
+	}
+	v.bomRefs[bomRef] = pointer
+}
+
+func (v *semanticValidator) addComponents(pointer string, components *[]cyclonedx.Component) {
+	if components == nil {
+		return
+	}
+
+	for i, component := range *components {
+		componentPointer := fmt.Sprintf("%s/%d", pointer, i)
+		v.addBomRef(componentPointer, component.BOMRef)
+		v.addComponents(componentPointer+"/components", component.Components)
+	}
+}
+
+func (v *semanticValidator) addServices(pointer string, services *[]cyclonedx.Service) {
+	if services == nil {
+		return
+	}
+
+	for i, service := range *services {
+		servicePointer := fmt.Sprintf("%s/%d", pointer, i)
+		v.addBomRef(servicePointer, service.BOMRef)
+		v.addServices(servicePointer+"/services", service.Services)
+	}
+}
+
+// ValidateSemantics checks sbom constraints which are required for analysis but not enforced by
+// cyclonedx schema. Pointers of xml sboms refer to their json representation.
+func ValidateSemantics(bom *cyclonedx.BOM) []types.SbomValidationError {
+	v := &semanticValidator{
+		errs:    []types.SbomValidationError{},
+		bomRefs: map[string]string{},
+	}
+
+	// components are stored against name and version of sbom root component
+	switch {
+	case bom.Metadata == nil:
+		v.addError("/metadata", "metadata is required")
+	case bom.Metadata.Component == nil:
+		v.addError("/metadata/component", "metadata.component is required")
+	default:
+		if bom.Metadata.Component.Name == "" {
+			v.addError("/metadata/component/name", "metadata.component.name is required")
+		}
+		v.addBomRef("/metadata/component", bom.Metadata.Component.BOMRef)
+		v.addComponents("/metadata/component/components", bom.Metadata.Component.Components)
+	}
+
+	v.addComponents("/components", bom.Components)
+	v.addServices("/services", bom.Services)
+
+	if bom.Dependencies != nil {
+		for i, dependency := range *bom.Dependencies {
+			dependencyPointer := fmt.Sprintf("/dependencies/%d", i)
+			if _, exists := v.bomRefs[dependency.Ref]; !exists {
+				v.addError(dependencyPointer+"/ref", "dependency ref %q does not match any component or service bom-ref", dependency.Ref)
+			}
+
+			if dependency.Dependencies == nil {
+				continue
+			}
+
+			for j, ref := range *dependency.Dependencies {
+				if _, exists := v.bomRefs[ref]; !exists {
+					v.addError(fmt.Sprintf("%s/dependsOn/%d", dependencyPointer, j), "dependency ref %q does not match any component or service bom-ref", ref)
+				}
+			}
+		}
+	}
+
+	return v.errs
+}
@@ -0,0 +1,82 @@
+package attestation
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dmdhrumilmistry/defect-detect/pkg/config"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type AttestationHandler struct {
+	store     types.AttestationStore
+	authStore types.AuthStore
+}
+
+func NewAttestationHandler(store types.AttestationStore, authStore types.AuthStore) *AttestationHandler {
+	return &AttestationHandler{
+		store:     store,
+		authStore: authStore,
+	}
+}
+
+func (a *AttestationHandler) RegisterRoutes(r *gin.Engine) {
+	// api v1
+	r.GET("/api/v1/attestation", a.GetAttestations)
+
+	log.Info().Msg("Attestation routes registered")
+}
+
+// lists attestations, e.g. provenance of an artifact to find commit which produced it
+// curl "http://localhost:8080/api/v1/attestation?digests=sha256:<hex>&predicate_types=https://slsa.dev/provenance/v1"
+func (a *AttestationHandler) GetAttestations(c *gin.Context) {
+	// Get page and limit from query parameters
+	pageStr := c.DefaultQuery("page", "1")
+	limitStr := c.DefaultQuery("limit", "10")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page number"})
+		return
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 || limit >= 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit number"})
+		return
+	}
+
+	filter := utils.BuildDynamicContainsFilter(map[string][]string{
+		"subject_digests":       utils.Split(c.DefaultQuery("digests", ""), ","),
+		"predicate_type":        utils.Split(c.DefaultQuery("predicate_types", ""), ","),
+		"project_id":            utils.Split(c.DefaultQuery("project_ids", ""), ","),
+		"sbom_id":               utils.Split(c.DefaultQuery("sbom_ids", ""), ","),
+		"provenance.commit":     utils.Split(c.DefaultQuery("commits", ""), ","),
+		"provenance.builder_id": utils.Split(c.DefaultQuery("builder_ids", ""), ","),
+	})
+
+	attestations, err := a.store.GetAttestationsUsingFilter(filter, page, limit, config.DefaultConfig.DbQueryTimeout)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to fetch attestations")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse data"})
+		return
+	}
+
+	total, err := a.store.GetTotalCount(filter)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get total attestations")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse data"})
+		return
+	}
+
+	// Build response
+	c.JSON(http.StatusOK, gin.H{
+		"data":  attestations,
+		"page":  page,
+		"limit": limit,
+		"total": total,
+	})
+}
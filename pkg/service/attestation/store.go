@@ -0,0 +1,125 @@
+package attestation
+
+import (
+	"context"
+	"time"
+
+	"github.com/dmdhrumilmistry/defect-detect/pkg/db"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const ATTESTATION_COLLECTION = "attestation"
+
+type AttestationStore struct {
+	db         *mongo.Database
+	collection *mongo.Collection
+}
+
+func NewAttestationStore(mdb *mongo.Database) *AttestationStore {
+	collection := mdb.Collection(ATTESTATION_COLLECTION)
+
+	// attestations are looked up using artifact digests and provenance source
+	for _, keys := range []bson.D{
+		{{Key: "subject_digests", Value: 1}, {Key: "created_at", Value: -1}},
+		{{Key: "project_id", Value: 1}, {Key: "subject_digests", Value: 1}, {Key: "created_at", Value: -1}},
+		{{Key: "sbom_id", Value: 1}},
+		{{Key: "project_id", Value: 1}},
+		{{Key: "provenance.commit", Value: 1}},
+	} {
+		db.EnsureIndex(collection, mongo.IndexModel{Keys: keys})
+	}
+
+	return &AttestationStore{
+		db:         mdb,
+		collection: collection,
+	}
+}
+
+func (a *AttestationStore) AddAttestation(attestation types.Attestation) (string, error) {
+	attestation.Id = ""
+	if attestation.CreatedAt.IsZero() {
+		attestation.CreatedAt = time.Now()
+	}
+
+	result, err := a.collection.InsertOne(context.TODO(), attestation)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to insert attestation")
+		return "", err
+	}
+
+	return result.InsertedID.(primitive.ObjectID).Hex(), nil
+}
+
+func (a *AttestationStore) GetTotalCount(filter interface{}) (int64, error) {
+	// Get total count of documents
+	total, err := a.collection.CountDocuments(context.TODO(), filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// returns attestations matching filter with latest attestations first
+func (a *AttestationStore) GetAttestationsUsingFilter(filter interface{}, page, limit, duration int) ([]types.Attestation, error) {
+	var attestations []types.Attestation
+
+	// Calculate skip
+	skip := (page - 1) * limit
+
+	// MongoDB query options
+	findOptions := options.Find()
+	findOptions.SetSkip(int64(skip))
+	findOptions.SetLimit(int64(limit))
+	findOptions.SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	// Query MongoDB
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(duration)*time.Second)
+	defer cancel()
+
+	cursor, err := a.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return attestations, err
+	}
+	defer cursor.Close(ctx)
+
+	// Parse results
+	if err := cursor.All(ctx, &attestations); err != nil {
+		return attestations, err
+	}
+
+	return attestations, nil
+}
+
+// returns latest provenance of artifact having any of the digests attested to the project, nil
+// when artifact has no provenance in the project
+func (a *AttestationStore) GetProvenanceByDigests(projectId string, digests []string, duration int) (*types.Provenance, error) {
+	if len(digests) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(duration)*time.Second)
+	defer cancel()
+
+	filter := bson.M{"project_id": projectId, "subject_digests": bson.M{"$in": digests}, "provenance": bson.M{"$exists": true}}
+	findOptions := options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	var attestation types.Attestation
+	if err := a.collection.FindOne(ctx, filter, findOptions).Decode(&attestation); err == mongo.ErrNoDocuments {
+		return nil, nil
+	} else if err != nil {
+		log.Error().Err(err).Msg("failed to fetch provenance of artifact")
+		return nil, err
+	}
+
+	if attestation.Provenance != nil {
+		attestation.Provenance.AttestationId = attestation.Id
+	}
+
+	return attestation.Provenance, nil
+}
@@ -0,0 +1,190 @@
+package component
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+)
+
+// source of vulns detected by osv analyzer, distro analyzer findings carry their own sources
+var analyzerVulnSource = types.VulnSource{Origin: types.VulnOriginAnalyzer, Name: "osv"}
+
+// osv severity types of cyclonedx rating methods
+var cvssSeverityTypes = map[cyclonedx.ScoringMethod]string{
+	cyclonedx.ScoringMethodCVSSv2:  "CVSS_V2",
+	cyclonedx.ScoringMethodCVSSv3:  "CVSS_V3",
+	cyclonedx.ScoringMethodCVSSv31: "CVSS_V3",
+	cyclonedx.ScoringMethodCVSSv4:  "CVSS_V4",
+}
+
+// ordered from highest to lowest so most severe rating is used for the vuln
+var severityOrder = []cyclonedx.Severity{
+	cyclonedx.SeverityCritical,
+	cyclonedx.SeverityHigh,
+	cyclonedx.SeverityMedium,
+	cyclonedx.SeverityLow,
+}
+
+func parseVulnTime(timestamp string) time.Time {
+	parsed, _ := time.Parse(time.RFC3339, timestamp)
+	return parsed
+}
+
+// converts vulnerability present in uploaded sbom to vuln stored with components
+func newEmbeddedVuln(vulnerability cyclonedx.Vulnerability) types.Vuln {
+	source := types.VulnSource{Origin: types.VulnOriginSbom}
+	if vulnerability.Source != nil {
+		source.Name = vulnerability.Source.Name
+		source.Url = vulnerability.Source.URL
+	}
+
+	vuln := types.Vuln{
+		ID:        vulnerability.ID,
+		Summary:   vulnerability.Description,
+		Details:   vulnerability.Detail,
+		Published: parseVulnTime(vulnerability.Published),
+		Modified:  parseVulnTime(vulnerability.Updated),
+		Sources:   []types.VulnSource{source},
+	}
+
+	if vulnerability.References != nil {
+		for _, reference := range *vulnerability.References {
+			if reference.ID != "" && reference.ID != vulnerability.ID {
+				vuln.Aliases = append(vuln.Aliases, reference.ID)
+			}
+		}
+	}
+
+	if vulnerability.Ratings != nil {
+		severities := map[cyclonedx.Severity]bool{}
+		for _, rating := range *vulnerability.Ratings {
+			severities[rating.Severity] = true
+			if severityType, exists := cvssSeverityTypes[rating.Method]; exists && rating.Vector != "" {
+				vuln.CvssSeverity = append(vuln.CvssSeverity, types.CvssSeverity{TypeStr: severityType, ScoreStr: rating.Vector})
+			}
+		}
+
+		for _, severity := range severityOrder {
+			if severities[severity] {
+				vuln.GhsaDatabaseSpecific.Severity = strings.ToUpper(string(severity))
+				break
+			}
+		}
+	}
+
+	if vulnerability.CWEs != nil {
+		for _, cwe := range *vulnerability.CWEs {
+			vuln.GhsaDatabaseSpecific.CweIds = append(vuln.GhsaDatabaseSpecific.CweIds, fmt.Sprintf("CWE-%d", cwe))
+		}
+	}
+
+	if vulnerability.Advisories != nil {
+		for _, advisory := range *vulnerability.Advisories {
+			if advisory.URL != "" {
+				vuln.References = append(vuln.References, types.References{Type: "ADVISORY", URL: advisory.URL})
+			}
+		}
+	}
+
+	return vuln
+}
+
+// returns vulns present in uploaded sbom indexed by bom-ref of affected components
+func getEmbeddedVulns(sbom types.Sbom) map[string][]types.Vuln {
+	embeddedVulns := map[string][]types.Vuln{}
+	if sbom.Vulnerabilities == nil {
+		return embeddedVulns
+	}
+
+	for _, vulnerability := range *sbom.Vulnerabilities {
+		if vulnerability.ID == "" || vulnerability.Affects == nil {
+			continue
+		}
+
+		vuln := newEmbeddedVuln(vulnerability)
+		for _, affects := range *vulnerability.Affects {
+			if affects.Ref != "" {
+				embeddedVulns[affects.Ref] = append(embeddedVulns[affects.Ref], vuln)
+			}
+		}
+	}
+
+	return embeddedVulns
+}
+
+// returns true when vulns share id or any alias
+func vulnsMatch(a, b types.Vuln) bool {
+	aIds := append([]string{a.ID}, a.Aliases...)
+	for _, bId := range append([]string{b.ID}, b.Aliases...) {
+		for _, aId := range aIds {
+			if aId != "" && aId == bId {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// merges vulns present in uploaded sbom with analyzer findings. Findings reported by both keep
+// analyzer details and record sbom as additional source.
+func mergeEmbeddedVulns(vulns, embeddedVulns []types.Vuln) []types.Vuln {
+	for i := range vulns {
+		if len(vulns[i].Sources) == 0 {
+			vulns[i].Sources = []types.VulnSource{analyzerVulnSource}
+		}
+	}
+
+	for _, embeddedVuln := range embeddedVulns {
+		matched := false
+		for i := range vulns {
+			if vulnsMatch(vulns[i], embeddedVuln) {
+				vulns[i].Sources = append(vulns[i].Sources, embeddedVuln.Sources...)
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			vulns = append(vulns, embeddedVuln)
+		}
+	}
+
+	return vulns
+}
+
+// marks vulns resolved by pedigree patches of component, such as backported fixes of distro packages
+func applyPedigreePatches(component *cyclonedx.Component, vulns []types.Vuln) {
+	if component == nil || component.Pedigree == nil || component.Pedigree.Patches == nil {
+		return
+	}
+
+	for _, patch := range *component.Pedigree.Patches {
+		if patch.Resolves == nil {
+			continue
+		}
+
+		for _, issue := range *patch.Resolves {
+			if issue.ID == "" {
+				continue
+			}
+
+			for i := range vulns {
+				if vulns[i].Patch != nil || !vulnsMatch(vulns[i], types.Vuln{ID: issue.ID}) {
+					continue
+				}
+
+				vulns[i].Patch = &types.VulnPatch{
+					Type:    string(patch.Type),
+					IssueId: issue.ID,
+				}
+				if patch.Diff != nil && patch.Diff.URL != "" {
+					vulns[i].Patch.DiffUrl = patch.Diff.URL
+				}
+			}
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package component
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -16,13 +17,15 @@ import (
 type ComponentHandler struct {
 	store     types.ComponentStore
 	sbomStore types.SbomStore
+	jobStore  types.JobStore
 	authStore types.AuthStore
 }
 
-func NewComponentHandler(store types.ComponentStore, sbomStore types.SbomStore, authStore types.AuthStore) *ComponentHandler {
+func NewComponentHandler(store types.ComponentStore, sbomStore types.SbomStore, jobStore types.JobStore, authStore types.AuthStore) *ComponentHandler {
 	return &ComponentHandler{
 		store:     store,
 		sbomStore: sbomStore,
+		jobStore:  jobStore,
 		authStore: authStore,
 	}
 }
@@ -30,6 +33,7 @@ func NewComponentHandler(store types.ComponentStore, sbomStore types.SbomStore,
 func (s *ComponentHandler) RegisterRoutes(r *gin.Engine) {
 	// api v1
 	r.POST("/api/v1/component", s.AddComponentUsingSbomId)
+	r.POST("/api/v1/component/retry", s.RetryFailedComponents)
 	r.GET("/api/v1/component", s.GetComponents)
 	r.GET("/api/v1/component/:id", s.GetComponentById)
 	r.GET("/api/v1/component/getByName", s.GetComponentByName)
@@ -37,6 +41,7 @@ func (s *ComponentHandler) RegisterRoutes(r *gin.Engine) {
 	log.Info().Msg("Component routes registered")
 }
 
+// queues sbom component analysis job and returns job id
 // curl -X POST "http://localhost:8080/api/v1/component?sbom_id=676852a1af6020598db6e8d6"
 func (s *ComponentHandler) AddComponentUsingSbomId(c *gin.Context) {
 	sbomId, exists := c.GetQuery("sbom_id")
@@ -56,13 +61,96 @@ func (s *ComponentHandler) AddComponentUsingSbomId(c *gin.Context) {
 		return
 	}
 
-	Ids, err := s.store.AddComponentUsingSbom(sbom)
+	// return existing job if sbom is already being analyzed
+	job, err := s.jobStore.GetActiveJobBySbomId(sbom.Id, config.DefaultConfig.DbQueryTimeout)
+	if err == nil {
+		c.JSON(http.StatusAccepted, gin.H{"message": "Sbom analysis is already queued", "job_id": job.Id})
+		return
+	} else if err != mongo.ErrNoDocuments {
+		log.Error().Err(err).Msgf("failed to fetch active jobs for sbom %s", sbom.Id)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to queue sbom analysis"})
+		return
+	}
+
+	// sboms whose analysis finished, even with failed components, are not analyzed again and 409 is
+	// returned. Failed components are re-analyzed using retry, while analysis interrupted before it
+	// finished has no sbom analysis yet and is resumed from stored components.
+	if sbom.Analysis != nil {
+		resp := gin.H{"error": "sbom is already processed", "analysis": sbom.Analysis}
+		if sbom.Analysis.FailedComponents > 0 {
+			resp["hint"] = "use /api/v1/component/retry to re-analyze failed components"
+		}
+		c.JSON(http.StatusConflict, resp)
+		return
+	}
+
+	jobId, err := s.jobStore.AddJob(types.Job{
+		Type:   types.JobTypeAnalyzeSbom,
+		SbomId: sbom.Id,
+	})
+	if errors.Is(err, types.ErrActiveJobExists) {
+		c.JSON(http.StatusAccepted, gin.H{"message": "Sbom analysis is already queued", "job_id": jobId})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to queue sbom analysis"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Sbom analysis queued successfully", "job_id": jobId})
+}
+
+// queues job to re-analyze only those sbom components which failed analysis
+// curl -X POST "http://localhost:8080/api/v1/component/retry?sbom_id=676852a1af6020598db6e8d6"
+func (s *ComponentHandler) RetryFailedComponents(c *gin.Context) {
+	sbomId, exists := c.GetQuery("sbom_id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "invalid request",
+		})
+		return
+	}
+
+	sbom, err := s.sbomStore.GetSbomById(sbomId, config.DefaultConfig.DbQueryTimeout)
+	if err == mongo.ErrNoDocuments {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch item"})
+		return
+	}
+
+	job, err := s.jobStore.GetActiveJobBySbomId(sbom.Id, config.DefaultConfig.DbQueryTimeout)
+	if err == nil {
+		c.JSON(http.StatusAccepted, gin.H{"message": "Sbom analysis is already queued", "job_id": job.Id})
+		return
+	} else if err != mongo.ErrNoDocuments {
+		log.Error().Err(err).Msgf("failed to fetch active jobs for sbom %s", sbom.Id)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to queue retry of failed components"})
+		return
+	}
+
+	analysis, err := s.store.GetSbomAnalysis(sbom.Id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to add components from sbom or sbom is already processed"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to queue retry of failed components"})
+		return
+	} else if analysis.FailedComponents == 0 {
+		c.JSON(http.StatusOK, gin.H{"message": "sbom does not have failed components", "analysis": analysis})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Components created successfully from Sbom", "ids": Ids})
+	jobId, err := s.jobStore.AddJob(types.Job{
+		Type:   types.JobTypeRetryFailedComponents,
+		SbomId: sbom.Id,
+	})
+	if errors.Is(err, types.ErrActiveJobExists) {
+		c.JSON(http.StatusAccepted, gin.H{"message": "Sbom analysis is already queued", "job_id": jobId})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to queue retry of failed components"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Retry of failed components queued successfully", "job_id": jobId, "analysis": analysis})
 }
 
 // curl "http://localhost:8080/api/v1/component?page=1&limit=10"
@@ -176,7 +264,14 @@ func (s *ComponentHandler) GetVulnerableComponents(c *gin.Context) {
 	versions := utils.Split(c.DefaultQuery("versions", ""), ",")
 	purls := utils.Split(c.DefaultQuery("purls", ""), ",")
 
-	vulnComps, total, err := s.store.GetVulnerableComponents(componentNames, componentVersions, sbomIds, compTypes, compNames, purls, versions, page, limit, config.DefaultConfig.DbQueryTimeout)
+	// vulns suppressed by vex statements are hidden by default
+	includeSuppressed, err := strconv.ParseBool(c.DefaultQuery("include_suppressed", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid include_suppressed value"})
+		return
+	}
+
+	vulnComps, total, err := s.store.GetVulnerableComponents(componentNames, componentVersions, sbomIds, compTypes, compNames, purls, versions, includeSuppressed, page, limit, config.DefaultConfig.DbQueryTimeout)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to get vulnerable components")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch vulnerable components"})
@@ -2,12 +2,17 @@ package component
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/CycloneDX/cyclonedx-go"
+	anz "github.com/dmdhrumilmistry/defect-detect/pkg/analyzer"
 	"github.com/dmdhrumilmistry/defect-detect/pkg/config"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/db"
 	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
 	"github.com/dmdhrumilmistry/defect-detect/pkg/utils"
 	"github.com/rs/zerolog/log"
@@ -20,42 +25,198 @@ import (
 const COMPONENT_COLLECTION = "component"
 
 type vulnResult struct {
+	Work      componentWork
 	Component types.Component
 	Err       error
 }
+
+// componentWork is a single sbom component or service queued for analysis
+type componentWork struct {
+	Component    *cyclonedx.Component
+	Service      *cyclonedx.Service
+	Stored       *types.Component   // previously stored component which is re-analyzed
+	Ref          string             // position of component in sbom, used as checkpoint key
+	ParentRef    string             // ref of parent component or service
+	ParentBomRef string             // bom-ref of parent component or service
+	ObjectId     primitive.ObjectID // id of stored component to replace, zero value if component isn't stored yet
+}
+
+const SERVICE_COMPONENT_TYPE = "service"
+
 type ComponentStore struct {
 	db         *mongo.Database
 	collection *mongo.Collection
 	Analyzer   types.Analyzer
+	vexStore   types.VexStore
 }
 
-func NewComponentStore(db *mongo.Database, analyzer types.Analyzer) *ComponentStore {
-	collection := db.Collection(COMPONENT_COLLECTION)
-	// TODO: create index if not exists
+func NewComponentStore(mdb *mongo.Database, analyzer types.Analyzer, vexStore types.VexStore) *ComponentStore {
+	collection := mdb.Collection(COMPONENT_COLLECTION)
+
+	// components are mostly fetched using their sbom
+	db.EnsureIndex(collection, mongo.IndexModel{
+		Keys: bson.D{{Key: "sbom_id", Value: 1}},
+	})
+
+	// used by component search
+	for _, keys := range []bson.D{
+		{{Key: "name", Value: 1}},
+		{{Key: "purl", Value: 1}},
+		{{Key: "hashes.value", Value: 1}},
+		{{Key: "supplier", Value: 1}},
+	} {
+		db.EnsureIndex(collection, mongo.IndexModel{Keys: keys})
+	}
+
+	// used as checkpoint while streaming analysis results
+	db.EnsureIndex(collection, mongo.IndexModel{
+		Keys: bson.D{{Key: "sbom_id", Value: 1}, {Key: "ref", Value: 1}},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+			"ref": bson.M{"$exists": true},
+		}),
+	})
 
 	return &ComponentStore{
-		db:         db,
+		db:         mdb,
 		collection: collection,
 		Analyzer:   analyzer,
+		vexStore:   vexStore,
+	}
+}
+
+// returns license ids of cyclonedx licenses
+func getLicenseIds(licenses *cyclonedx.Licenses) []string {
+	var licenseIds []string
+	if licenses == nil {
+		return licenseIds
+	}
+
+	for _, license := range *licenses {
+		if license.License != nil && license.License.ID != "" {
+			licenseIds = append(licenseIds, license.License.ID)
+		}
 	}
+
+	return licenseIds
 }
 
-func (c *ComponentStore) processComponentsWorker(sbom types.Sbom, componentName, componentVersion string, wg *sync.WaitGroup, workCh <-chan *cyclonedx.Component, resultCh chan vulnResult) {
+// returns purl of a service. Services don't have purl field so it is read from
+// bom-ref or "purl" property of the service
+func getServicePurl(service *cyclonedx.Service) string {
+	if strings.HasPrefix(service.BOMRef, "pkg:") {
+		return service.BOMRef
+	}
+
+	if service.Properties != nil {
+		for _, property := range *service.Properties {
+			if property.Name == "purl" && strings.HasPrefix(property.Value, "pkg:") {
+				return property.Value
+			}
+		}
+	}
+
+	return ""
+}
+
+// returns hashes of cyclonedx component with lower case digests
+func getComponentHashes(hashes *[]cyclonedx.Hash) []types.ComponentHash {
+	var componentHashes []types.ComponentHash
+	if hashes == nil {
+		return componentHashes
+	}
+
+	for _, hash := range *hashes {
+		componentHashes = append(componentHashes, types.ComponentHash{
+			Algorithm: string(hash.Algorithm),
+			Value:     strings.ToLower(hash.Value),
+		})
+	}
+
+	return componentHashes
+}
+
+// builds component to be stored from queued work without analysis results
+func newComponentFromWork(work componentWork) types.Component {
+	switch {
+	case work.Stored != nil:
+		component := *work.Stored
+		component.Id = ""
+		component.Vulns = nil
+		component.PackageInfos = nil
+		component.AnalyzerErrors = nil
+		return component
+	case work.Service != nil:
+		service := work.Service
+		details := &types.ServiceDetails{
+			Group:                service.Group,
+			Description:          service.Description,
+			Authenticated:        service.Authenticated,
+			CrossesTrustBoundary: service.CrossesTrustBoundary,
+		}
+		if service.Provider != nil {
+			details.Provider = service.Provider.Name
+		}
+		if service.Endpoints != nil {
+			details.Endpoints = *service.Endpoints
+		}
+		if service.Data != nil {
+			for _, data := range *service.Data {
+				details.DataClassifications = append(details.DataClassifications, types.DataClassification{
+					Flow:           string(data.Flow),
+					Classification: data.Classification,
+				})
+			}
+		}
+
+		component := types.Component{
+			Name:         service.Name,
+			Version:      service.Version,
+			Licenses:     getLicenseIds(service.Licenses),
+			Type:         SERVICE_COMPONENT_TYPE,
+			Ref:          work.Ref,
+			BomRef:       service.BOMRef,
+			ParentRef:    work.ParentRef,
+			ParentBomRef: work.ParentBomRef,
+			Service:      details,
+			Supplier:     details.Provider,
+		}
+
+		// only versioned services can be matched against vulns
+		if service.Version != "" {
+			component.PackageUrl = getServicePurl(service)
+		}
+
+		return component
+	default:
+		var supplier string
+		if work.Component.Supplier != nil {
+			supplier = work.Component.Supplier.Name
+		}
+
+		return types.Component{
+			Hashes:       getComponentHashes(work.Component.Hashes),
+			Supplier:     supplier,
+			Name:         work.Component.Name,
+			Version:      work.Component.Version,
+			PackageUrl:   work.Component.PackageURL,
+			Licenses:     getLicenseIds(work.Component.Licenses),
+			Type:         string(work.Component.Type),
+			Ref:          work.Ref,
+			BomRef:       work.Component.BOMRef,
+			ParentRef:    work.ParentRef,
+			ParentBomRef: work.ParentBomRef,
+		}
+	}
+}
+
+func (c *ComponentStore) processComponentsWorker(sbom types.Sbom, embeddedVulns map[string][]types.Vuln, componentName, componentVersion string, wg *sync.WaitGroup, workCh <-chan componentWork, resultCh chan vulnResult) {
 	defer wg.Done()
-	for component := range workCh {
-		var licences []string
+	for work := range workCh {
+		component := newComponentFromWork(work)
 		var vulns []types.Vuln
 		var pkgInfos []types.PackageInfo
 		var vulnErr, pkgInfoErr error
 
-		if component.Licenses != nil {
-			for _, license := range *component.Licenses {
-				if license.License != nil && license.License.ID != "" {
-					licences = append(licences, license.License.ID)
-				}
-			}
-		}
-
 		var innerWg sync.WaitGroup
 		// Channel to collect errors
 		errCh := make(chan error, 2)
@@ -64,14 +225,15 @@ func (c *ComponentStore) processComponentsWorker(sbom types.Sbom, componentName,
 		innerWg.Add(1)
 		go func() {
 			defer innerWg.Done()
-			if component.PackageURL != "" {
-				log.Info().Msgf("Processing vulns for purl %s", component.PackageURL)
-				vulns, vulnErr = c.Analyzer.GetVulns(component.PackageURL)
+			// unpinned packages such as requirements with version ranges are not analyzed for vulns
+			if component.PackageUrl != "" && utils.PurlHasVersion(component.PackageUrl) {
+				log.Info().Msgf("Processing vulns for purl %s", component.PackageUrl)
+				vulns, vulnErr = c.Analyzer.GetVulns(component.PackageUrl)
 				if vulnErr != nil {
-					log.Error().Err(vulnErr).Msgf("failed to analyze vulns for %s", component.PackageURL)
+					log.Error().Err(vulnErr).Msgf("failed to analyze vulns for %s", component.PackageUrl)
 					errCh <- vulnErr
 				} else {
-					log.Info().Msgf("Detected %d vulns for purl: %s", len(vulns), component.PackageURL)
+					log.Info().Msgf("Detected %d vulns for purl: %s", len(vulns), component.PackageUrl)
 				}
 			}
 		}()
@@ -80,10 +242,12 @@ func (c *ComponentStore) processComponentsWorker(sbom types.Sbom, componentName,
 		innerWg.Add(1)
 		go func() {
 			defer innerWg.Done()
-			pkgInfos, pkgInfoErr = c.Analyzer.GetPackageInfo(component.PackageURL)
-			if pkgInfoErr != nil {
-				log.Error().Err(pkgInfoErr).Msgf("failed to fetch package info for purl: %s", component.PackageURL)
-				errCh <- pkgInfoErr
+			if component.PackageUrl != "" {
+				pkgInfos, pkgInfoErr = c.Analyzer.GetPackageInfo(component.PackageUrl)
+				if pkgInfoErr != nil {
+					log.Error().Err(pkgInfoErr).Msgf("failed to fetch package info for purl: %s", component.PackageUrl)
+					errCh <- pkgInfoErr
+				}
 			}
 		}()
 
@@ -92,55 +256,108 @@ func (c *ComponentStore) processComponentsWorker(sbom types.Sbom, componentName,
 		close(errCh)
 
 		// Aggregate errors
-		var combinedErr error
+		var errs []error
 		for err := range errCh {
-			if combinedErr == nil {
-				combinedErr = err
-			} else {
-				combinedErr = fmt.Errorf("%v; %w", combinedErr, err)
-			}
+			errs = append(errs, err)
 		}
+		combinedErr := errors.Join(errs...)
+
+		now := time.Now()
+		component.ComponentName = componentName
+		component.ComponentVersion = componentVersion
+		component.ComponentPurl = getSbomRootPurl(sbom)
+		component.SbomId = sbom.Id
+		if component.BomRef != "" {
+			vulns = mergeEmbeddedVulns(vulns, embeddedVulns[component.BomRef])
+		}
+		applyPedigreePatches(work.Component, vulns)
+		component.Vulns = vulns
+		c.applyStoredVexStatements(&component)
+		component.PackageInfos = pkgInfos
+		component.AnalyzerErrors = anz.ToAnalyzerErrors(combinedErr, now)
+		component.AnalyzedAt = now
 
 		// Send the result back
 		resultCh <- vulnResult{
-			Component: types.Component{
-				Name:             component.Name,
-				Version:          component.Version,
-				PackageUrl:       component.PackageURL,
-				Licenses:         licences,
-				Type:             string(component.Type),
-				ComponentName:    componentName,
-				ComponentVersion: componentVersion,
-				Vulns:            vulns,
-				SbomId:           sbom.Id,
-				PackageInfos:     pkgInfos,
-			},
-			Err: combinedErr,
+			Work:      work,
+			Component: component,
+			Err:       combinedErr,
 		}
 	}
 }
 
-func (c *ComponentStore) processComponents(sbom types.Sbom, componentName, componentVersion string, workers int) []interface{} {
-	var components []interface{}
+// writes analyzed components to db. Components are upserted using their checkpoint ref
+// or replaced using id so that resumed analysis doesn't create duplicate components
+func (c *ComponentStore) writeComponents(results []vulnResult) error {
+	var models []mongo.WriteModel
+	for _, result := range results {
+		var filter bson.M
+		if !result.Work.ObjectId.IsZero() {
+			filter = bson.M{"_id": result.Work.ObjectId}
+		} else {
+			filter = bson.M{"sbom_id": result.Component.SbomId, "ref": result.Component.Ref}
+		}
+
+		models = append(models, mongo.NewReplaceOneModel().SetFilter(filter).SetReplacement(result.Component).SetUpsert(true))
+	}
+
+	_, err := c.collection.BulkWrite(context.TODO(), models, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+// processComponents analyzes components using workers and streams results to db in chunks of batchSize.
+// At most maxInFlight components are being analyzed or waiting to be written at any time.
+// Returns number of components stored in db.
+func (c *ComponentStore) processComponents(ctx context.Context, sbom types.Sbom, items []componentWork, componentName, componentVersion string, workers int, progress types.ProgressFunc) (int, error) {
+	var processed, failed, stored int
+	total := len(items)
+
+	batchSize := config.DefaultConfig.AnalysisBatchSize
+	maxInFlight := config.DefaultConfig.AnalysisMaxInFlight
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	if maxInFlight < batchSize {
+		maxInFlight = batchSize
+	}
+
+	// stop feeding workers once ctx is cancelled or write to db fails
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
 	// Channels for work distribution and results collection
-	workCh := make(chan *cyclonedx.Component)
+	workCh := make(chan componentWork)
 	resultCh := make(chan vulnResult)
+	inFlight := make(chan struct{}, maxInFlight)
+
+	// vulns present in uploaded sbom are merged with analyzer findings
+	embeddedVulns := getEmbeddedVulns(sbom)
 
 	// Start workers
 	var wg sync.WaitGroup
 	for i := 0; i < workers; i++ {
 		wg.Add(1)
 		// go worker(&wg)
-		go c.processComponentsWorker(sbom, componentName, componentVersion, &wg, workCh, resultCh)
+		go c.processComponentsWorker(sbom, embeddedVulns, componentName, componentVersion, &wg, workCh, resultCh)
 	}
 
-	// Send components to work channel
+	// Send components to work channel, blocks while maxInFlight components are pending
 	go func() {
-		for _, component := range *sbom.Components {
-			workCh <- &component
+		defer close(workCh)
+		for _, item := range items {
+			select {
+			case inFlight <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+
+			select {
+			case workCh <- item:
+			case <-ctx.Done():
+				<-inFlight
+				return
+			}
 		}
-		close(workCh)
 	}()
 
 	// Collect results
@@ -149,11 +366,111 @@ func (c *ComponentStore) processComponents(sbom types.Sbom, componentName, compo
 		close(resultCh)
 	}()
 
+	var writeErr error
+	batch := make([]vulnResult, 0, batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+
+		if writeErr == nil {
+			if err := c.writeComponents(batch); err != nil {
+				log.Error().Err(err).Msgf("failed to write analyzed components of sbom %s", sbom.Id)
+				writeErr = err
+				cancel()
+			} else {
+				stored += len(batch)
+			}
+		}
+
+		// release in flight slots once batch is written
+		for range batch {
+			<-inFlight
+		}
+		batch = batch[:0]
+	}
+
 	for result := range resultCh {
-		components = append(components, result.Component)
+		batch = append(batch, result)
+
+		processed++
+		if result.Err != nil {
+			failed++
+		}
+
+		if len(batch) >= batchSize {
+			flush()
+		}
+		if progress != nil {
+			progress(processed, failed, total)
+		}
+	}
+	flush()
+
+	if writeErr != nil {
+		return stored, writeErr
+	}
+
+	return stored, ctx.Err()
+}
+
+// appends work items for components and their nested components
+func appendComponentWork(items []componentWork, components *[]cyclonedx.Component, parentRef, parentBomRef string) []componentWork {
+	if components == nil {
+		return items
+	}
+
+	for i := range *components {
+		component := &(*components)[i]
+		ref := strconv.Itoa(i)
+		if parentRef != "" {
+			ref = parentRef + "/" + ref
+		}
+
+		items = append(items, componentWork{
+			Component:    component,
+			Ref:          ref,
+			ParentRef:    parentRef,
+			ParentBomRef: parentBomRef,
+		})
+		items = appendComponentWork(items, component.Components, ref, component.BOMRef)
+	}
+
+	return items
+}
+
+// appends work items for services and their nested services
+func appendServiceWork(items []componentWork, services *[]cyclonedx.Service, parentRef, parentBomRef string) []componentWork {
+	if services == nil {
+		return items
+	}
+
+	for i := range *services {
+		service := &(*services)[i]
+		ref := "s" + strconv.Itoa(i)
+		if parentRef != "" {
+			ref = parentRef + "/" + ref
+		}
+
+		items = append(items, componentWork{
+			Service:      service,
+			Ref:          ref,
+			ParentRef:    parentRef,
+			ParentBomRef: parentBomRef,
+		})
+		items = appendServiceWork(items, service.Services, ref, service.BOMRef)
 	}
 
-	return components
+	return items
+}
+
+// returns work items for all sbom components including nested components and services
+func getSbomComponents(sbom types.Sbom) []componentWork {
+	var items []componentWork
+	items = appendComponentWork(items, sbom.Components, "", "")
+	items = appendServiceWork(items, sbom.Services, "", "")
+
+	return items
 }
 
 func (c *ComponentStore) IsSbomProcessed(sbomId string) bool {
@@ -167,30 +484,225 @@ func (c *ComponentStore) IsSbomProcessed(sbomId string) bool {
 	return doc_count != 0
 }
 
-func (c *ComponentStore) AddComponentUsingSbom(sbom types.Sbom) ([]string, error) {
-	componentName := sbom.Metadata.Component.Name
-	componentVersion := sbom.Metadata.Component.Version
-	insertedIds := []string{}
+// returns checkpoint refs of already stored sbom components
+func (c *ComponentStore) getStoredRefs(ctx context.Context, sbomId string) (map[string]bool, error) {
+	refs := map[string]bool{}
+
+	cursor, err := c.collection.Find(ctx, bson.M{"sbom_id": sbomId}, options.Find().SetProjection(bson.M{"ref": 1}))
+	if err != nil {
+		return refs, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var component types.Component
+		if err := cursor.Decode(&component); err != nil {
+			return refs, err
+		}
 
-	if c.IsSbomProcessed(sbom.Id) {
-		return insertedIds, fmt.Errorf("sbom is already processed")
+		if component.Ref == "" {
+			// components analyzed before checkpointing was introduced
+			return refs, fmt.Errorf("sbom is already processed")
+		}
+		refs[component.Ref] = true
 	}
 
-	components := c.processComponents(sbom, componentName, componentVersion, config.DefaultConfig.DefaultWorkersCount)
+	return refs, cursor.Err()
+}
+
+// returns name and version of sbom root component which analyzed components are stored against
+func getSbomRootComponent(sbom types.Sbom) (string, string, error) {
+	if sbom.Metadata == nil || sbom.Metadata.Component == nil {
+		// sboms stored before uploads were validated
+		return "", "", fmt.Errorf("sbom %s does not have metadata.component", sbom.Id)
+	}
 
-	results, err := c.collection.InsertMany(context.TODO(), components)
+	return sbom.Metadata.Component.Name, sbom.Metadata.Component.Version, nil
+}
+
+func (c *ComponentStore) AddComponentUsingSbom(sbom types.Sbom) ([]string, error) {
+	insertedIds := []string{}
+	if _, err := c.AnalyzeComponentsUsingSbom(context.TODO(), sbom, nil); err != nil {
+		return insertedIds, err
+	}
+
+	cursor, err := c.collection.Find(context.TODO(), bson.M{"sbom_id": sbom.Id}, options.Find().SetProjection(bson.M{"_id": 1}))
 	if err != nil {
-		log.Error().Err(err).Msg("failed to insert")
 		return insertedIds, err
 	}
 
-	for _, insertedId := range results.InsertedIDs {
-		insertedIds = append(insertedIds, insertedId.(primitive.ObjectID).Hex())
+	var components []types.Component
+	if err := cursor.All(context.TODO(), &components); err != nil {
+		return insertedIds, err
+	}
+
+	for _, component := range components {
+		insertedIds = append(insertedIds, component.Id)
 	}
 
 	return insertedIds, nil
 }
 
+// analyzes sbom components and streams results to db. Components stored by a previous
+// interrupted run of the same sbom are skipped. Returns number of components stored.
+func (c *ComponentStore) AnalyzeComponentsUsingSbom(ctx context.Context, sbom types.Sbom, progress types.ProgressFunc) (int, error) {
+	componentName, componentVersion, err := getSbomRootComponent(sbom)
+	if err != nil {
+		return 0, err
+	}
+
+	storedRefs, err := c.getStoredRefs(ctx, sbom.Id)
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to fetch analysis checkpoint of sbom %s", sbom.Id)
+		return 0, err
+	}
+
+	var items []componentWork
+	for _, item := range getSbomComponents(sbom) {
+		if !storedRefs[item.Ref] {
+			items = append(items, item)
+		}
+	}
+
+	if len(storedRefs) > 0 {
+		log.Info().Msgf("Resuming analysis of sbom %s, %d components already analyzed, %d remaining", sbom.Id, len(storedRefs), len(items))
+	}
+
+	// report progress including components analyzed by previous runs
+	resumeProgress := progress
+	if progress != nil {
+		resumeProgress = func(processed, failed, total int) {
+			progress(processed+len(storedRefs), failed, total+len(storedRefs))
+		}
+	}
+
+	return c.processComponents(ctx, sbom, items, componentName, componentVersion, config.DefaultConfig.DefaultWorkersCount, resumeProgress)
+}
+
+// re-analyzes sbom components and replaces previously stored components of the sbom in place
+func (c *ComponentStore) RescanComponentsUsingSbom(ctx context.Context, sbom types.Sbom) (int, error) {
+	componentName, componentVersion, err := getSbomRootComponent(sbom)
+	if err != nil {
+		return 0, err
+	}
+	startedAt := time.Now()
+
+	// stored components are replaced as results arrive so that findings are available while rescan is in progress
+	stored, err := c.processComponents(ctx, sbom, getSbomComponents(sbom), componentName, componentVersion, config.DefaultConfig.DefaultWorkersCount, nil)
+	if err != nil {
+		return stored, err
+	}
+
+	// remove components which were not replaced by this rescan
+	staleFilter := bson.M{
+		"sbom_id": sbom.Id,
+		"$or": []bson.M{
+			{"analyzed_at": bson.M{"$lt": startedAt}},
+			{"analyzed_at": bson.M{"$exists": false}},
+		},
+	}
+	deleteResult, err := c.collection.DeleteMany(ctx, staleFilter)
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to delete previously analyzed components for sbom %s", sbom.Id)
+		return stored, err
+	}
+	log.Info().Msgf("Removed %d stale components for sbom %s", deleteResult.DeletedCount, sbom.Id)
+
+	return stored, nil
+}
+
+// filter matching sbom components which failed analysis
+func getFailedComponentsFilter(sbomId string) bson.M {
+	return bson.M{
+		"sbom_id":           sbomId,
+		"analyzer_errors.0": bson.M{"$exists": true},
+	}
+}
+
+// re-analyzes sbom components which failed analysis and replaces them with new results
+func (c *ComponentStore) RetryFailedComponents(ctx context.Context, sbom types.Sbom, progress types.ProgressFunc) (int, error) {
+	componentName, componentVersion, err := getSbomRootComponent(sbom)
+	if err != nil {
+		return 0, err
+	}
+
+	cursor, err := c.collection.Find(ctx, getFailedComponentsFilter(sbom.Id))
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to fetch failed components of sbom %s", sbom.Id)
+		return 0, err
+	}
+
+	var failedComponents []types.Component
+	if err := cursor.All(ctx, &failedComponents); err != nil {
+		log.Error().Err(err).Msgf("failed to parse failed components of sbom %s", sbom.Id)
+		return 0, err
+	}
+
+	if len(failedComponents) == 0 {
+		log.Info().Msgf("sbom %s does not have any failed components", sbom.Id)
+		return 0, nil
+	}
+
+	// sbom components are required to apply their pedigree patches
+	sbomComponents := map[string]*cyclonedx.Component{}
+	for _, item := range getSbomComponents(sbom) {
+		if item.Component != nil {
+			sbomComponents[item.Ref] = item.Component
+		}
+	}
+
+	// re-analyze stored components in place
+	var items []componentWork
+	for i, failedComponent := range failedComponents {
+		objectId, err := primitive.ObjectIDFromHex(failedComponent.Id)
+		if err != nil {
+			log.Error().Err(err).Msgf("invalid component id %s", failedComponent.Id)
+			continue
+		}
+
+		items = append(items, componentWork{
+			Component: sbomComponents[failedComponent.Ref],
+			Stored:    &failedComponents[i],
+			Ref:       failedComponent.Ref,
+			ObjectId:  objectId,
+		})
+	}
+
+	return c.processComponents(ctx, sbom, items, componentName, componentVersion, config.DefaultConfig.DefaultWorkersCount, progress)
+}
+
+// returns analysis completeness of sbom using stored components
+func (c *ComponentStore) GetSbomAnalysis(sbomId string) (types.SbomAnalysis, error) {
+	analysis := types.SbomAnalysis{
+		AnalyzedAt: time.Now(),
+	}
+
+	total, err := c.GetComponentTotalCount(bson.M{"sbom_id": sbomId})
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to count components of sbom %s", sbomId)
+		return analysis, err
+	}
+
+	failed, err := c.GetComponentTotalCount(getFailedComponentsFilter(sbomId))
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to count failed components of sbom %s", sbomId)
+		return analysis, err
+	}
+
+	analysis.TotalComponents = total
+	analysis.FailedComponents = failed
+	switch {
+	case failed == 0:
+		analysis.Status = types.SbomAnalysisComplete
+	case failed < total:
+		analysis.Status = types.SbomAnalysisPartial
+	default:
+		analysis.Status = types.SbomAnalysisFailed
+	}
+
+	return analysis, nil
+}
+
 func (c *ComponentStore) GetComponentTotalCount(filter interface{}) (int64, error) {
 	// Get total count of documents
 	total, err := c.collection.CountDocuments(context.TODO(), filter)
@@ -235,6 +747,32 @@ func (c *ComponentStore) GetPaginatedComponents(page, limit, duration int) ([]ty
 	return c.GetComponentsUsingFilter(bson.M{}, page, limit, duration)
 }
 
+// passes stored components of sbom to visit one at a time using cursor, so components of large
+// sboms are not loaded into memory together
+func (c *ComponentStore) ForEachComponentBySbomId(sbomId string, duration int, visit func(types.Component) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(duration)*time.Second)
+	defer cancel()
+
+	cursor, err := c.collection.Find(ctx, bson.M{"sbom_id": sbomId})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var component types.Component
+		if err := cursor.Decode(&component); err != nil {
+			return err
+		}
+
+		if err := visit(component); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
 // Handler for getting paginated items
 func (c *ComponentStore) GetComponentById(idParam string, duration int) ([]types.Component, error) {
 	// Convert the string ID to a MongoDB ObjectID
@@ -250,7 +788,30 @@ func (c *ComponentStore) GetComponentByName(name string, duration int) ([]types.
 	return c.GetComponentsUsingFilter(bson.M{"component_name": name}, 1, 1, config.DefaultConfig.DbQueryTimeout)
 }
 
-func (c *ComponentStore) GetVulnerableSbomComponentsFilter(componentNames, componentVersions, sbomIds, compTypes, compNames, purls, versions []string, page, limit int) bson.M {
+// returns filter matching components using search query. Components are limited to sbomIds
+// when it is not nil, which is used to search components of sboms matching metadata properties.
+func (c *ComponentStore) GetSearchFilter(query types.SearchQuery, sbomIds []string) interface{} {
+	filter := bson.M{}
+	if name := utils.BuildNameFilter(query); name != nil {
+		filter["name"] = name
+	}
+	if query.Purl != "" {
+		filter["purl"] = utils.BuildPurlFilter(query.Purl)
+	}
+	if query.Hash != "" {
+		filter["hashes"] = utils.BuildHashFilter(query.Hash, query.HashAlgorithm)
+	}
+	if query.Supplier != "" {
+		filter["supplier"] = query.Supplier
+	}
+	if sbomIds != nil {
+		filter["sbom_id"] = bson.M{"$in": sbomIds}
+	}
+
+	return filter
+}
+
+func (c *ComponentStore) GetVulnerableSbomComponentsFilter(componentNames, componentVersions, sbomIds, compTypes, compNames, purls, versions []string, includeSuppressed bool, page, limit int) bson.M {
 	conditions := map[string][]string{
 		"component_name":    componentNames,
 		"component_version": componentVersions,
@@ -262,17 +823,30 @@ func (c *ComponentStore) GetVulnerableSbomComponentsFilter(componentNames, compo
 	}
 	filter := utils.BuildDynamicContainsFilter(conditions)
 
-	// Add to the query: {vulns: { $exists: true, $ne: []}}
-	filter["vulns"] = bson.M{
-		"$exists": true,
-		"$ne":     []interface{}{}, // Ensure the array is not empty
+	if includeSuppressed {
+		// Add to the query: {vulns: { $exists: true, $ne: []}}
+		filter["vulns"] = bson.M{
+			"$exists": true,
+			"$ne":     []interface{}{}, // Ensure the array is not empty
+		}
+	} else {
+		// at least one vuln should not be suppressed by vex statement or pedigree patch
+		filter["vulns"] = bson.M{
+			"$elemMatch": bson.M{
+				"vex.status": bson.M{"$nin": types.VexSuppressedStatuses},
+				"patch":      bson.M{"$exists": false},
+			},
+		}
 	}
 
 	return filter
 }
 
-func (c *ComponentStore) GetVulnerableComponents(componentNames, componentVersions, sbomIds, compTypes, compNames, purls, versions []string, page, limit, duration int) (components []types.Component, total int64, err error) {
-	filter := c.GetVulnerableSbomComponentsFilter(componentNames, componentVersions, sbomIds, compTypes, compNames, purls, versions, page, limit)
+// returns components with vulns. Vulns suppressed by vex statements or pedigree patches are removed
+// from components unless includeSuppressed is set, in which case they are returned along with
+// vex analysis and patch.
+func (c *ComponentStore) GetVulnerableComponents(componentNames, componentVersions, sbomIds, compTypes, compNames, purls, versions []string, includeSuppressed bool, page, limit, duration int) (components []types.Component, total int64, err error) {
+	filter := c.GetVulnerableSbomComponentsFilter(componentNames, componentVersions, sbomIds, compTypes, compNames, purls, versions, includeSuppressed, page, limit)
 
 	components, err = c.GetComponentsUsingFilter(filter, page, limit, duration)
 	if err != nil {
@@ -280,6 +854,18 @@ func (c *ComponentStore) GetVulnerableComponents(componentNames, componentVersio
 		return components, total, err
 	}
 
+	if !includeSuppressed {
+		for i := range components {
+			var vulns []types.Vuln
+			for _, vuln := range components[i].Vulns {
+				if !vuln.IsSuppressed() {
+					vulns = append(vulns, vuln)
+				}
+			}
+			components[i].Vulns = vulns
+		}
+	}
+
 	total, err = c.GetComponentTotalCount(filter)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get total components")
@@ -310,6 +896,10 @@ func (c *ComponentStore) DeleteByIds(idParams []string, param string, duration i
 		param = "_id"
 	}
 	filter := bson.M{param: bson.M{"$in": objectIDs}}
+	if param != "_id" {
+		// referenced ids such as sbom_id are stored as strings
+		filter = bson.M{param: bson.M{"$in": idParams}}
+	}
 
 	result, err := c.collection.DeleteMany(ctx, filter)
 	if err != nil {
@@ -323,3 +913,51 @@ func (c *ComponentStore) DeleteByIds(idParams []string, param string, duration i
 func (c *ComponentStore) DeleteById(idParam string, param string, duration int) (int64, error) {
 	return c.DeleteByIds([]string{idParam}, param, duration)
 }
+
+// returns number of components of sboms and vulns found in those components
+func (c *ComponentStore) CountBySbomIds(sbomIds []string, duration int) (components, findings int64, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(duration)*time.Second)
+	defer cancel()
+
+	pipeline := bson.A{
+		bson.M{"$match": bson.M{"sbom_id": bson.M{"$in": sbomIds}}},
+		bson.M{"$group": bson.M{
+			"_id":        nil,
+			"components": bson.M{"$sum": 1},
+			"findings":   bson.M{"$sum": bson.M{"$size": bson.M{"$ifNull": bson.A{"$vulns", bson.A{}}}}},
+		}},
+	}
+	cursor, err := c.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to count components of sboms")
+		return 0, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var counts []struct {
+		Components int64 `bson:"components"`
+		Findings   int64 `bson:"findings"`
+	}
+	if err := cursor.All(ctx, &counts); err != nil {
+		log.Error().Err(err).Msg("failed to count components of sboms")
+		return 0, 0, err
+	}
+
+	// no group is returned when sboms don't have components
+	if len(counts) == 0 {
+		return 0, 0, nil
+	}
+
+	return counts[0].Components, counts[0].Findings, nil
+}
+
+// deletes components of sboms along with their vulns using ctx, which may be a transaction
+func (c *ComponentStore) DeleteBySbomIds(ctx context.Context, sbomIds []string) (int64, error) {
+	result, err := c.collection.DeleteMany(ctx, bson.M{"sbom_id": bson.M{"$in": sbomIds}})
+	if err != nil {
+		log.Error().Err(err).Msg("failed to delete components of sboms")
+		return -1, err
+	}
+
+	return result.DeletedCount, nil
+}
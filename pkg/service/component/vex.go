@@ -0,0 +1,197 @@
+package component
+
+import (
+	"context"
+
+	"github.com/dmdhrumilmistry/defect-detect/pkg/config"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/utils"
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// number of updated components written to db at once while applying vex statements
+const vexWriteBatchSize = 500
+
+// returns purl of sbom root component which vex products can refer to
+func getSbomRootPurl(sbom types.Sbom) string {
+	if sbom.Metadata == nil || sbom.Metadata.Component == nil {
+		return ""
+	}
+
+	return sbom.Metadata.Component.PackageURL
+}
+
+// returns ids and aliases of vulns
+func getVulnIds(vulns []types.Vuln) []string {
+	ids := []string{}
+	for _, vuln := range vulns {
+		ids = append(ids, vuln.ID)
+		ids = append(ids, vuln.Aliases...)
+	}
+
+	return ids
+}
+
+// returns true when vex statement refers to the vuln using its id or aliases
+func vexStatementMatchesVuln(statement types.VexStatement, vuln types.Vuln) bool {
+	vulnIds := append([]string{vuln.ID}, vuln.Aliases...)
+	for _, statementId := range append([]string{statement.VulnId}, statement.Aliases...) {
+		for _, vulnId := range vulnIds {
+			if statementId != "" && statementId == vulnId {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// returns true when vex statement product refers to the component or to the sbom containing
+// it. Statements with subcomponents only apply to those subcomponents of the product sbom.
+func vexStatementMatchesComponent(statement types.VexStatement, component types.Component) bool {
+	if len(statement.SubcomponentPurls) == 0 {
+		return utils.PurlMatches(statement.ProductPurl, component.PackageUrl) || utils.PurlMatches(statement.ProductPurl, component.ComponentPurl)
+	}
+
+	if !utils.PurlMatches(statement.ProductPurl, component.ComponentPurl) {
+		return false
+	}
+
+	for _, subcomponentPurl := range statement.SubcomponentPurls {
+		if utils.PurlMatches(subcomponentPurl, component.PackageUrl) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// applies latest matching vex statement to each component vuln. Returns true when any vuln changed.
+func applyVexStatements(statements []types.VexStatement, component *types.Component) bool {
+	changed := false
+	for i := range component.Vulns {
+		vuln := &component.Vulns[i]
+		for _, statement := range statements {
+			if !vexStatementMatchesVuln(statement, *vuln) || !vexStatementMatchesComponent(statement, *component) {
+				continue
+			}
+
+			if vuln.Vex != nil && vuln.Vex.Timestamp.After(statement.Timestamp) {
+				continue
+			}
+
+			// statements of re-uploaded documents keep their id, so applied statement is compared
+			// using its content
+			analysis := types.VexAnalysis{
+				StatementId:     statement.Id,
+				Status:          statement.Status,
+				Justification:   statement.Justification,
+				ImpactStatement: statement.ImpactStatement,
+				ActionStatement: statement.ActionStatement,
+				Author:          statement.Author,
+				Timestamp:       statement.Timestamp,
+			}
+			if vuln.Vex != nil && vuln.Vex.Equal(analysis) {
+				continue
+			}
+
+			vuln.Vex = &analysis
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+// applies stored vex statements to vulns of analyzed component
+func (c *ComponentStore) applyStoredVexStatements(component *types.Component) {
+	if c.vexStore == nil || len(component.Vulns) == 0 {
+		return
+	}
+
+	statements, err := c.vexStore.GetStatementsByVulnIds(getVulnIds(component.Vulns), config.DefaultConfig.DbQueryTimeout)
+	if err != nil {
+		// findings are still stored, statements are applied again on next vex upload or rescan
+		log.Error().Err(err).Msgf("failed to fetch vex statements for purl %s", component.PackageUrl)
+		return
+	}
+
+	applyVexStatements(statements, component)
+}
+
+// applies vex statements to vulns of already analyzed components and returns number of updated components
+func (c *ComponentStore) ApplyVexStatements(statements []types.VexStatement) (int64, error) {
+	var vulnIds []string
+	for _, statement := range statements {
+		vulnIds = append(vulnIds, statement.VulnId)
+		vulnIds = append(vulnIds, statement.Aliases...)
+	}
+	if len(vulnIds) == 0 {
+		return 0, nil
+	}
+
+	ctx := context.TODO()
+	filter := bson.M{"$or": []bson.M{
+		{"vulns.id": bson.M{"$in": vulnIds}},
+		{"vulns.aliases": bson.M{"$in": vulnIds}},
+	}}
+	projection := bson.M{"vulns": 1, "purl": 1, "component_purl": 1}
+
+	cursor, err := c.collection.Find(ctx, filter, options.Find().SetProjection(projection))
+	if err != nil {
+		log.Error().Err(err).Msg("failed to fetch components affected by vex statements")
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var updated int64
+	var models []mongo.WriteModel
+	writeModels := func() error {
+		if len(models) == 0 {
+			return nil
+		}
+
+		result, err := c.collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+		if err != nil {
+			log.Error().Err(err).Msg("failed to apply vex statements to components")
+			return err
+		}
+		updated += result.ModifiedCount
+		models = nil
+
+		return nil
+	}
+
+	for cursor.Next(ctx) {
+		var component types.Component
+		if err := cursor.Decode(&component); err != nil {
+			return updated, err
+		}
+
+		if !applyVexStatements(statements, &component) {
+			continue
+		}
+
+		objectId, err := primitive.ObjectIDFromHex(component.Id)
+		if err != nil {
+			log.Error().Err(err).Msgf("invalid component id %s", component.Id)
+			continue
+		}
+
+		models = append(models, mongo.NewUpdateOneModel().SetFilter(bson.M{"_id": objectId}).SetUpdate(bson.M{"$set": bson.M{"vulns": component.Vulns}}))
+		if len(models) == vexWriteBatchSize {
+			if err := writeModels(); err != nil {
+				return updated, err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return updated, err
+	}
+
+	return updated, writeModels()
+}
@@ -0,0 +1,269 @@
+package github
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dmdhrumilmistry/defect-detect/pkg/config"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/githubapi"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type GithubHandler struct {
+	store     types.GithubIntegrationStore
+	importer  *Importer
+	jobStore  types.JobStore
+	authStore types.AuthStore
+}
+
+func NewGithubHandler(store types.GithubIntegrationStore, importer *Importer, jobStore types.JobStore, authStore types.AuthStore) *GithubHandler {
+	return &GithubHandler{
+		store:     store,
+		importer:  importer,
+		jobStore:  jobStore,
+		authStore: authStore,
+	}
+}
+
+func (g *GithubHandler) RegisterRoutes(r *gin.Engine) {
+	// api v1
+	r.POST("/api/v1/sbom/githubImport", g.ImportGithubRepo)
+	r.POST("/api/v1/github/import", g.ImportGithubOwner)
+	r.POST("/api/v1/github/integration", g.CreateIntegration)
+	r.GET("/api/v1/github/integration", g.GetIntegrations)
+	r.DELETE("/api/v1/github/integration/:id", g.DeleteIntegration)
+
+	log.Info().Msg("Github routes registered")
+}
+
+// writes error response of failed github import
+func respondImportError(c *gin.Context, err error) {
+	var apiErr *githubapi.ApiError
+	switch {
+	case errors.Is(err, ErrInvalidRequest), errors.Is(err, githubapi.ErrInvalidUrl), errors.Is(err, ErrApiUrlNotAllowed), errors.Is(err, ErrIntegrationNotFound), errors.Is(err, ErrTokenNotQueued):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, githubapi.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "github repo, account or dependency graph not found"})
+	case errors.Is(err, types.ErrSbomRevisionConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidSbom):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.As(err, &apiErr):
+		// status code of upstream is only logged
+		log.Error().Err(err).Msgf("github api responded with status code %d", apiErr.StatusCode)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "failed to fetch sbom github api"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch sbom github api"})
+	}
+}
+
+// imports dependency graph sbom of repo, optionally of a branch or tag other than default branch
+// curl -X POST -H "application/json" -d '{"owner":"dmdhrumilmistry", "repo_name":"pyhtools"}' http://localhost:8080/api/v1/sbom/githubImport
+// curl -X POST -H "application/json" -d '{"owner":"dmdhrumilmistry", "repo_name":"pyhtools", "ref":"dev", "link_project":true}' http://localhost:8080/api/v1/sbom/githubImport
+func (g *GithubHandler) ImportGithubRepo(c *gin.Context) {
+	var jsonData types.GithubRepoImportRequestSchema
+
+	// Bind the incoming JSON data to the struct
+	if err := c.ShouldBindJSON(&jsonData); err != nil {
+		// If there is an error parsing the JSON, send an error response
+		log.Error().Err(err).Msg("invalid json data")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	result, err := g.importer.ImportRepo(c.Request.Context(), types.GithubImportJob{
+		Owner:         jsonData.Owner,
+		RepoName:      jsonData.RepoName,
+		Ref:           jsonData.Ref,
+		ApiUrl:        jsonData.ApiUrl,
+		Token:         jsonData.Token,
+		IntegrationId: jsonData.IntegrationId,
+		LinkProject:   jsonData.LinkProject,
+	})
+	if err != nil {
+		respondImportError(c, err)
+		return
+	}
+
+	message := "SBOM uploaded successfully"
+	if result.Duplicate {
+		message = "SBOM already exists"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   message,
+		"id":        result.SbomId,
+		"duplicate": result.Duplicate,
+		"import":    result,
+	})
+}
+
+// queues import of every repo of org or user account, archived and forked repos are skipped
+// curl -X POST -H "application/json" -d '{"owner":"dmdhrumilmistry", "link_project":true}' http://localhost:8080/api/v1/github/import
+func (g *GithubHandler) ImportGithubOwner(c *gin.Context) {
+	var jsonData types.GithubBulkImportRequestSchema
+	if err := c.ShouldBindJSON(&jsonData); err != nil {
+		log.Error().Err(err).Msg("invalid json data")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	request := types.GithubImportJob{
+		Owner:         jsonData.Owner,
+		ApiUrl:        jsonData.ApiUrl,
+		Token:         jsonData.Token,
+		IntegrationId: jsonData.IntegrationId,
+		LinkProject:   jsonData.LinkProject,
+	}
+	if !githubapi.IsValidName(request.Owner) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid owner"})
+		return
+	} else if request.Token != "" {
+		// jobs reference integration instead of storing token of request
+		respondImportError(c, ErrTokenNotQueued)
+		return
+	}
+
+	client, err := g.importer.GetClient(request)
+	if err != nil {
+		respondImportError(c, err)
+		return
+	}
+
+	repos, err := client.ListRepos(c.Request.Context(), request.Owner)
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to list repos of github account %s", request.Owner)
+		respondImportError(c, err)
+		return
+	}
+
+	jobs := []gin.H{}
+	skipped := []gin.H{}
+	for _, repo := range repos {
+		if repo.Archived || repo.Fork {
+			reason := "archived"
+			if repo.Fork {
+				reason = "fork"
+			}
+			skipped = append(skipped, gin.H{"repo": repo.Name, "reason": reason})
+			continue
+		}
+
+		repoRequest := request
+		repoRequest.RepoName = repo.Name
+		jobId, err := g.jobStore.AddJob(types.Job{
+			Type:         types.JobTypeImportGithubRepo,
+			GithubImport: &repoRequest,
+		})
+		if err != nil {
+			log.Error().Err(err).Msgf("failed to queue import of github repo %s", repo.FullName)
+			skipped = append(skipped, gin.H{"repo": repo.Name, "reason": "failed to queue import"})
+			continue
+		}
+		jobs = append(jobs, gin.H{"repo": repo.Name, "job_id": jobId})
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "github repo imports queued",
+		"owner":   request.Owner,
+		"total":   len(repos),
+		"jobs":    jobs,
+		"skipped": skipped,
+	})
+}
+
+// curl -X POST -H "application/json" -d '{"name":"enterprise", "api_url":"https://github.example.com/api/v3", "token":"ghp_..."}' http://localhost:8080/api/v1/github/integration
+func (g *GithubHandler) CreateIntegration(c *gin.Context) {
+	var payload types.GithubIntegrationRequestSchema
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		log.Error().Err(err).Msg("failed to validate request")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to validate payload"})
+		return
+	}
+
+	integration := types.GithubIntegration{
+		Name:   payload.Name,
+		ApiUrl: strings.TrimRight(payload.ApiUrl, "/"),
+		Token:  payload.Token,
+	}
+	if integration.ApiUrl == "" {
+		integration.ApiUrl = config.DefaultConfig.GithubApiUrl
+	} else if err := ValidateApiUrl(integration.ApiUrl); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := g.store.AddIntegration(integration)
+	if mongo.IsDuplicateKeyError(err) {
+		c.JSON(http.StatusConflict, gin.H{"error": "github integration with same name already exists"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create github integration"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "github integration created successfully", "id": id})
+}
+
+// curl http://localhost:8080/api/v1/github/integration
+func (g *GithubHandler) GetIntegrations(c *gin.Context) {
+	// Get page and limit from query parameters
+	pageStr := c.DefaultQuery("page", "1")
+	limitStr := c.DefaultQuery("limit", "10")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page number"})
+		return
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 || limit >= 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit number"})
+		return
+	}
+
+	integrations, err := g.store.GetIntegrationsUsingFilter(bson.M{}, page, limit, config.DefaultConfig.DbQueryTimeout)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to fetch github integrations")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse data"})
+		return
+	}
+
+	total, err := g.store.GetTotalCount(bson.M{})
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get total github integrations")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse data"})
+		return
+	}
+
+	// Build response
+	c.JSON(http.StatusOK, gin.H{
+		"data":  integrations,
+		"page":  page,
+		"limit": limit,
+		"total": total,
+	})
+}
+
+// curl -X DELETE http://localhost:8080/api/v1/github/integration/{integration_id}
+func (g *GithubHandler) DeleteIntegration(c *gin.Context) {
+	idParam := c.Param("id")
+
+	count, err := g.store.DeleteById(idParam, config.DefaultConfig.DbQueryTimeout)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to delete github integration"})
+		return
+	} else if count == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "github integration not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "github integration deleted successfully"})
+}
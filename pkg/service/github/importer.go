@@ -0,0 +1,251 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/config"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/githubapi"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/sbomconvert"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/sbomformat"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/sbomquality"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/sbomvalidate"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/service/project"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// sboms retained by projects created for imported repos
+const defaultSbomsToRetain = 3
+
+var (
+	ErrInvalidRequest      = errors.New("invalid github import request")
+	ErrIntegrationNotFound = errors.New("github integration not found")
+	ErrInvalidSbom         = errors.New("invalid github dependency graph sbom")
+	ErrApiUrlNotAllowed    = errors.New("github api url is not allowed, allowed urls are configured using GITHUB_ALLOWED_API_URLS")
+	ErrTokenNotQueued      = errors.New("tokens are not stored with queued imports, use integration_id of a github integration instead")
+)
+
+// ValidateApiUrl returns error when api url isn't GITHUB_API_URL or one of GITHUB_ALLOWED_API_URLS,
+// so that requests can not make server call arbitrary hosts
+func ValidateApiUrl(apiUrl string) error {
+	if err := githubapi.ValidateApiUrl(apiUrl); err != nil {
+		return err
+	}
+
+	if apiUrl != config.DefaultConfig.GithubApiUrl && !slices.Contains(config.DefaultConfig.GithubAllowedApiUrls, apiUrl) {
+		return ErrApiUrlNotAllowed
+	}
+
+	return nil
+}
+
+type Importer struct {
+	integrationStore types.GithubIntegrationStore
+	sbomStore        types.SbomStore
+	projectStore     types.ProjectStore
+	sbomManager      *project.SbomManager
+}
+
+func NewImporter(integrationStore types.GithubIntegrationStore, sbomStore types.SbomStore, projectStore types.ProjectStore, sbomManager *project.SbomManager) *Importer {
+	return &Importer{
+		integrationStore: integrationStore,
+		sbomStore:        sbomStore,
+		projectStore:     projectStore,
+		sbomManager:      sbomManager,
+	}
+}
+
+// GetClient returns client of github account selected by import request. Tokens of configured
+// accounts are never sent to api url provided with the request, which must be allowed in config.
+func (i *Importer) GetClient(request types.GithubImportJob) (*githubapi.Client, error) {
+	apiUrl, token := config.DefaultConfig.GithubApiUrl, config.DefaultConfig.GithubToken
+
+	if request.IntegrationId != "" {
+		integration, err := i.integrationStore.GetIntegrationById(request.IntegrationId, config.DefaultConfig.DbQueryTimeout)
+		if err != nil {
+			log.Error().Err(err).Msgf("failed to fetch github integration %s", request.IntegrationId)
+			return nil, ErrIntegrationNotFound
+		}
+
+		if integration.ApiUrl != "" {
+			apiUrl = integration.ApiUrl
+		}
+		token = integration.Token
+	}
+
+	if requestUrl := strings.TrimRight(request.ApiUrl, "/"); requestUrl != "" && requestUrl != apiUrl {
+		if err := ValidateApiUrl(requestUrl); err != nil {
+			return nil, err
+		}
+		apiUrl, token = requestUrl, ""
+	}
+
+	if request.Token != "" {
+		token = request.Token
+	}
+
+	return githubapi.NewClient(apiUrl, token), nil
+}
+
+// ValidateRequest returns error when repo or ref of import request is invalid
+func ValidateRequest(request types.GithubImportJob) error {
+	if !githubapi.IsValidName(request.Owner) || !githubapi.IsValidName(request.RepoName) {
+		return fmt.Errorf("%w: owner (%s) and repo name (%s) are invalid", ErrInvalidRequest, request.Owner, request.RepoName)
+	}
+
+	if request.Ref != "" && !githubapi.IsValidRef(request.Ref) {
+		return fmt.Errorf("%w: ref (%s) is invalid", ErrInvalidRequest, request.Ref)
+	}
+
+	return nil
+}
+
+func addProperty(bom *cyclonedx.BOM, name, value string) {
+	if bom.Metadata == nil {
+		bom.Metadata = &cyclonedx.Metadata{}
+	}
+	if bom.Metadata.Properties == nil {
+		bom.Metadata.Properties = &[]cyclonedx.Property{}
+	}
+
+	*bom.Metadata.Properties = append(*bom.Metadata.Properties, cyclonedx.Property{Name: name, Value: value})
+}
+
+// returns project linked to repo, project is created when no project links to the repo
+func (i *Importer) getOrCreateProject(repo githubapi.Repo) (types.Project, bool, error) {
+	projects, err := i.projectStore.GetUsingFilter(bson.M{"links": repo.HtmlUrl}, 1, 1, config.DefaultConfig.DbQueryTimeout)
+	if err != nil {
+		return types.Project{}, false, err
+	} else if len(projects) > 0 {
+		return projects[0], false, nil
+	}
+
+	description := repo.Description
+	if description == "" {
+		description = fmt.Sprintf("imported from %s", repo.HtmlUrl)
+	}
+
+	newProject := types.Project{
+		Name:          repo.FullName,
+		Description:   description,
+		SbomsToRetain: defaultSbomsToRetain,
+		Sboms:         []string{},
+		Links:         []string{repo.HtmlUrl},
+	}
+	newProject.Id, err = i.projectStore.AddProject(newProject)
+	if err != nil {
+		return newProject, false, err
+	}
+	log.Info().Msgf("created project %s for github repo %s", newProject.Id, repo.HtmlUrl)
+
+	return newProject, true, nil
+}
+
+// ImportRepo stores dependency graph sbom of repo and queues its analysis. Sboms of refs other than
+// default branch are built by applying dependency changes of ref to default branch sbom.
+func (i *Importer) ImportRepo(ctx context.Context, request types.GithubImportJob) (types.GithubImportResult, error) {
+	result := types.GithubImportResult{}
+	if err := ValidateRequest(request); err != nil {
+		return result, err
+	}
+
+	client, err := i.GetClient(request)
+	if err != nil {
+		return result, err
+	}
+
+	log.Info().Msgf("Fetching SBOM for %s/%s repo", request.Owner, request.RepoName)
+
+	repo, err := client.GetRepo(ctx, request.Owner, request.RepoName)
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to fetch github repo %s/%s", request.Owner, request.RepoName)
+		return result, err
+	}
+	result.Repository = repo.HtmlUrl
+	result.Ref = repo.DefaultBranch
+
+	content, err := client.GetSbom(ctx, request.Owner, request.RepoName)
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to fetch sbom of github repo %s", repo.FullName)
+		return result, err
+	}
+
+	// github dependency graph sboms are in spdx json format
+	doc, err := sbomformat.ReadSpdx(content, sbomformat.SpdxJson)
+	if err != nil {
+		return result, fmt.Errorf("%w: %v", ErrInvalidSbom, err)
+	}
+
+	bom, loss, err := sbomconvert.ConvertSpdxDocument(doc)
+	if err != nil {
+		return result, fmt.Errorf("%w: %v", ErrInvalidSbom, err)
+	}
+
+	if request.Ref != "" && request.Ref != repo.DefaultBranch {
+		changes, err := client.CompareDependencies(ctx, request.Owner, request.RepoName, repo.DefaultBranch, request.Ref)
+		if err != nil {
+			log.Error().Err(err).Msgf("failed to compare dependencies of %s with ref %s", repo.FullName, request.Ref)
+			return result, err
+		}
+
+		diff := githubapi.ApplyDependencyChanges(bom, changes)
+		result.DependencyChanges = &diff
+		result.Ref = request.Ref
+	}
+
+	addProperty(bom, "defect-detect:github:repository", repo.HtmlUrl)
+	addProperty(bom, "defect-detect:github:ref", result.Ref)
+
+	if validationErrs := sbomvalidate.ValidateSemantics(bom); len(validationErrs) > 0 {
+		return result, fmt.Errorf("%w: %s", ErrInvalidSbom, validationErrs[0].Message)
+	}
+
+	sbom := types.NewSbom(bom, string(sbomformat.SpdxJson))
+	sbom.ConversionLoss = loss
+	result.ConversionLoss = loss
+
+	// exported document only describes default branch
+	if result.DependencyChanges == nil {
+		sbom.OriginalDocument = string(content)
+	}
+
+	quality := sbomquality.Score(sbom)
+	sbom.Quality = &quality
+
+	sbomId, err := i.sbomStore.AddComponentSbom(sbom)
+	result.Duplicate = errors.Is(err, types.ErrDuplicateSbom)
+	if err != nil && !result.Duplicate {
+		return result, err
+	}
+	result.SbomId = sbomId
+
+	if request.LinkProject {
+		repoProject, created, err := i.getOrCreateProject(repo)
+		if err != nil {
+			log.Error().Err(err).Msgf("failed to link project to github repo %s", repo.HtmlUrl)
+			return result, err
+		}
+		result.ProjectId = repoProject.Id
+		result.ProjectCreated = created
+
+		result.Evicted, result.ProjectError, err = i.sbomManager.AddSbom(repoProject, sbom, sbomId)
+		if err != nil {
+			log.Error().Err(err).Msgf("failed to add sbom %s to project %s", sbomId, repoProject.Id)
+			return result, err
+		}
+	}
+
+	jobId, err := i.sbomManager.QueueAnalysis(sbomId, result.Duplicate)
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to queue analysis of sbom %s", sbomId)
+	}
+	result.AnalysisJobId = jobId
+
+	return result, nil
+}
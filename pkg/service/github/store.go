@@ -0,0 +1,102 @@
+package github
+
+import (
+	"context"
+	"time"
+
+	"github.com/dmdhrumilmistry/defect-detect/pkg/db"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/utils"
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const GITHUB_INTEGRATION_COLLECTION = "github_integration"
+
+type GithubIntegrationStore struct {
+	db         *mongo.Database
+	collection *mongo.Collection
+}
+
+func NewGithubIntegrationStore(mdb *mongo.Database) *GithubIntegrationStore {
+	collection := mdb.Collection(GITHUB_INTEGRATION_COLLECTION)
+
+	db.EnsureIndex(collection, mongo.IndexModel{
+		Keys:    bson.D{{Key: "name", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	return &GithubIntegrationStore{
+		db:         mdb,
+		collection: collection,
+	}
+}
+
+func (g *GithubIntegrationStore) AddIntegration(integration types.GithubIntegration) (string, error) {
+	integration.Id = ""
+	integration.CreatedAt = time.Now()
+
+	result, err := g.collection.InsertOne(context.TODO(), integration)
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to insert github integration %s", integration.Name)
+		return "", err
+	}
+
+	return result.InsertedID.(primitive.ObjectID).Hex(), nil
+}
+
+func (g *GithubIntegrationStore) GetTotalCount(filter interface{}) (int64, error) {
+	// Get total count of documents
+	total, err := g.collection.CountDocuments(context.TODO(), filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (g *GithubIntegrationStore) GetIntegrationsUsingFilter(filter interface{}, page, limit, duration int) ([]types.GithubIntegration, error) {
+	integrations, err := utils.GetObjectsUsingFilter[types.GithubIntegration](g.collection, filter, page, limit, duration)
+	for i := range integrations {
+		integrations[i].HasToken = integrations[i].Token != ""
+	}
+
+	return integrations, err
+}
+
+func (g *GithubIntegrationStore) GetIntegrationById(idParam string, duration int) (types.GithubIntegration, error) {
+	var integration types.GithubIntegration
+
+	objID, err := primitive.ObjectIDFromHex(idParam)
+	if err != nil {
+		return integration, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(duration)*time.Second)
+	defer cancel()
+
+	err = g.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&integration)
+	integration.HasToken = integration.Token != ""
+	return integration, err
+}
+
+func (g *GithubIntegrationStore) DeleteById(idParam string, duration int) (int64, error) {
+	objID, err := primitive.ObjectIDFromHex(idParam)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(duration)*time.Second)
+	defer cancel()
+
+	result, err := g.collection.DeleteOne(ctx, bson.M{"_id": objID})
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to delete github integration %s", idParam)
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
@@ -0,0 +1,130 @@
+package job
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dmdhrumilmistry/defect-detect/pkg/config"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type JobHandler struct {
+	store     types.JobStore
+	authStore types.AuthStore
+}
+
+func NewJobHandler(store types.JobStore, authStore types.AuthStore) *JobHandler {
+	return &JobHandler{
+		store:     store,
+		authStore: authStore,
+	}
+}
+
+func (j *JobHandler) RegisterRoutes(r *gin.Engine) {
+	// api v1
+	r.GET("/api/v1/jobs", j.GetJobs)
+	r.GET("/api/v1/jobs/:id", j.GetJobById)
+	r.DELETE("/api/v1/jobs/:id", j.CancelJobById)
+
+	log.Info().Msg("Job routes registered")
+}
+
+// curl "http://localhost:8080/api/v1/jobs?statuses=queued,running"
+func (j *JobHandler) GetJobs(c *gin.Context) {
+	// Get page and limit from query parameters
+	pageStr := c.DefaultQuery("page", "1")
+	limitStr := c.DefaultQuery("limit", "10")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page number"})
+		return
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 || limit >= 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit number"})
+		return
+	}
+
+	filter := utils.BuildDynamicContainsFilter(map[string][]string{
+		"status":  utils.Split(c.DefaultQuery("statuses", ""), ","),
+		"sbom_id": utils.Split(c.DefaultQuery("sbom_ids", ""), ","),
+		"type":    utils.Split(c.DefaultQuery("types", ""), ","),
+	})
+
+	jobs, err := j.store.GetJobsUsingFilter(filter, page, limit, config.DefaultConfig.DbQueryTimeout)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to fetch jobs")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse data"})
+		return
+	}
+
+	total, err := j.store.GetTotalCount(filter)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get total jobs")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse data"})
+		return
+	}
+
+	// Build response
+	c.JSON(http.StatusOK, gin.H{
+		"data":  jobs,
+		"page":  page,
+		"limit": limit,
+		"total": total,
+	})
+}
+
+// curl http://localhost:8080/api/v1/jobs/{job_id}
+func (j *JobHandler) GetJobById(c *gin.Context) {
+	idParam := c.Param("id")
+	if !utils.IsValidMongoObjectID(idParam) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid job id"})
+		return
+	}
+
+	job, err := j.store.GetJobById(idParam, config.DefaultConfig.DbQueryTimeout)
+	if err == mongo.ErrNoDocuments {
+		c.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+		return
+	} else if err != nil {
+		log.Error().Err(err).Msgf("failed to fetch job %s", idParam)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch job"})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// curl -X DELETE http://localhost:8080/api/v1/jobs/{job_id}
+func (j *JobHandler) CancelJobById(c *gin.Context) {
+	idParam := c.Param("id")
+	if !utils.IsValidMongoObjectID(idParam) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid job id"})
+		return
+	}
+
+	job, err := j.store.CancelJob(idParam, config.DefaultConfig.DbQueryTimeout)
+	if err == mongo.ErrNoDocuments {
+		c.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+		return
+	} else if err != nil {
+		log.Error().Err(err).Msgf("failed to cancel job %s", idParam)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to cancel job"})
+		return
+	}
+
+	switch job.Status {
+	case types.JobStatusCancelled:
+		c.JSON(http.StatusOK, gin.H{"message": "job cancelled", "job": job})
+	case types.JobStatusRunning:
+		c.JSON(http.StatusAccepted, gin.H{"message": "job cancellation requested", "job": job})
+	default:
+		c.JSON(http.StatusConflict, gin.H{"error": "job has already finished", "job": job})
+	}
+}
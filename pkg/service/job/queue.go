@@ -0,0 +1,39 @@
+package job
+
+import (
+	"errors"
+
+	"github.com/dmdhrumilmistry/defect-detect/pkg/config"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// QueueAnalysis queues analysis of sbom and returns job id. Already queued or analyzed duplicate sboms are not re-queued.
+func QueueAnalysis(jobStore types.JobStore, sbomStore types.SbomStore, sbomId string, duplicate bool) (string, error) {
+	if duplicate {
+		job, err := jobStore.GetActiveJobBySbomId(sbomId, config.DefaultConfig.DbQueryTimeout)
+		if err == nil {
+			return job.Id, nil
+		} else if err != mongo.ErrNoDocuments {
+			return "", err
+		}
+
+		sbom, err := sbomStore.GetSbomById(sbomId, config.DefaultConfig.DbQueryTimeout)
+		if err != nil {
+			return "", err
+		} else if sbom.Analysis != nil {
+			return "", nil
+		}
+	}
+
+	// job queued by a concurrent request is returned
+	jobId, err := jobStore.AddJob(types.Job{
+		Type:   types.JobTypeAnalyzeSbom,
+		SbomId: sbomId,
+	})
+	if errors.Is(err, types.ErrActiveJobExists) {
+		return jobId, nil
+	}
+
+	return jobId, err
+}
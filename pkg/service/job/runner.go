@@ -0,0 +1,267 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dmdhrumilmistry/defect-detect/pkg/config"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type Runner struct {
+	store          types.JobStore
+	sbomStore      types.SbomStore
+	componentStore types.ComponentStore
+	githubImporter types.GithubImporter
+	projectScanner types.ProjectScanner
+
+	workers           int
+	pollInterval      time.Duration
+	heartbeatInterval time.Duration
+	staleAfter        time.Duration
+	maxAttempts       int
+	owner             string
+}
+
+func NewRunner(store types.JobStore, sbomStore types.SbomStore, componentStore types.ComponentStore, githubImporter types.GithubImporter, projectScanner types.ProjectScanner) *Runner {
+	hostname, err := os.Hostname()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get hostname for job runner owner id")
+		hostname = "unknown"
+	}
+
+	staleAfter := time.Duration(config.DefaultConfig.JobStaleTimeout) * time.Second
+
+	return &Runner{
+		store:          store,
+		sbomStore:      sbomStore,
+		componentStore: componentStore,
+		githubImporter: githubImporter,
+		projectScanner: projectScanner,
+
+		workers:           config.DefaultConfig.JobWorkersCount,
+		pollInterval:      time.Duration(config.DefaultConfig.JobPollInterval) * time.Second,
+		heartbeatInterval: staleAfter / 4,
+		staleAfter:        staleAfter,
+		maxAttempts:       config.DefaultConfig.JobMaxAttempts,
+		owner:             fmt.Sprintf("%s-%s", hostname, uuid.New().String()),
+	}
+}
+
+// Start requeues jobs left running by crashed workers and processes queued jobs until ctx is cancelled
+func (r *Runner) Start(ctx context.Context) {
+	if r.workers < 1 || r.pollInterval <= 0 || r.heartbeatInterval <= 0 || r.maxAttempts < 1 {
+		log.Warn().Msg("invalid job runner config, background jobs are disabled")
+		return
+	}
+
+	log.Info().Msgf("Starting job runner %s with %d workers", r.owner, r.workers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < r.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.work(ctx)
+		}()
+	}
+
+	// periodically resume jobs whose worker stopped sending heartbeats
+	ticker := time.NewTicker(r.staleAfter)
+	defer ticker.Stop()
+	for {
+		count, err := r.store.RequeueStaleJobs(r.staleAfter, r.maxAttempts)
+		if err == nil && count > 0 {
+			log.Info().Msgf("Requeued %d stale jobs", count)
+		}
+
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			log.Info().Msg("Stopped job runner")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Runner) work(ctx context.Context) {
+	for {
+		job, err := r.store.ClaimNextJob(r.owner)
+		if err == nil {
+			r.runJob(ctx, job)
+			continue
+		}
+
+		if err != mongo.ErrNoDocuments {
+			log.Error().Err(err).Msg("failed to claim job")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.pollInterval):
+		}
+	}
+}
+
+func (r *Runner) runJob(parentCtx context.Context, job types.Job) {
+	log.Info().Msgf("Running job %s (%s) for sbom %s, attempt %d", job.Id, job.Type, job.SbomId, job.Attempts)
+
+	if job.CancelRequested {
+		r.finish(job, types.JobStatusCancelled, "", 0)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(parentCtx)
+	defer cancel()
+
+	// progress is flushed to db with heartbeat instead of every processed component
+	var processed, failed, total atomic.Int64
+	var cancelled, lost atomic.Bool
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(r.heartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				cancelRequested, err := r.store.UpdateProgress(job.Id, r.owner, int(processed.Load()), int(failed.Load()), int(total.Load()))
+				if err == nil && cancelRequested {
+					log.Info().Msgf("Cancellation requested for job %s", job.Id)
+					cancelled.Store(true)
+					cancel()
+				} else if err == mongo.ErrNoDocuments {
+					// job went stale and was requeued, it's processed by its new owner
+					log.Warn().Msgf("job %s was claimed by another worker", job.Id)
+					lost.Store(true)
+					cancel()
+				}
+			}
+		}
+	}()
+
+	progress := func(p, f, t int) {
+		processed.Store(int64(p))
+		failed.Store(int64(f))
+		total.Store(int64(t))
+	}
+
+	var stored int
+	var err error
+	switch job.Type {
+	case types.JobTypeImportGithubRepo:
+		err = r.importGithubRepo(ctx, job)
+		progress(1, 0, 1)
+	case types.JobTypeRescanProject:
+		stored, err = r.rescanProject(ctx, job, progress)
+	default:
+		var sbom types.Sbom
+		sbom, err = r.sbomStore.GetSbomById(job.SbomId, config.DefaultConfig.DbQueryTimeout)
+		if err == nil {
+			switch job.Type {
+			case types.JobTypeAnalyzeSbom:
+				stored, err = r.componentStore.AnalyzeComponentsUsingSbom(ctx, sbom, progress)
+			case types.JobTypeRetryFailedComponents:
+				stored, err = r.componentStore.RetryFailedComponents(ctx, sbom, progress)
+			default:
+				err = fmt.Errorf("unsupported job type %s", job.Type)
+			}
+		}
+
+		if err == nil {
+			r.updateSbomAnalysis(job.SbomId)
+		}
+	}
+	close(done)
+
+	if lost.Load() {
+		return
+	}
+	if _, progressErr := r.store.UpdateProgress(job.Id, r.owner, int(processed.Load()), int(failed.Load()), int(total.Load())); progressErr != nil {
+		log.Error().Err(progressErr).Msgf("failed to store final progress of job %s", job.Id)
+	}
+
+	switch {
+	case cancelled.Load():
+		r.finish(job, types.JobStatusCancelled, "", 0)
+	case errors.Is(err, context.Canceled):
+		// runner is shutting down, job is queued again so it is resumed by the next runner
+		log.Warn().Msgf("job %s interrupted by shutdown", job.Id)
+		if err := r.store.ReleaseJob(job.Id, r.owner); err != nil {
+			log.Error().Err(err).Msgf("failed to requeue job %s, it is resumed once its heartbeat goes stale", job.Id)
+		}
+	case err != nil:
+		r.finish(job, types.JobStatusFailed, err.Error(), stored)
+	default:
+		r.finish(job, types.JobStatusSucceeded, "", stored)
+	}
+}
+
+// imports sbom of github repo and stores import result on job. Job is resumed when interrupted
+// by shutdown.
+func (r *Runner) importGithubRepo(ctx context.Context, job types.Job) error {
+	if job.GithubImport == nil {
+		return fmt.Errorf("job does not describe github repo to import")
+	}
+
+	result, err := r.githubImporter.ImportRepo(ctx, *job.GithubImport)
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	githubImport := *job.GithubImport
+	if err == nil {
+		githubImport.Result = &result
+	}
+	if updateErr := r.store.UpdateGithubImport(job.Id, githubImport); updateErr != nil {
+		log.Error().Err(updateErr).Msgf("failed to store github import result of job %s", job.Id)
+	}
+
+	return err
+}
+
+// rescans project of scheduled activation, job fails when rescan of any sbom fails
+func (r *Runner) rescanProject(ctx context.Context, job types.Job, progress types.ProgressFunc) (int, error) {
+	if job.Rescan == nil {
+		return 0, fmt.Errorf("job does not describe project to rescan")
+	}
+
+	run, err := r.projectScanner.RescanProject(ctx, *job.Rescan, job.Id, progress)
+	if err == nil && run.Status == types.ScanStatusFailed {
+		err = errors.New(run.Error)
+	}
+
+	return run.ComponentsScanned, err
+}
+
+// stores sbom level analysis completeness after components are analyzed
+func (r *Runner) updateSbomAnalysis(sbomId string) {
+	analysis, err := r.componentStore.GetSbomAnalysis(sbomId)
+	if err != nil {
+		return
+	}
+
+	if err := r.sbomStore.UpdateAnalysis(sbomId, analysis, config.DefaultConfig.DbQueryTimeout); err != nil {
+		log.Error().Err(err).Msgf("failed to store analysis status of sbom %s", sbomId)
+	}
+}
+
+func (r *Runner) finish(job types.Job, status, errMsg string, componentsCount int) {
+	if err := r.store.FinishJob(job.Id, r.owner, status, errMsg, componentsCount); err != nil {
+		log.Error().Err(err).Msgf("failed to mark job %s as %s", job.Id, status)
+		return
+	}
+
+	log.Info().Msgf("Job %s finished with status %s", job.Id, status)
+}
@@ -0,0 +1,417 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dmdhrumilmistry/defect-detect/pkg/db"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const JOB_COLLECTION = "job"
+
+type JobStore struct {
+	db         *mongo.Database
+	collection *mongo.Collection
+}
+
+func NewJobStore(mdb *mongo.Database) *JobStore {
+	collection := mdb.Collection(JOB_COLLECTION)
+
+	db.EnsureIndex(collection, mongo.IndexModel{
+		Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: 1}},
+	})
+	db.EnsureIndex(collection, mongo.IndexModel{
+		Keys: bson.D{{Key: "sbom_id", Value: 1}},
+	})
+
+	// concurrent requests can't queue more than one job of a sbom
+	db.EnsureIndex(collection, mongo.IndexModel{
+		Keys: bson.D{{Key: "active_sbom_id", Value: 1}},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+			"active_sbom_id": bson.M{"$type": "string"},
+		}),
+	})
+
+	return &JobStore{
+		db:         mdb,
+		collection: collection,
+	}
+}
+
+// AddJob queues job. Id of the active job of sbom is returned along with types.ErrActiveJobExists
+// when sbom already has a queued or running job.
+func (j *JobStore) AddJob(job types.Job) (string, error) {
+	job.Status = types.JobStatusQueued
+	job.ActiveSbomId = job.SbomId
+	job.CreatedAt = time.Now()
+
+	result, err := j.collection.InsertOne(context.TODO(), job)
+	if mongo.IsDuplicateKeyError(err) && job.SbomId != "" {
+		var active types.Job
+		if findErr := j.collection.FindOne(context.TODO(), bson.M{"active_sbom_id": job.SbomId}).Decode(&active); findErr != nil {
+			log.Error().Err(findErr).Msgf("failed to fetch active job of sbom %s", job.SbomId)
+			return "", findErr
+		}
+		return active.Id, types.ErrActiveJobExists
+	} else if err != nil {
+		log.Error().Err(err).Msg("failed to insert job")
+		return "", err
+	}
+
+	return (result.InsertedID).(primitive.ObjectID).Hex(), nil
+}
+
+func (j *JobStore) GetJobById(idParam string, duration int) (types.Job, error) {
+	var job types.Job
+
+	// Convert the string ID to a MongoDB ObjectID
+	objID, err := primitive.ObjectIDFromHex(idParam)
+	if err != nil {
+		return job, err
+	}
+
+	// Query MongoDB
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(duration)*time.Second)
+	defer cancel()
+
+	err = j.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&job)
+	return job, err
+}
+
+// returns queued or running job for the sbom, mongo.ErrNoDocuments is returned if not found
+func (j *JobStore) GetActiveJobBySbomId(sbomId string, duration int) (types.Job, error) {
+	var job types.Job
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(duration)*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"sbom_id": sbomId,
+		"status":  bson.M{"$in": []string{types.JobStatusQueued, types.JobStatusRunning}},
+	}
+	err := j.collection.FindOne(ctx, filter).Decode(&job)
+	return job, err
+}
+
+// returns queued and running jobs of sboms
+func (j *JobStore) GetActiveJobsBySbomIds(sbomIds []string, duration int) ([]types.Job, error) {
+	jobs := []types.Job{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(duration)*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"sbom_id": bson.M{"$in": sbomIds},
+		"status":  bson.M{"$in": []string{types.JobStatusQueued, types.JobStatusRunning}},
+	}
+	cursor, err := j.collection.Find(ctx, filter)
+	if err != nil {
+		return jobs, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &jobs); err != nil {
+		return jobs, err
+	}
+
+	return jobs, nil
+}
+
+// cancels queued jobs of sboms using ctx, which may be a transaction
+func (j *JobStore) CancelQueuedJobsBySbomIds(ctx context.Context, sbomIds []string) (int64, error) {
+	filter := bson.M{"sbom_id": bson.M{"$in": sbomIds}, "status": types.JobStatusQueued}
+	update := bson.M{
+		"$set":   bson.M{"status": types.JobStatusCancelled, "cancel_requested": true, "finished_at": time.Now()},
+		"$unset": bson.M{"active_sbom_id": "", "github_import.token": ""},
+	}
+
+	result, err := j.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to cancel queued jobs of sboms")
+		return -1, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// returns true when a job of any of the sboms is running, using ctx which may be a transaction
+func (j *JobStore) HasRunningJobs(ctx context.Context, sbomIds []string) (bool, error) {
+	count, err := j.collection.CountDocuments(ctx, bson.M{"sbom_id": bson.M{"$in": sbomIds}, "status": types.JobStatusRunning}, options.Count().SetLimit(1))
+	if err != nil {
+		log.Error().Err(err).Msg("failed to check running jobs of sboms")
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (j *JobStore) GetTotalCount(filter interface{}) (int64, error) {
+	// Get total count of documents
+	total, err := j.collection.CountDocuments(context.TODO(), filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// returns jobs matching filter with latest jobs first
+func (j *JobStore) GetJobsUsingFilter(filter interface{}, page, limit, duration int) ([]types.Job, error) {
+	var jobs []types.Job
+
+	// Calculate skip
+	skip := (page - 1) * limit
+
+	// MongoDB query options
+	findOptions := options.Find()
+	findOptions.SetSkip(int64(skip))
+	findOptions.SetLimit(int64(limit))
+	findOptions.SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	// Query MongoDB
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(duration)*time.Second)
+	defer cancel()
+
+	cursor, err := j.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return jobs, err
+	}
+	defer cursor.Close(ctx)
+
+	// Parse results
+	if err := cursor.All(ctx, &jobs); err != nil {
+		return jobs, err
+	}
+
+	return jobs, nil
+}
+
+// ClaimNextJob atomically moves oldest queued job to running state.
+// mongo.ErrNoDocuments is returned when queue is empty.
+func (j *JobStore) ClaimNextJob(owner string) (types.Job, error) {
+	var job types.Job
+	now := time.Now()
+
+	filter := bson.M{"status": types.JobStatusQueued}
+	update := bson.M{
+		"$set": bson.M{
+			"status":       types.JobStatusRunning,
+			"owner":        owner,
+			"started_at":   now,
+			"heartbeat_at": now,
+		},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "created_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	err := j.collection.FindOneAndUpdate(context.TODO(), filter, update, opts).Decode(&job)
+	return job, err
+}
+
+// UpdateProgress stores job progress and heartbeat of job claimed by owner. Returns whether job
+// cancellation was requested, mongo.ErrNoDocuments is returned once job is claimed by another owner.
+func (j *JobStore) UpdateProgress(idParam, owner string, processed, failed, total int) (bool, error) {
+	var job types.Job
+
+	objID, err := primitive.ObjectIDFromHex(idParam)
+	if err != nil {
+		return false, err
+	}
+
+	update := bson.M{"$set": bson.M{
+		"processed":    processed,
+		"failed":       failed,
+		"total":        total,
+		"heartbeat_at": time.Now(),
+	}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	if err := j.collection.FindOneAndUpdate(context.TODO(), bson.M{"_id": objID, "owner": owner}, update, opts).Decode(&job); err != nil {
+		log.Error().Err(err).Msgf("failed to update job progress for %s", idParam)
+		return false, err
+	}
+
+	return job.CancelRequested, nil
+}
+
+// FinishJob stores final status of job claimed by owner, mongo.ErrNoDocuments is returned once job
+// is claimed by another owner
+func (j *JobStore) FinishJob(idParam, owner, status, errMsg string, componentsCount int) error {
+	objID, err := primitive.ObjectIDFromHex(idParam)
+	if err != nil {
+		return err
+	}
+
+	// tokens of github imports stored by older versions are removed once job finishes
+	update := bson.M{
+		"$set": bson.M{
+			"status":           status,
+			"error":            errMsg,
+			"components_count": componentsCount,
+			"finished_at":      time.Now(),
+		},
+		"$unset": bson.M{"active_sbom_id": "", "github_import.token": ""},
+	}
+
+	result, err := j.collection.UpdateOne(context.TODO(), bson.M{"_id": objID, "owner": owner}, update)
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to update job status for %s", idParam)
+		return err
+	} else if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// ReleaseJob moves running job claimed by owner back to queue without counting the claim as an
+// attempt, used when runner shuts down so restarts don't fail healthy jobs. mongo.ErrNoDocuments
+// is returned once job is claimed by another owner.
+func (j *JobStore) ReleaseJob(idParam, owner string) error {
+	objID, err := primitive.ObjectIDFromHex(idParam)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": objID, "owner": owner, "status": types.JobStatusRunning}
+	update := bson.M{
+		"$set": bson.M{"status": types.JobStatusQueued, "owner": ""},
+		"$inc": bson.M{"attempts": -1},
+	}
+
+	result, err := j.collection.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to release job %s", idParam)
+		return err
+	} else if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// UpdateGithubImport stores repo imported by job along with import result
+func (j *JobStore) UpdateGithubImport(idParam string, githubImport types.GithubImportJob) error {
+	objID, err := primitive.ObjectIDFromHex(idParam)
+	if err != nil {
+		return err
+	}
+
+	if _, err := j.collection.UpdateOne(context.TODO(), bson.M{"_id": objID}, bson.M{"$set": bson.M{"github_import": githubImport}}); err != nil {
+		log.Error().Err(err).Msgf("failed to update github import of job %s", idParam)
+		return err
+	}
+
+	return nil
+}
+
+// SetJobSbom makes running job the active job of sbom, so jobs processing several sboms one at a
+// time block other jobs and deletion of the sbom being processed. types.ErrActiveJobExists is
+// returned when sbom already has an active job. Empty sbomId releases sbom of the job.
+func (j *JobStore) SetJobSbom(idParam, sbomId string) error {
+	objID, err := primitive.ObjectIDFromHex(idParam)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{"$set": bson.M{"sbom_id": sbomId, "active_sbom_id": sbomId}}
+	if sbomId == "" {
+		update = bson.M{"$set": bson.M{"sbom_id": ""}, "$unset": bson.M{"active_sbom_id": ""}}
+	}
+
+	_, err = j.collection.UpdateOne(context.TODO(), bson.M{"_id": objID, "status": types.JobStatusRunning}, update)
+	if mongo.IsDuplicateKeyError(err) {
+		return types.ErrActiveJobExists
+	} else if err != nil {
+		log.Error().Err(err).Msgf("failed to set sbom %s of job %s", sbomId, idParam)
+		return err
+	}
+
+	return nil
+}
+
+// CancelJob cancels queued job immediately and requests cancellation of running job
+func (j *JobStore) CancelJob(idParam string, duration int) (types.Job, error) {
+	var job types.Job
+
+	objID, err := primitive.ObjectIDFromHex(idParam)
+	if err != nil {
+		return job, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(duration)*time.Second)
+	defer cancel()
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	// queued jobs are cancelled right away
+	filter := bson.M{"_id": objID, "status": types.JobStatusQueued}
+	update := bson.M{
+		"$set":   bson.M{"status": types.JobStatusCancelled, "cancel_requested": true, "finished_at": time.Now()},
+		"$unset": bson.M{"active_sbom_id": "", "github_import.token": ""},
+	}
+	err = j.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&job)
+	if err != mongo.ErrNoDocuments {
+		return job, err
+	}
+
+	// running jobs are cancelled by the worker processing it
+	filter = bson.M{"_id": objID, "status": types.JobStatusRunning}
+	update = bson.M{"$set": bson.M{"cancel_requested": true}}
+	err = j.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&job)
+	if err != mongo.ErrNoDocuments {
+		return job, err
+	}
+
+	// job has already finished or does not exist
+	return j.GetJobById(idParam, duration)
+}
+
+// RequeueStaleJobs moves running jobs whose worker stopped sending heartbeats back to queue. Jobs
+// which were already claimed maxAttempts times are failed instead, so a job crashing its worker
+// isn't retried forever.
+func (j *JobStore) RequeueStaleJobs(staleAfter time.Duration, maxAttempts int) (int64, error) {
+	now := time.Now()
+	filter := bson.M{
+		"status":       types.JobStatusRunning,
+		"heartbeat_at": bson.M{"$lt": now.Add(-staleAfter)},
+		"attempts":     bson.M{"$gte": maxAttempts},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":      types.JobStatusFailed,
+			"error":       fmt.Sprintf("job was interrupted %d times", maxAttempts),
+			"owner":       "",
+			"finished_at": now,
+		},
+		"$unset": bson.M{"active_sbom_id": "", "github_import.token": ""},
+	}
+
+	result, err := j.collection.UpdateMany(context.TODO(), filter, update)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to fail stale jobs exceeding max attempts")
+		return 0, err
+	} else if result.ModifiedCount > 0 {
+		log.Warn().Msgf("failed %d stale jobs exceeding %d attempts", result.ModifiedCount, maxAttempts)
+	}
+
+	filter["attempts"] = bson.M{"$lt": maxAttempts}
+	update = bson.M{"$set": bson.M{"status": types.JobStatusQueued, "owner": ""}}
+
+	result, err = j.collection.UpdateMany(context.TODO(), filter, update)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to requeue stale jobs")
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
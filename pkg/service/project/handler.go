@@ -1,12 +1,25 @@
 package project
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"slices"
 	"strconv"
+	"time"
 
+	"github.com/dmdhrumilmistry/defect-detect/pkg/attestation"
 	"github.com/dmdhrumilmistry/defect-detect/pkg/config"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/sbomformat"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/sbomquality"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/sbomsign"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/sbomvalidate"
+	sbomservice "github.com/dmdhrumilmistry/defect-detect/pkg/service/sbom"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/service/scan"
 	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
 	"go.mongodb.org/mongo-driver/bson"
@@ -14,18 +27,24 @@ import (
 )
 
 type ProjectHandler struct {
-	store          types.ProjectStore
-	componentStore types.ComponentStore
-	sbomStore      types.SbomStore
-	authStore      types.AuthStore
+	store            types.ProjectStore
+	componentStore   types.ComponentStore
+	sbomStore        types.SbomStore
+	jobStore         types.JobStore
+	attestationStore types.AttestationStore
+	authStore        types.AuthStore
+	sbomManager      *SbomManager
 }
 
-func NewProjectHandler(store types.ProjectStore, sbomStore types.SbomStore, componentStore types.ComponentStore, authStore types.AuthStore) *ProjectHandler {
+func NewProjectHandler(store types.ProjectStore, sbomStore types.SbomStore, componentStore types.ComponentStore, jobStore types.JobStore, attestationStore types.AttestationStore, authStore types.AuthStore) *ProjectHandler {
 	return &ProjectHandler{
-		store:          store,
-		componentStore: componentStore,
-		sbomStore:      sbomStore,
-		authStore:      authStore,
+		store:            store,
+		componentStore:   componentStore,
+		sbomStore:        sbomStore,
+		jobStore:         jobStore,
+		attestationStore: attestationStore,
+		authStore:        authStore,
+		sbomManager:      NewSbomManager(store, sbomStore, componentStore, jobStore),
 	}
 }
 
@@ -36,6 +55,10 @@ func (p *ProjectHandler) RegisterRoutes(r *gin.Engine) {
 	r.GET("/api/v1/project/:id", p.GetProjectById)
 	r.PATCH("/api/v1/project/:id", p.UpdateProjectById)
 	r.DELETE("/api/v1/project/:id", p.DeleteProjectById)
+	r.POST("/api/v1/project/:id/sbom", p.UploadProjectSbom)
+	r.POST("/api/v1/project/:id/attestation", p.UploadProjectAttestation)
+	r.POST("/api/v1/project/:id/lockfile", p.UploadProjectLockfiles)
+	r.POST("/api/v1/project/:id/image", p.UploadProjectImage)
 
 	log.Info().Msg("Project routes registered")
 }
@@ -51,6 +74,18 @@ func (p *ProjectHandler) CreateProject(c *gin.Context) {
 	// ignore provided id
 	payload.Id = ""
 
+	if err := scan.ValidateSchedule(payload.ScanSchedule); err != nil {
+		log.Error().Err(err).Msg("invalid scan schedule")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid scan schedule"})
+		return
+	}
+
+	if err := sbomsign.ValidateTrustedKeys(payload.TrustedKeys); err != nil {
+		log.Error().Err(err).Msg("invalid trusted keys")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	id, err := p.store.AddProject(payload)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -87,6 +122,7 @@ func (p *ProjectHandler) GetProjects(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse data"})
 		return
 	}
+	p.addSbomQuality(sboms)
 
 	total, err := p.store.GetTotalCount(filter)
 	if err != nil {
@@ -123,6 +159,7 @@ func (p *ProjectHandler) GetProjectById(c *gin.Context) {
 
 	// Return the item as JSON
 	if len(projects) > 0 {
+		p.addSbomQuality(projects)
 		c.JSON(http.StatusOK, projects[0])
 		return
 	}
@@ -140,13 +177,15 @@ func (p *ProjectHandler) UpdateProjectById(c *gin.Context) {
 		return
 	}
 
-	idParam := c.Param("id")
-	payload.Id = idParam
-	if err := p.store.UpdateById(payload, config.DefaultConfig.DbQueryTimeout); err != nil {
-		log.Error().Err(err).Msgf("failed to update project details for id %s: %v", idParam, payload)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "failed to update project details",
-		})
+	if err := scan.ValidateSchedule(payload.ScanSchedule); err != nil {
+		log.Error().Err(err).Msg("invalid scan schedule")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid scan schedule"})
+		return
+	}
+
+	if err := sbomsign.ValidateTrustedKeys(payload.TrustedKeys); err != nil {
+		log.Error().Err(err).Msg("invalid trusted keys")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -161,65 +200,432 @@ func (p *ProjectHandler) UpdateProjectById(c *gin.Context) {
 		return
 	}
 
+	idParam := c.Param("id")
+	payload.Id = idParam
+	if err := p.store.UpdateById(payload, config.DefaultConfig.DbQueryTimeout); err != nil {
+		log.Error().Err(err).Msgf("failed to update project details for id %s: %v", idParam, payload)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to update project details",
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"msg": "project details updated successfully"})
 }
 
-// curl http://localhost:8080/api/v1/project/{project_id}
-func (s *ProjectHandler) DeleteProjectById(c *gin.Context) {
-	// Get the ID from the path parameter
-	var deleteSboms bool
-	var msg string
+// returns content of optional multipart file field, nil when file is not uploaded
+func readOptionalFile(c *gin.Context, field string) ([]byte, error) {
+	file, err := c.FormFile(field)
+	if err == http.ErrMissingFile {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	fileContent, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer fileContent.Close()
+
+	return io.ReadAll(fileContent)
+}
+
+// adds quality of project sboms to projects
+func (p *ProjectHandler) addSbomQuality(projects []types.Project) {
+	var sbomIds []string
+	for _, project := range projects {
+		sbomIds = append(sbomIds, project.Sboms...)
+	}
+	if len(sbomIds) == 0 {
+		return
+	}
+
+	qualities, err := p.sbomStore.GetSbomQualities(sbomIds, config.DefaultConfig.DbQueryTimeout)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to fetch quality of project sboms")
+		return
+	}
+
+	for i := range projects {
+		for _, sbomId := range projects[i].Sboms {
+			sbomQuality := types.ProjectSbomQuality{SbomId: sbomId}
+			if quality := qualities[sbomId]; quality != nil {
+				sbomQuality.Score = &quality.Score
+				sbomQuality.NtiaCompliant = quality.NtiaCompliant
+			}
+			projects[i].SbomQuality = append(projects[i].SbomQuality, sbomQuality)
+		}
+	}
+}
+
+// uploads sbom as latest project sbom and queues its analysis. Sboms beyond sboms_to_retain are
+// removed from the project and deleted along with their components unless another project uses them.
+// curl -X POST -F "sbom=@example-sbom.json" http://localhost:8080/api/v1/project/{project_id}/sbom
+func (p *ProjectHandler) UploadProjectSbom(c *gin.Context) {
 	idParam := c.Param("id")
-	deleteSbom, _ := c.GetQuery("delete_sbom")
 
-	deleteSboms, err := strconv.ParseBool(deleteSbom)
+	projects, err := p.store.GetProjectById(idParam, config.DefaultConfig.DbQueryTimeout)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to parse delete_sbom param. Using default value: false")
-		deleteSboms = false
+		log.Error().Err(err).Msgf("failed to fetch project %s", idParam)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch project"})
+		return
+	} else if len(projects) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+		return
+	}
+
+	sbom, content, ok := sbomservice.ParseUploadedSbom(c)
+	if !ok {
+		return
+	}
+
+	detachedSignature, err := readOptionalFile(c, "signature")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid signature file"})
+		return
+	}
+
+	sbom.Signature = sbomsign.Verify(content, detachedSignature, projects[0].TrustedKeys)
+	if projects[0].RequireSignature && (sbom.Signature == nil || !sbom.Signature.Verified) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "project requires sbom signed with a trusted key", "signature_verification": sbom.Signature})
+		return
+	}
+
+	_, resp, ok := p.addProjectSbom(c, projects[0], sbom)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusAccepted, resp)
+}
+
+// generates sbom from lockfiles and manifests of project, which is then uploaded as latest project sbom.
+// Name of generated root component defaults to project name.
+// curl -X POST -F "lockfile=@go.mod" -F "lockfile=@go.sum" http://localhost:8080/api/v1/project/{project_id}/lockfile
+func (p *ProjectHandler) UploadProjectLockfiles(c *gin.Context) {
+	p.uploadGeneratedSbom(c, sbomservice.ParseUploadedLockfiles)
+}
+
+// generates sbom of os packages installed in container image tarball, which is then uploaded as
+// latest project sbom. Image name defaults to its first repo tag and then to project name.
+// curl -X POST -F "image=@app.tar" http://localhost:8080/api/v1/project/{project_id}/image
+func (p *ProjectHandler) UploadProjectImage(c *gin.Context) {
+	p.uploadGeneratedSbom(c, sbomservice.ParseUploadedImage)
+}
+
+// uploads sbom generated by generate as latest project sbom. Project name is passed to generate as
+// default name of the root component.
+func (p *ProjectHandler) uploadGeneratedSbom(c *gin.Context, generate func(c *gin.Context, defaultName string) (types.Sbom, bool)) {
+	idParam := c.Param("id")
+
+	projects, err := p.store.GetProjectById(idParam, config.DefaultConfig.DbQueryTimeout)
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to fetch project %s", idParam)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch project"})
+		return
+	} else if len(projects) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+		return
+	}
+
+	// generated sboms are never signed
+	if projects[0].RequireSignature {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "project requires sbom signed with a trusted key"})
+		return
+	}
+
+	sbom, ok := generate(c, projects[0].Name)
+	if !ok {
+		return
+	}
+
+	_, resp, ok := p.addProjectSbom(c, projects[0], sbom)
+	if !ok {
+		return
 	}
 
-	if deleteSboms {
-		projects, err := s.store.GetProjectById(idParam, config.DefaultConfig.DbQueryTimeout)
+	c.JSON(http.StatusAccepted, resp)
+}
+
+// stores sbom as latest project sbom, links it to provenance of its artifact and queues its analysis.
+// Returns id of stored sbom along with upload response, error response is written to the request
+// context when sbom can not be added.
+func (p *ProjectHandler) addProjectSbom(c *gin.Context, project types.Project, sbom types.Sbom) (string, gin.H, bool) {
+	quality := sbomquality.Score(sbom)
+	sbom.Quality = &quality
+	if reason := GetQualityRejection(project, quality); reason != "" {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": reason, "quality": quality})
+		return "", nil, false
+	}
 
-		if err == mongo.ErrNoDocuments {
-			c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+	// hashes of metadata component are only claimed by sbom, they identify the artifact only when
+	// sbom is signed with a trusted key of project
+	if sbom.Signature != nil && sbom.Signature.Verified {
+		sbom.ArtifactDigests = utils.MergeUnique(sbom.ArtifactDigests, attestation.GetSbomArtifactDigests(sbom))
+	}
+
+	// provenance may have been attested to the project before sbom was uploaded
+	provenance, err := p.attestationStore.GetProvenanceByDigests(project.Id, sbom.ArtifactDigests, config.DefaultConfig.DbQueryTimeout)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch provenance of sbom artifact"})
+		return "", nil, false
+	}
+	sbom.Provenance = provenance
+
+	// signature is verified using trusted keys of project, sbom shared by projects keeps result of each project
+	if sbom.Signature != nil {
+		sbom.ProjectSignatures = map[string]types.SbomSignature{project.Id: *sbom.Signature}
+	}
+
+	// duplicate sbom is added to project using id of the stored sbom
+	sbomId, err := p.sbomStore.AddComponentSbom(sbom)
+	duplicate := errors.Is(err, types.ErrDuplicateSbom)
+	if errors.Is(err, types.ErrSbomRevisionConflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return "", nil, false
+	} else if err != nil && !duplicate {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to upload component SBOM"})
+		return "", nil, false
+	}
+
+	if duplicate && sbom.Signature != nil {
+		if err := p.sbomStore.UpdateSignature(sbomId, project.Id, *sbom.Signature, config.DefaultConfig.DbQueryTimeout); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store signature verification", "sbom_id": sbomId})
+			return "", nil, false
+		}
+	}
+
+	if duplicate && len(sbom.ArtifactDigests) > 0 {
+		if err := p.sbomStore.AddArtifactDigests(sbomId, sbom.ArtifactDigests, config.DefaultConfig.DbQueryTimeout); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store sbom artifact digests", "sbom_id": sbomId})
+			return "", nil, false
+		}
+
+		if provenance != nil {
+			if _, err := p.sbomStore.LinkProvenance([]string{sbomId}, sbom.ArtifactDigests, *provenance, config.DefaultConfig.DbQueryTimeout); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to link provenance to sbom", "sbom_id": sbomId})
+				return "", nil, false
+			}
+		}
+	}
+
+	evicted, err := p.sbomManager.AddProjectSbom(project.Id, sbomId)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+		return "", nil, false
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to add sbom to project", "sbom_id": sbomId})
+		return "", nil, false
+	}
+
+	resp := gin.H{
+		"message":   "SBOM uploaded successfully",
+		"sbom_id":   sbomId,
+		"duplicate": duplicate,
+		"format":    sbom.OriginalFormat,
+		"evicted":   evicted,
+	}
+	if sbom.ConversionLoss != nil {
+		resp["conversion_loss"] = sbom.ConversionLoss
+	}
+	if limitation := sbomvalidate.GetSchemaLimitation(sbomformat.Format(sbom.OriginalFormat)); limitation != "" {
+		resp["schema_validation"] = limitation
+	}
+	if sbom.Signature != nil {
+		resp["signature_verification"] = sbom.Signature
+	}
+	if sbom.Provenance != nil {
+		resp["provenance"] = sbom.Provenance
+	}
+
+	jobId, err := p.sbomManager.QueueAnalysis(sbomId, duplicate)
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to queue analysis of sbom %s", sbomId)
+		resp["error"] = "failed to queue sbom analysis"
+	} else if jobId != "" {
+		resp["job_id"] = jobId
+	}
+
+	return sbomId, resp, true
+}
+
+// verifies in-toto attestation in DSSE envelope using trusted keys of project. Sbom predicates are
+// uploaded as latest project sbom and SLSA provenance is linked to sboms of attested artifacts.
+// curl -X POST -F "attestation=@sbom.intoto.jsonl" http://localhost:8080/api/v1/project/{project_id}/attestation
+func (p *ProjectHandler) UploadProjectAttestation(c *gin.Context) {
+	idParam := c.Param("id")
+
+	projects, err := p.store.GetProjectById(idParam, config.DefaultConfig.DbQueryTimeout)
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to fetch project %s", idParam)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch project"})
+		return
+	} else if len(projects) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+		return
+	}
+
+	content, err := readOptionalFile(c, "attestation")
+	if err != nil || content == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to upload file"})
+		return
+	}
+
+	envelope, statement, payload, err := attestation.Parse(content)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	} else if !statement.IsSbom() && !statement.IsProvenance() {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": fmt.Sprintf("unsupported predicate type %q", statement.PredicateType)})
+		return
+	}
+
+	// unverified attestations are never stored since provenance can not be trusted
+	signature := attestation.Verify(envelope, payload, projects[0].TrustedKeys)
+	if !signature.Verified {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "attestation is not signed with a trusted key of project", "signature_verification": signature})
+		return
+	}
+
+	record := types.Attestation{
+		ProjectId:      idParam,
+		PayloadType:    envelope.PayloadType,
+		StatementType:  statement.Type,
+		PredicateType:  statement.PredicateType,
+		Subjects:       statement.Subject,
+		SubjectDigests: statement.SubjectDigests(),
+		Signature:      signature,
+		CreatedAt:      time.Now(),
+	}
+
+	if statement.IsProvenance() {
+		provenance, err := attestation.ExtractProvenance(statement)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
-		} else if err != nil {
-			log.Error().Err(err).Msg("failed to fetch project")
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch project"})
+		}
+		provenance.CreatedAt = record.CreatedAt
+		record.Provenance = &provenance
+
+		attestationId, err := p.attestationStore.AddAttestation(record)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store attestation"})
 			return
 		}
 
-		deleteCount, err := s.componentStore.DeleteByIds(projects[0].Sboms, "sbom_id", config.DefaultConfig.DbQueryTimeout)
+		provenance.AttestationId = attestationId
+		linked, err := p.sbomStore.LinkProvenance(projects[0].Sboms, record.SubjectDigests, provenance, config.DefaultConfig.DbQueryTimeout)
 		if err != nil {
-			log.Error().Err(err).Msg("failed to delete sboms components")
-			msg += "failed to delete sbom components."
-		} else {
-			msg += fmt.Sprintf("%d sbom components deleted successfully.", deleteCount)
-			log.Info().Msgf("Total %d sbom components deleted", deleteCount)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to link provenance to sboms", "attestation_id": attestationId})
+			return
 		}
 
-		deleteCount, err = s.sbomStore.DeleteByIds(projects[0].Sboms, config.DefaultConfig.DbQueryTimeout)
+		c.JSON(http.StatusOK, gin.H{
+			"message":                "Provenance attestation uploaded successfully",
+			"attestation_id":         attestationId,
+			"provenance":             provenance,
+			"linked_sboms":           linked,
+			"signature_verification": signature,
+		})
+		return
+	}
+
+	sbomContent, err := statement.SbomContent()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sbom, ok := sbomservice.ParseSbomContent(c, sbomContent, "", "")
+	if !ok {
+		return
+	}
+	sbom.Signature = &signature
+	sbom.ArtifactDigests = record.SubjectDigests
+
+	sbomId, resp, ok := p.addProjectSbom(c, projects[0], sbom)
+	if !ok {
+		return
+	}
+
+	record.SbomId = sbomId
+	attestationId, err := p.attestationStore.AddAttestation(record)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store attestation", "sbom_id": sbomId})
+		return
+	}
+	resp["attestation_id"] = attestationId
+
+	c.JSON(http.StatusAccepted, resp)
+}
+
+// deletes project. Use delete_sbom=true to delete project sboms which are not used by other
+// projects along with their components in the same transaction.
+// curl -X DELETE "http://localhost:8080/api/v1/project/{project_id}?delete_sbom=true"
+func (s *ProjectHandler) DeleteProjectById(c *gin.Context) {
+	// Get the ID from the path parameter
+	idParam := c.Param("id")
+	deleteSbom, _ := c.GetQuery("delete_sbom")
+
+	deleteSboms, err := strconv.ParseBool(deleteSbom)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to parse delete_sbom param. Using default value: false")
+		deleteSboms = false
+	}
+
+	if !deleteSboms {
+		_, err = s.store.DeleteById(idParam, config.DefaultConfig.DbQueryTimeout)
 		if err != nil {
-			log.Error().Err(err).Msg("failed to delete sboms")
-			msg += "failed to delete sboms."
-		} else {
-			msg += fmt.Sprintf("%d sboms deleted successfully.", deleteCount)
-			log.Info().Msgf("Total %d sboms deleted", deleteCount)
+			log.Error().Err(err).Msgf("failed to delete project with id: %s", idParam)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete project"})
+			return
 		}
+		log.Info().Msgf("Project %s deleted successfully", idParam)
 
+		c.JSON(http.StatusNoContent, gin.H{"msg": "Project deleted successfully."})
+		return
 	}
 
-	_, err = s.store.DeleteById(idParam, config.DefaultConfig.DbQueryTimeout)
+	projects, err := s.store.GetProjectById(idParam, config.DefaultConfig.DbQueryTimeout)
 	if err != nil {
-		log.Error().Err(err).Msgf("failed to delete project with id: %s", idParam)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to delete project. %s", msg)})
+		log.Error().Err(err).Msg("failed to fetch project")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch project"})
+		return
+	} else if len(projects) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
 		return
 	}
-	log.Info().Msgf("Project %s deleted successfully", idParam)
 
-	c.JSON(http.StatusNoContent, gin.H{
-		"msg": fmt.Sprintf("Project deleted successfully. %s", msg),
+	// sboms shared with other projects are kept
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(config.DefaultConfig.DbQueryTimeout)*time.Second)
+	used, err := s.store.GetUsedSbomIds(ctx, projects[0].Sboms, idParam)
+	cancel()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check projects using sboms"})
+		return
+	}
+
+	sbomIds := []string{}
+	for _, sbomId := range projects[0].Sboms {
+		if !slices.Contains(used, sbomId) {
+			sbomIds = append(sbomIds, sbomId)
+		}
+	}
+
+	deletion, err := sbomservice.CascadeDeleteSboms(s.sbomStore, s.componentStore, s.store, s.jobStore, sbomIds, false, func(ctx context.Context) error {
+		_, err := s.store.DeleteProject(ctx, idParam)
+		return err
 	})
+	if errors.Is(err, types.ErrSbomAnalysisRunning) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "running_job_ids": deletion.RunningJobIds})
+		return
+	} else if err != nil {
+		log.Error().Err(err).Msgf("failed to delete project with id: %s", idParam)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete project"})
+		return
+	}
+	log.Info().Msgf("Project %s deleted successfully along with %d sboms", idParam, len(deletion.SbomIds))
 
+	c.JSON(http.StatusNoContent, gin.H{"msg": "Project deleted successfully.", "deletion": deletion})
 }
@@ -0,0 +1,171 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/dmdhrumilmistry/defect-detect/pkg/config"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/service/job"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// SbomManager adds stored sboms to projects, queues their analysis and deletes sboms evicted from projects
+type SbomManager struct {
+	store          types.ProjectStore
+	sbomStore      types.SbomStore
+	componentStore types.ComponentStore
+	jobStore       types.JobStore
+}
+
+func NewSbomManager(store types.ProjectStore, sbomStore types.SbomStore, componentStore types.ComponentStore, jobStore types.JobStore) *SbomManager {
+	return &SbomManager{
+		store:          store,
+		sbomStore:      sbomStore,
+		componentStore: componentStore,
+		jobStore:       jobStore,
+	}
+}
+
+// GetQualityRejection returns reason for rejecting sbom when its quality doesn't meet project requirements
+func GetQualityRejection(project types.Project, quality types.SbomQuality) string {
+	if project.MinQualityScore > 0 && quality.Score < project.MinQualityScore {
+		return fmt.Sprintf("sbom quality score %d is lower than minimum quality score %d of project", quality.Score, project.MinQualityScore)
+	}
+
+	if project.RequireNtiaCompliance && !quality.NtiaCompliant {
+		return "sbom does not contain NTIA minimum elements required by project"
+	}
+
+	return ""
+}
+
+// QueueAnalysis queues analysis of sbom and returns job id. Already queued or analyzed duplicate sboms are not re-queued.
+func (m *SbomManager) QueueAnalysis(sbomId string, duplicate bool) (string, error) {
+	return job.QueueAnalysis(m.jobStore, m.sbomStore, sbomId, duplicate)
+}
+
+// deletes sboms evicted from project using ctx of the transaction adding sbom to the project, so
+// the project keeps its sboms when deletion fails. Sboms used by other projects are kept. Sboms
+// with running analysis are appended back to the project, since analysis would store components
+// after deletion, and are evicted again by a later upload. Returns eviction details of each sbom
+// along with sboms whose blobs can be deleted once transaction is committed.
+func (m *SbomManager) deleteEvictedSboms(ctx context.Context, projectId string, evicted []string) ([]types.EvictedSbom, []types.Sbom, error) {
+	evictedSboms := []types.EvictedSbom{}
+	if len(evicted) == 0 {
+		return evictedSboms, nil, nil
+	}
+
+	// sbom can be shared with other projects
+	used, err := m.store.GetUsedSbomIds(ctx, evicted, projectId)
+	if err != nil {
+		return evictedSboms, nil, err
+	}
+
+	blobs := []types.Sbom{}
+	running := []string{}
+	for _, sbomId := range evicted {
+		evictedSbom := types.EvictedSbom{SbomId: sbomId}
+		if slices.Contains(used, sbomId) {
+			evictedSboms = append(evictedSboms, evictedSbom)
+			continue
+		}
+
+		// stop queued analysis so components are not stored after deletion
+		if _, err := m.jobStore.CancelQueuedJobsBySbomIds(ctx, []string{sbomId}); err != nil {
+			return evictedSboms, nil, err
+		}
+		if isRunning, err := m.jobStore.HasRunningJobs(ctx, []string{sbomId}); err != nil {
+			return evictedSboms, nil, err
+		} else if isRunning {
+			evictedSbom.Error = "sbom analysis is running, sbom is kept in project until analysis finishes"
+			evictedSboms = append(evictedSboms, evictedSbom)
+			running = append(running, sbomId)
+			continue
+		}
+
+		// blob keys are only available until sbom document is deleted
+		sbomBlobs, err := m.sbomStore.GetSbomBlobs([]string{sbomId}, config.DefaultConfig.DbQueryTimeout)
+		if err != nil {
+			return evictedSboms, nil, err
+		}
+
+		componentsCount, err := m.componentStore.DeleteBySbomIds(ctx, []string{sbomId})
+		if err != nil {
+			return evictedSboms, nil, err
+		}
+		if _, err := m.sbomStore.DeleteDocuments(ctx, []string{sbomId}); err != nil {
+			return evictedSboms, nil, err
+		}
+
+		evictedSbom.Deleted = true
+		evictedSbom.ComponentsDeleted = componentsCount
+		evictedSboms = append(evictedSboms, evictedSbom)
+		blobs = append(blobs, sbomBlobs...)
+	}
+
+	if len(running) > 0 {
+		if err := m.store.AppendSboms(ctx, projectId, running); err != nil {
+			return evictedSboms, nil, err
+		}
+	}
+
+	return evictedSboms, blobs, nil
+}
+
+// AddProjectSbom adds stored sbom as latest project sbom and deletes sboms evicted from project
+// along with their components unless another project uses them. Project update and deletion of
+// evicted sboms happen in a single transaction. mongo.ErrNoDocuments is returned when project
+// does not exist.
+func (m *SbomManager) AddProjectSbom(projectId, sbomId string) ([]types.EvictedSbom, error) {
+	var evictedSboms []types.EvictedSbom
+	var blobs []types.Sbom
+	_, err := m.sbomStore.WithTransaction(config.DefaultConfig.DbQueryTimeout, func(ctx context.Context) error {
+		evicted, err := m.store.AddSbom(ctx, projectId, sbomId)
+		if err != nil {
+			return err
+		}
+
+		evictedSboms, blobs, err = m.deleteEvictedSboms(ctx, projectId, evicted)
+		return err
+	})
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			log.Error().Err(err).Msgf("failed to add sbom %s to project %s", sbomId, projectId)
+		}
+		return nil, err
+	}
+
+	m.sbomStore.DeleteBlobs(blobs)
+	for _, evictedSbom := range evictedSboms {
+		if evictedSbom.Deleted {
+			log.Info().Msgf("evicted sbom %s deleted along with %d components", evictedSbom.SbomId, evictedSbom.ComponentsDeleted)
+		}
+	}
+
+	return evictedSboms, nil
+}
+
+// AddSbom adds stored sbom as latest project sbom and deletes sboms evicted from project. Sboms not
+// meeting project requirements are not added and reason for rejecting them is returned.
+func (m *SbomManager) AddSbom(project types.Project, sbom types.Sbom, sbomId string) ([]types.EvictedSbom, string, error) {
+	if project.RequireSignature && (sbom.Signature == nil || !sbom.Signature.Verified) {
+		return nil, "project requires sbom signed with a trusted key", nil
+	}
+
+	if sbom.Quality != nil {
+		if reason := GetQualityRejection(project, *sbom.Quality); reason != "" {
+			return nil, reason, nil
+		}
+	}
+
+	evicted, err := m.AddProjectSbom(project.Id, sbomId)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return evicted, "", nil
+}
@@ -3,6 +3,7 @@ package project
 import (
 	"context"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/dmdhrumilmistry/defect-detect/pkg/config"
@@ -111,6 +112,53 @@ func (p *ProjectStore) UpdateById(payload types.Project, duration int) error {
 	return nil
 }
 
+// Prepends sbom to project sboms and drops sboms beyond sboms_to_retain in a single update
+// so concurrent uploads can not lose sboms. Returns ids of sboms dropped from the project. ctx
+// may be a transaction.
+func (p *ProjectStore) AddSbom(ctx context.Context, projectId, sbomId string) ([]string, error) {
+	objectId, err := primitive.ObjectIDFromHex(projectId)
+	if err != nil {
+		log.Error().Err(err).Msg("invalid object id")
+		return nil, err
+	}
+
+	// re-uploaded sbom is moved to 0th position instead of being added twice
+	sboms := bson.M{"$concatArrays": bson.A{
+		bson.A{sbomId},
+		bson.M{"$filter": bson.M{
+			"input": bson.M{"$ifNull": bson.A{"$sboms", bson.A{}}},
+			"cond":  bson.M{"$ne": bson.A{"$$this", sbomId}},
+		}},
+	}}
+	update := bson.A{bson.M{"$set": bson.M{
+		"sboms": bson.M{"$slice": bson.A{sboms, "$sboms_to_retain"}},
+	}}}
+
+	var project types.Project
+	findOptions := options.FindOneAndUpdate().SetReturnDocument(options.Before)
+	if err := p.collection.FindOneAndUpdate(ctx, bson.M{"_id": objectId}, update, findOptions).Decode(&project); err != nil {
+		if err != mongo.ErrNoDocuments {
+			log.Error().Err(err).Msgf("failed to add sbom %s to project %s", sbomId, projectId)
+		}
+		return nil, err
+	}
+
+	// replay update on previous state to find evicted sboms
+	retained := []string{sbomId}
+	for _, id := range project.Sboms {
+		if id != sbomId {
+			retained = append(retained, id)
+		}
+	}
+
+	evicted := []string{}
+	if len(retained) > project.SbomsToRetain {
+		evicted = retained[project.SbomsToRetain:]
+	}
+
+	return evicted, nil
+}
+
 func (c *ProjectStore) ValidateIds(ids []string) error {
 	// Convert string IDs to ObjectIDs
 	var objectIDs []primitive.ObjectID
@@ -141,6 +189,84 @@ func (c *ProjectStore) ValidateIds(ids []string) error {
 	return nil
 }
 
+// returns ids of projects referencing any of the sboms
+func (p *ProjectStore) GetProjectIdsBySbomIds(sbomIds []string, duration int) ([]string, error) {
+	ids := []string{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(duration)*time.Second)
+	defer cancel()
+
+	cursor, err := p.collection.Find(ctx, bson.M{"sboms": bson.M{"$in": sbomIds}}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return ids, err
+	}
+	defer cursor.Close(ctx)
+
+	var projects []types.Project
+	if err := cursor.All(ctx, &projects); err != nil {
+		return ids, err
+	}
+
+	for _, project := range projects {
+		ids = append(ids, project.Id)
+	}
+
+	return ids, nil
+}
+
+// appends sboms to the end of project sboms using ctx, which may be a transaction. Sboms beyond
+// sboms_to_retain are evicted again by the next added sbom.
+func (p *ProjectStore) AppendSboms(ctx context.Context, projectId string, sbomIds []string) error {
+	objectId, err := primitive.ObjectIDFromHex(projectId)
+	if err != nil {
+		return err
+	}
+
+	if _, err := p.collection.UpdateOne(ctx, bson.M{"_id": objectId}, bson.M{"$push": bson.M{"sboms": bson.M{"$each": sbomIds}}}); err != nil {
+		log.Error().Err(err).Msgf("failed to append sboms to project %s", projectId)
+		return err
+	}
+
+	return nil
+}
+
+// returns ids of sboms which are still used by any project other than excluded project using ctx,
+// which may be a transaction
+func (p *ProjectStore) GetUsedSbomIds(ctx context.Context, sbomIds []string, excludeProjectId string) ([]string, error) {
+	ids := []string{}
+
+	filter := bson.M{"sboms": bson.M{"$in": sbomIds}}
+	if objectId, err := primitive.ObjectIDFromHex(excludeProjectId); err == nil {
+		filter["_id"] = bson.M{"$ne": objectId}
+	}
+
+	values, err := p.collection.Distinct(ctx, "sboms", filter)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to fetch sboms used by projects")
+		return ids, err
+	}
+
+	for _, value := range values {
+		if id, ok := value.(string); ok && slices.Contains(sbomIds, id) {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
+
+// removes sboms from all projects using ctx, which may be a transaction. Returns number of
+// updated projects.
+func (p *ProjectStore) RemoveSboms(ctx context.Context, sbomIds []string) (int64, error) {
+	result, err := p.collection.UpdateMany(ctx, bson.M{"sboms": bson.M{"$in": sbomIds}}, bson.M{"$pullAll": bson.M{"sboms": sbomIds}})
+	if err != nil {
+		log.Error().Err(err).Msg("failed to remove sboms from projects")
+		return -1, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
 func (p *ProjectStore) DeleteByIds(idParams []string, duration int) (int64, error) {
 	// Convert string IDs to ObjectIDs
 	var objectIDs []primitive.ObjectID
@@ -173,6 +299,22 @@ func (p *ProjectStore) DeleteById(idParam string, duration int) (int64, error) {
 	return p.DeleteByIds([]string{idParam}, duration)
 }
 
+// deletes project using ctx, which may be a transaction
+func (p *ProjectStore) DeleteProject(ctx context.Context, idParam string) (int64, error) {
+	objectId, err := primitive.ObjectIDFromHex(idParam)
+	if err != nil {
+		return -1, err
+	}
+
+	result, err := p.collection.DeleteOne(ctx, bson.M{"_id": objectId})
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to delete project %s", idParam)
+		return -1, err
+	}
+
+	return result.DeletedCount, nil
+}
+
 func (p *ProjectStore) GetTotalCount(filter interface{}) (int64, error) {
 	// Get total count of documents
 	total, err := p.collection.CountDocuments(context.TODO(), filter)
@@ -0,0 +1,124 @@
+package sbom
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/dmdhrumilmistry/defect-detect/pkg/config"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+	"github.com/rs/zerolog/log"
+)
+
+// deletes sboms along with their components and findings, see CascadeDeleteSboms
+func (s *ComponentSbomHandler) deleteSboms(ids []string, dryRun bool) (types.SbomDeletion, error) {
+	return CascadeDeleteSboms(s.store, s.componentStore, s.projectStore, s.jobStore, ids, dryRun, nil)
+}
+
+// CascadeDeleteSboms deletes sboms along with their components and findings, removes them from
+// projects and cancels their queued jobs in a single transaction. fn is run in the same
+// transaction when it is not nil, even when none of the sboms exist. ErrSbomAnalysisRunning is
+// returned while a job of any sbom is running. Blobs of sboms are deleted once transaction is
+// committed. Dry run only reports what would be deleted.
+func CascadeDeleteSboms(sbomStore types.SbomStore, componentStore types.ComponentStore, projectStore types.ProjectStore, jobStore types.JobStore, ids []string, dryRun bool, fn func(ctx context.Context) error) (types.SbomDeletion, error) {
+	deletion := types.SbomDeletion{
+		DryRun:          dryRun,
+		SbomIds:         []string{},
+		NotFound:        []string{},
+		ProjectIds:      []string{},
+		CancelledJobIds: []string{},
+		RunningJobIds:   []string{},
+	}
+
+	sboms, err := sbomStore.GetSbomBlobs(ids, config.DefaultConfig.DbQueryTimeout)
+	if err != nil {
+		return deletion, err
+	}
+	for _, sbom := range sboms {
+		deletion.SbomIds = append(deletion.SbomIds, sbom.Id)
+	}
+	for _, id := range ids {
+		if !slices.Contains(deletion.SbomIds, id) {
+			deletion.NotFound = append(deletion.NotFound, id)
+		}
+	}
+	if len(deletion.SbomIds) == 0 && fn == nil {
+		return deletion, nil
+	}
+
+	deletion.ComponentsDeleted, deletion.FindingsDeleted, err = componentStore.CountBySbomIds(deletion.SbomIds, config.DefaultConfig.DbQueryTimeout)
+	if err != nil {
+		return deletion, err
+	}
+
+	deletion.ProjectIds, err = projectStore.GetProjectIdsBySbomIds(deletion.SbomIds, config.DefaultConfig.DbQueryTimeout)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to fetch projects of sboms")
+		return deletion, err
+	}
+
+	// running analysis would store components of deleted sboms, so deletion is refused until the
+	// job finishes. Queued jobs are cancelled along with deletion.
+	jobs, err := jobStore.GetActiveJobsBySbomIds(deletion.SbomIds, config.DefaultConfig.DbQueryTimeout)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to fetch active jobs of sboms")
+		return deletion, err
+	}
+	for _, job := range jobs {
+		if job.Status == types.JobStatusRunning {
+			deletion.RunningJobIds = append(deletion.RunningJobIds, job.Id)
+		} else {
+			deletion.CancelledJobIds = append(deletion.CancelledJobIds, job.Id)
+		}
+	}
+
+	if dryRun {
+		return deletion, nil
+	} else if len(deletion.RunningJobIds) > 0 {
+		return deletion, types.ErrSbomAnalysisRunning
+	}
+
+	deletion.Transaction, err = sbomStore.WithTransaction(config.DefaultConfig.DbQueryTimeout, func(ctx context.Context) error {
+		if _, err := jobStore.CancelQueuedJobsBySbomIds(ctx, deletion.SbomIds); err != nil {
+			return err
+		}
+
+		// job may have been claimed after active jobs were fetched
+		if running, err := jobStore.HasRunningJobs(ctx, deletion.SbomIds); err != nil {
+			return err
+		} else if running {
+			return types.ErrSbomAnalysisRunning
+		}
+
+		if _, err := projectStore.RemoveSboms(ctx, deletion.SbomIds); err != nil {
+			return err
+		}
+
+		componentsDeleted, err := componentStore.DeleteBySbomIds(ctx, deletion.SbomIds)
+		if err != nil {
+			return err
+		}
+		deletion.ComponentsDeleted = componentsDeleted
+
+		if _, err := sbomStore.DeleteDocuments(ctx, deletion.SbomIds); err != nil {
+			return err
+		}
+
+		if fn != nil {
+			return fn(ctx)
+		}
+
+		return nil
+	})
+	if errors.Is(err, types.ErrSbomAnalysisRunning) {
+		return deletion, err
+	} else if err != nil {
+		log.Error().Err(err).Msgf("failed to delete sboms %v", deletion.SbomIds)
+		return deletion, err
+	}
+
+	sbomStore.DeleteBlobs(sboms)
+	log.Info().Msgf("deleted sboms %v along with %d components", deletion.SbomIds, deletion.ComponentsDeleted)
+
+	return deletion, nil
+}
@@ -3,16 +3,27 @@ package sbom
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"regexp"
+	"slices"
 	"strconv"
 
 	"github.com/CycloneDX/cyclonedx-go"
 	"github.com/dmdhrumilmistry/defect-detect/pkg/config"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/containerimage"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/lockfile"
 	"github.com/dmdhrumilmistry/defect-detect/pkg/sbomconvert"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/sbomdiff"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/sbomexport"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/sbomformat"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/sbommerge"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/sbomquality"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/sbomvalidate"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/service/job"
 	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
@@ -20,15 +31,24 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// lockfiles accepted by a single sbom generation request
+const maxLockfiles = 20
+
 type ComponentSbomHandler struct {
-	authStore types.AuthStore
-	store     types.SbomStore
+	authStore      types.AuthStore
+	store          types.SbomStore
+	componentStore types.ComponentStore
+	projectStore   types.ProjectStore
+	jobStore       types.JobStore
 }
 
-func NewComponentSbomHandler(store types.SbomStore, authStore types.AuthStore) *ComponentSbomHandler {
+func NewComponentSbomHandler(store types.SbomStore, componentStore types.ComponentStore, projectStore types.ProjectStore, jobStore types.JobStore, authStore types.AuthStore) *ComponentSbomHandler {
 	return &ComponentSbomHandler{
-		store:     store,
-		authStore: authStore,
+		store:          store,
+		componentStore: componentStore,
+		projectStore:   projectStore,
+		jobStore:       jobStore,
+		authStore:      authStore,
 	}
 }
 
@@ -36,48 +56,325 @@ func (s *ComponentSbomHandler) RegisterRoutes(r *gin.Engine, authStore types.Aut
 	// api v1
 	r.POST("/api/v1/sbom", s.UploadSbomHandler)
 	r.GET("/api/v1/sbom", s.GetSboms)
+	r.GET("/api/v1/sbom/diff", s.DiffSboms)
+	r.POST("/api/v1/sbom/merge", s.MergeSboms)
+	r.POST("/api/v1/sbom/delete", s.DeleteSboms)
 	r.GET("/api/v1/sbom/:id", s.GetSbomById)
+	r.DELETE("/api/v1/sbom/:id", s.DeleteSbomById)
+	r.GET("/api/v1/sbom/:id/original", s.GetOriginalSbomById)
+	r.GET("/api/v1/sbom/:id/revisions", s.GetSbomRevisions)
+	r.GET("/api/v1/sbom/:id/export", s.ExportSbom)
+	r.GET("/api/v1/sbom/:id/quality", s.GetSbomQuality)
 	r.GET("/api/v1/sbom/getByComponentName", s.GetSbomByName)
 	r.POST("/api/v1/sbom/convert", s.ConvertSbom)
-	r.POST("/api/v1/sbom/githubImport", s.ImportGithubRepo)
+	r.POST("/api/v1/sbom/generate", s.GenerateSbom)
+	r.POST("/api/v1/sbom/image", s.GenerateImageSbom)
 
 	log.Info().Msg("sbom routes registered")
 }
 
+// accepts cyclonedx sbom in json or xml format and spdx 2.2/2.3 sbom in json, tag-value or yaml format.
+// spdx sboms are converted to cyclonedx and uploaded document is stored along with the sbom.
 // curl -X POST -F "sbom=@example-sbom.json" http://localhost:8080/api/v1/sbom
+// curl -X POST -F "sbom=@bom.xml" http://localhost:8080/api/v1/sbom
+// curl -X POST -F "sbom=@example.spdx" http://localhost:8080/api/v1/sbom
 func (s *ComponentSbomHandler) UploadSbomHandler(c *gin.Context) {
+	sbom, _, ok := ParseUploadedSbom(c)
+	if !ok {
+		return
+	}
+
+	s.storeSbom(c, sbom, false)
+}
+
+// ParseUploadedSbom reads sbom from multipart "sbom" field, validates it and converts spdx sboms
+// to cyclonedx. Uploaded content is returned along with sbom for signature verification. Error
+// response is written to the request context when sbom can not be parsed.
+func ParseUploadedSbom(c *gin.Context) (types.Sbom, []byte, bool) {
 	file, err := c.FormFile("sbom")
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to upload file"})
-		return
+		return types.Sbom{}, nil, false
 	}
 
 	fileContent, err := file.Open()
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid file content"})
-		return
+		return types.Sbom{}, nil, false
 	}
 	defer fileContent.Close()
 
-	decoder := cyclonedx.NewBOMDecoder(fileContent, cyclonedx.BOMFileFormatJSON)
-	var bom cyclonedx.BOM
-	if err := decoder.Decode(&bom); err != nil {
+	content, err := io.ReadAll(fileContent)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid file content"})
+		return types.Sbom{}, nil, false
+	}
+
+	sbom, ok := ParseSbomContent(c, content, file.Header.Get("Content-Type"), file.Filename)
+	return sbom, content, ok
+}
+
+// ParseSbomContent validates sbom content and converts spdx sboms to cyclonedx. Content is kept as
+// original document of the sbom. Error response is written to the request context when sbom can
+// not be parsed.
+func ParseSbomContent(c *gin.Context, content []byte, contentType, fileName string) (types.Sbom, bool) {
+	format, err := sbomformat.Detect(content, contentType, fileName)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid SBOM format"})
+		return types.Sbom{}, false
+	}
+
+	if !format.IsSpdx() {
+		validationErrs, err := sbomvalidate.ValidateSchema(content, format)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to validate sbom schema")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to validate SBOM"})
+			return types.Sbom{}, false
+		} else if len(validationErrs) > 0 {
+			resp := gin.H{"error": "invalid SBOM", "validation_errors": validationErrs}
+			if limitation := sbomvalidate.GetSchemaLimitation(format); limitation != "" {
+				resp["schema_validation"] = limitation
+			}
+			c.JSON(http.StatusBadRequest, resp)
+			return types.Sbom{}, false
+		}
+
+		bom, err := sbomformat.Decode(content, format)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid SBOM format"})
+			return types.Sbom{}, false
+		}
+
+		if validationErrs := sbomvalidate.ValidateSemantics(bom); len(validationErrs) > 0 {
+			respondValidationErrors(c, validationErrs)
+			return types.Sbom{}, false
+		}
+
+		sbom := types.NewSbom(bom, string(format))
+		sbom.OriginalDocument = string(content)
+
+		return sbom, true
+	}
+
+	doc, err := sbomformat.ReadSpdx(content, format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid SBOM format"})
+		return types.Sbom{}, false
+	}
+
+	bom, loss, err := sbomconvert.ConvertSpdxDocument(doc)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Failed to convert SBOM"})
+		return types.Sbom{}, false
+	}
+
+	// pointers refer to converted cyclonedx sbom
+	if validationErrs := sbomvalidate.ValidateSemantics(bom); len(validationErrs) > 0 {
+		respondValidationErrors(c, validationErrs)
+		return types.Sbom{}, false
+	}
+
+	sbom := types.NewSbom(bom, string(format))
+	sbom.OriginalDocument = string(content)
+	sbom.ConversionLoss = loss
+
+	return sbom, true
+}
+
+// generates cyclonedx sbom from lockfiles and manifests of a project. Name and version of the
+// project default to the ones described by lockfiles.
+// curl -X POST -F "lockfile=@package-lock.json" http://localhost:8080/api/v1/sbom/generate
+// curl -X POST -F "lockfile=@go.mod" -F "lockfile=@go.sum" -F "version=1.2.0" http://localhost:8080/api/v1/sbom/generate
+// curl -X POST -F "lockfile=@poetry.lock" -F "name=my-service" http://localhost:8080/api/v1/sbom/generate
+func (s *ComponentSbomHandler) GenerateSbom(c *gin.Context) {
+	sbom, ok := ParseUploadedLockfiles(c, "")
+	if !ok {
 		return
 	}
 
-	// Store component SBOM
-	componentId, err := s.store.AddComponentSbom(bom)
+	s.storeSbom(c, sbom, true)
+}
+
+// ParseUploadedLockfiles reads lockfiles from multipart "lockfile" fields and generates cyclonedx
+// sbom of them. Name of the generated root component defaults to the form "name" field, then to
+// the project described by lockfiles and then to defaultName. Error response is written to the
+// request context when sbom can not be generated.
+func ParseUploadedLockfiles(c *gin.Context, defaultName string) (types.Sbom, bool) {
+	form, err := c.MultipartForm()
+	if err != nil || len(form.File["lockfile"]) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "lockfile is required"})
+		return types.Sbom{}, false
+	} else if len(form.File["lockfile"]) > maxLockfiles {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("at most %d lockfiles can be uploaded", maxLockfiles)})
+		return types.Sbom{}, false
+	}
+
+	name, version := c.PostForm("name"), c.PostForm("version")
+	lockfiles := []*lockfile.Lockfile{}
+	for _, file := range form.File["lockfile"] {
+		lockfileType, err := lockfile.Detect(file.Filename)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return types.Sbom{}, false
+		}
+
+		fileContent, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid file content"})
+			return types.Sbom{}, false
+		}
+		content, err := io.ReadAll(fileContent)
+		fileContent.Close()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid file content"})
+			return types.Sbom{}, false
+		}
+
+		parsed, err := lockfile.Parse(lockfileType, content)
+		if err != nil {
+			log.Error().Err(err).Msgf("failed to parse lockfile %s", file.Filename)
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return types.Sbom{}, false
+		}
+		lockfiles = append(lockfiles, parsed)
+
+		if name == "" && parsed.RootName != "" {
+			name = parsed.RootName
+			if version == "" {
+				version = parsed.RootVersion
+			}
+		}
+	}
+
+	if name == "" {
+		name = defaultName
+	}
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required as lockfiles do not describe the project"})
+		return types.Sbom{}, false
+	}
+
+	bom := lockfile.ToBom(lockfiles, name, version)
+	if validationErrs := sbomvalidate.ValidateSemantics(bom); len(validationErrs) > 0 {
+		respondValidationErrors(c, validationErrs)
+		return types.Sbom{}, false
+	}
+
+	return types.NewSbom(bom, string(sbomformat.Lockfile)), true
+}
+
+// generates cyclonedx sbom of os packages installed in container image from `docker save` or oci
+// layout tarball, which may be gzip compressed. Name of the image defaults to its first repo tag.
+// curl -X POST -F "image=@nginx.tar" http://localhost:8080/api/v1/sbom/image
+// curl -X POST -F "image=@nginx.tar.gz" -F "name=nginx:1.25" http://localhost:8080/api/v1/sbom/image
+func (s *ComponentSbomHandler) GenerateImageSbom(c *gin.Context) {
+	sbom, ok := ParseUploadedImage(c, "")
+	if !ok {
+		return
+	}
+
+	s.storeSbom(c, sbom, true)
+}
+
+// ParseUploadedImage reads container image tarball from multipart "image" field and generates
+// cyclonedx sbom of os packages installed in image. Image name defaults to the form "name" field,
+// then to first repo tag of image and then to defaultName. Error response is written to the
+// request context when sbom can not be generated.
+func ParseUploadedImage(c *gin.Context, defaultName string) (types.Sbom, bool) {
+	file, err := c.FormFile("image")
 	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to upload file"})
+		return types.Sbom{}, false
+	}
+
+	fileContent, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid file content"})
+		return types.Sbom{}, false
+	}
+	defer fileContent.Close()
+
+	inventory, err := containerimage.Scan(fileContent, file.Size, int64(config.DefaultConfig.MaxImageSizeMb)<<20)
+	switch {
+	case errors.Is(err, containerimage.ErrImageTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		return types.Sbom{}, false
+	case errors.Is(err, containerimage.ErrInvalidImage), errors.Is(err, containerimage.ErrUnsupportedLayer):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return types.Sbom{}, false
+	case errors.Is(err, containerimage.ErrNoPackages):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return types.Sbom{}, false
+	case err != nil:
+		log.Error().Err(err).Msg("failed to scan container image")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to scan container image"})
+		return types.Sbom{}, false
+	}
+
+	name := c.PostForm("name")
+	if name == "" {
+		name = inventory.Name
+	}
+	if name == "" {
+		name = defaultName
+	}
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required as image has no repo tag"})
+		return types.Sbom{}, false
+	}
+
+	bom := containerimage.ToBom(inventory, name)
+	if validationErrs := sbomvalidate.ValidateSemantics(bom); len(validationErrs) > 0 {
+		respondValidationErrors(c, validationErrs)
+		return types.Sbom{}, false
+	}
+
+	return types.NewSbom(bom, string(sbomformat.ContainerImage)), true
+}
+
+func respondValidationErrors(c *gin.Context, validationErrs []types.SbomValidationError) {
+	c.JSON(http.StatusBadRequest, gin.H{"error": "invalid SBOM", "validation_errors": validationErrs})
+}
+
+// stores sbom and writes upload response, analysis of stored sbom is queued when analyze is set
+func (s *ComponentSbomHandler) storeSbom(c *gin.Context, sbom types.Sbom, analyze bool) {
+	// Store component SBOM
+	componentId, err := s.store.AddComponentSbom(sbom)
+	duplicate := errors.Is(err, types.ErrDuplicateSbom)
+	if errors.Is(err, types.ErrSbomRevisionConflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	} else if err != nil && !duplicate {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "failed to upload component SBOM",
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "SBOM uploaded successfully", "id": componentId})
+	resp := gin.H{"message": "SBOM uploaded successfully", "id": componentId, "format": sbom.OriginalFormat}
+	if duplicate {
+		resp = gin.H{"message": "SBOM already exists", "id": componentId, "duplicate": true}
+	} else if sbom.ConversionLoss != nil {
+		resp["conversion_loss"] = sbom.ConversionLoss
+	}
+	if limitation := sbomvalidate.GetSchemaLimitation(sbomformat.Format(sbom.OriginalFormat)); limitation != "" {
+		resp["schema_validation"] = limitation
+	}
+
+	if analyze {
+		jobId, err := job.QueueAnalysis(s.jobStore, s.store, componentId, duplicate)
+		if err != nil {
+			log.Error().Err(err).Msgf("failed to queue analysis of sbom %s", componentId)
+			resp["analysis_error"] = "failed to queue sbom analysis"
+		} else if jobId != "" {
+			resp["job_id"] = jobId
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
 }
 
+// returns metadata of sboms, components and other bom elements are only returned by sbom id
 // curl http://localhost:8080/api/v1/sbom
 func (s *ComponentSbomHandler) GetSboms(c *gin.Context) {
 	// Get page and limit from query parameters
@@ -119,6 +416,160 @@ func (s *ComponentSbomHandler) GetSboms(c *gin.Context) {
 	})
 }
 
+// returns stored components of sbom streamed from db
+func (s *ComponentSbomHandler) getSbomComponents(sbomId string) types.ComponentsFunc {
+	return func(visit func(types.Component) error) error {
+		return s.componentStore.ForEachComponentBySbomId(sbomId, config.DefaultConfig.DbQueryTimeout, visit)
+	}
+}
+
+// returns components, licenses and vulns changed between base and head sboms as json along with
+// markdown summary. Use output=markdown to get only markdown summary.
+// curl "http://localhost:8080/api/v1/sbom/diff?base={base_sbom_id}&head={head_sbom_id}"
+func (s *ComponentSbomHandler) DiffSboms(c *gin.Context) {
+	baseId, baseExists := c.GetQuery("base")
+	headId, headExists := c.GetQuery("head")
+	if !baseExists || !headExists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "base and head sbom ids are required"})
+		return
+	}
+
+	sboms := []types.Sbom{}
+	for _, sbomId := range []string{baseId, headId} {
+		sbom, err := s.store.GetSbomById(sbomId, config.DefaultConfig.DbQueryTimeout)
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("sbom %s not found", sbomId)})
+			return
+		} else if err != nil {
+			log.Error().Err(err).Msgf("failed to fetch sbom %s", sbomId)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch item"})
+			return
+		}
+
+		sboms = append(sboms, sbom)
+	}
+
+	diff, err := sbomdiff.Diff(sboms[0], sboms[1], s.getSbomComponents(baseId), s.getSbomComponents(headId))
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to fetch components of sboms %s and %s", baseId, headId)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sbom components"})
+		return
+	}
+	markdown := sbomdiff.Markdown(diff)
+
+	if c.Query("output") == "markdown" {
+		c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(markdown))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"diff": diff, "markdown": markdown})
+}
+
+// merges stored sboms into new aggregate sbom with synthetic root component. Aggregate sbom
+// stores ids of merged sboms so that it can be analyzed and reported as a single product.
+// curl -X POST -H "Content-Type: application/json" -d '{"sbom_ids": ["{sbom_id}", "{sbom_id}"], "name": "product", "version": "1.0.0"}' http://localhost:8080/api/v1/sbom/merge
+func (s *ComponentSbomHandler) MergeSboms(c *gin.Context) {
+	var payload types.MergeSbomsRequestSchema
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		log.Error().Err(err).Msg("failed to validate request")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to validate payload"})
+		return
+	}
+
+	sboms := []types.Sbom{}
+	for _, sbomId := range payload.SbomIds {
+		if !utils.IsValidMongoObjectID(sbomId) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid sbom id %s", sbomId)})
+			return
+		}
+
+		sbom, err := s.store.GetSbomById(sbomId, config.DefaultConfig.DbQueryTimeout)
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("sbom %s not found", sbomId)})
+			return
+		} else if err != nil {
+			log.Error().Err(err).Msgf("failed to fetch sbom %s", sbomId)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch item"})
+			return
+		}
+
+		sboms = append(sboms, sbom)
+	}
+
+	root := cyclonedx.Component{
+		Type:       cyclonedx.ComponentTypeApplication,
+		Name:       payload.Name,
+		Version:    payload.Version,
+		Group:      payload.Group,
+		PackageURL: payload.Purl,
+	}
+
+	sbom := types.NewSbom(sbommerge.Merge(sboms, root), string(sbomformat.CycloneDxJson))
+	sbom.SourceSbomIds = payload.SbomIds
+
+	sbomId, err := s.store.AddComponentSbom(sbom)
+	duplicate := errors.Is(err, types.ErrDuplicateSbom)
+	if err != nil && !duplicate {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store merged SBOM"})
+		return
+	}
+
+	components := 0
+	if sbom.Components != nil {
+		components = len(*sbom.Components)
+	}
+
+	resp := gin.H{
+		"message":         "SBOMs merged successfully",
+		"id":              sbomId,
+		"source_sbom_ids": sbom.SourceSbomIds,
+		"components":      components,
+	}
+	if duplicate {
+		resp = gin.H{"message": "SBOM already exists", "id": sbomId, "duplicate": true}
+	}
+
+	jobId, err := job.QueueAnalysis(s.jobStore, s.store, sbomId, duplicate)
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to queue analysis of merged sbom %s", sbomId)
+		resp["analysis_error"] = "failed to queue sbom analysis"
+	} else if jobId != "" {
+		resp["job_id"] = jobId
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// returns quality score breakdown of sbom. Sboms stored before quality scoring was introduced
+// are scored and updated on first request, use rescore=true to score sbom again.
+// curl http://localhost:8080/api/v1/sbom/{sbom_id}/quality
+func (s *ComponentSbomHandler) GetSbomQuality(c *gin.Context) {
+	idParam := c.Param("id")
+
+	sbom, err := s.store.GetSbomById(idParam, config.DefaultConfig.DbQueryTimeout)
+	if err == mongo.ErrNoDocuments {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
+		return
+	} else if err != nil {
+		log.Error().Err(err).Msgf("failed to fetch sbom %s", idParam)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch item"})
+		return
+	}
+
+	if sbom.Quality != nil && c.Query("rescore") != "true" {
+		c.JSON(http.StatusOK, sbom.Quality)
+		return
+	}
+
+	quality := sbomquality.Score(sbom)
+	if err := s.store.UpdateQuality(idParam, quality, config.DefaultConfig.DbQueryTimeout); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store sbom quality"})
+		return
+	}
+
+	c.JSON(http.StatusOK, quality)
+}
+
 // curl http://localhost:8080/api/v1/sbom/{sbom_id}
 func (s *ComponentSbomHandler) GetSbomById(c *gin.Context) {
 	// Get the ID from the path parameter
@@ -139,6 +590,157 @@ func (s *ComponentSbomHandler) GetSbomById(c *gin.Context) {
 	c.JSON(http.StatusOK, sbom)
 }
 
+// deletes sbom along with its components and findings and removes it from projects. Use
+// dry_run=true to report what would be deleted.
+// curl -X DELETE "http://localhost:8080/api/v1/sbom/{sbom_id}?dry_run=true"
+func (s *ComponentSbomHandler) DeleteSbomById(c *gin.Context) {
+	idParam := c.Param("id")
+	if !utils.IsValidMongoObjectID(idParam) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sbom id"})
+		return
+	}
+
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid dry_run value"})
+		return
+	}
+
+	deletion, err := s.deleteSboms([]string{idParam}, dryRun)
+	if errors.Is(err, types.ErrSbomAnalysisRunning) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "running_job_ids": deletion.RunningJobIds})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete sbom"})
+		return
+	} else if len(deletion.NotFound) > 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, deletion)
+}
+
+// deletes sboms along with their components and findings and removes them from projects, ids
+// of missing sboms are reported as not found
+// curl -X POST -H "Content-Type: application/json" -d '{"sbom_ids": ["{sbom_id}", "{sbom_id}"], "dry_run": true}' http://localhost:8080/api/v1/sbom/delete
+func (s *ComponentSbomHandler) DeleteSboms(c *gin.Context) {
+	var payload types.DeleteSbomsRequestSchema
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		log.Error().Err(err).Msg("failed to validate request")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to validate payload"})
+		return
+	}
+
+	for _, sbomId := range payload.SbomIds {
+		if !utils.IsValidMongoObjectID(sbomId) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid sbom id %s", sbomId)})
+			return
+		}
+	}
+
+	deletion, err := s.deleteSboms(payload.SbomIds, payload.DryRun)
+	if errors.Is(err, types.ErrSbomAnalysisRunning) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "running_job_ids": deletion.RunningJobIds})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete sboms"})
+		return
+	}
+
+	c.JSON(http.StatusOK, deletion)
+}
+
+// downloads document as it was uploaded, before it was converted to cyclonedx
+// curl -OJ http://localhost:8080/api/v1/sbom/{sbom_id}/original
+func (s *ComponentSbomHandler) GetOriginalSbomById(c *gin.Context) {
+	idParam := c.Param("id")
+
+	sbom, err := s.store.GetSbomById(idParam, config.DefaultConfig.DbQueryTimeout)
+	if err == mongo.ErrNoDocuments {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch item"})
+		return
+	}
+
+	original, err := s.store.GetOriginalDocument(sbom)
+	if errors.Is(err, types.ErrBlobNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "original document is only stored for uploaded sboms"})
+		return
+	} else if err != nil {
+		log.Error().Err(err).Msgf("failed to fetch original document of sbom %s", idParam)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch original document"})
+		return
+	}
+	defer original.Close()
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, sbom.Id, sbomformat.Format(sbom.OriginalFormat).Extension()))
+	c.DataFromReader(http.StatusOK, -1, sbomformat.Format(sbom.OriginalFormat).ContentType(), original, nil)
+}
+
+// exports sbom with vulnerabilities of analyzed components as vulnerability disclosure report.
+// Supported formats are cyclonedx-json (default), cyclonedx-xml and spdx-json.
+// curl "http://localhost:8080/api/v1/sbom/{sbom_id}/export?format=cyclonedx-xml"
+func (s *ComponentSbomHandler) ExportSbom(c *gin.Context) {
+	idParam := c.Param("id")
+	format := sbomformat.Format(c.DefaultQuery("format", string(sbomformat.CycloneDxJson)))
+	if !slices.Contains(sbomexport.ExportFormats, format) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid export format", "supported_formats": sbomexport.ExportFormats})
+		return
+	}
+
+	sbom, err := s.store.GetSbomById(idParam, config.DefaultConfig.DbQueryTimeout)
+	if err == mongo.ErrNoDocuments {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch item"})
+		return
+	}
+
+	content, err := sbomexport.Export(sbom, s.getSbomComponents(sbom.Id), format)
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to export sbom %s as %s", sbom.Id, format)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export SBOM"})
+		return
+	}
+
+	c.Data(http.StatusOK, format.ContentType(), content)
+}
+
+// returns metadata of all revisions of the bom sorted by version
+// curl http://localhost:8080/api/v1/sbom/{sbom_id}/revisions
+func (s *ComponentSbomHandler) GetSbomRevisions(c *gin.Context) {
+	idParam := c.Param("id")
+
+	sbom, err := s.store.GetSbomById(idParam, config.DefaultConfig.DbQueryTimeout)
+	if err == mongo.ErrNoDocuments {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch item"})
+		return
+	}
+
+	// sboms without serial number can not be linked to other revisions
+	if sbom.SerialNumber == "" {
+		c.JSON(http.StatusOK, gin.H{"data": []types.Sbom{sbom.WithoutContent()}})
+		return
+	}
+
+	revisions, err := s.store.GetSbomRevisions(sbom.SerialNumber, config.DefaultConfig.DbQueryTimeout)
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to fetch revisions of sbom %s", idParam)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch item"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": revisions})
+}
+
+// returns metadata of sboms with root component name
 // curl "http://localhost:8080/api/v1/sbom/getByComponentName?name=enigma"
 func (s *ComponentSbomHandler) GetSbomByName(c *gin.Context) {
 	// Get the ID from the path parameter
@@ -214,111 +816,3 @@ func (s *ComponentSbomHandler) ConvertSbom(c *gin.Context) {
 	// Return the converted SBOM as JSON
 	c.JSON(http.StatusOK, gin.H{"converted_sbom": sbom})
 }
-
-// curl -X POST -H "application/json" -d '{"owner":"dmdhrumilmistry", "repo_name":"pyhtools"}' http://localhost:8080/api/v1/sbom/githubImport
-func (s *ComponentSbomHandler) ImportGithubRepo(c *gin.Context) {
-	var jsonData types.GithubRepoImportRequestSchema
-
-	// Bind the incoming JSON data to the struct
-	if err := c.ShouldBindJSON(&jsonData); err != nil {
-		// If there is an error parsing the JSON, send an error response
-		log.Error().Err(err).Msg("invalid json data")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
-		return
-	}
-
-	// Validate the URL
-	sbomUrl := fmt.Sprintf("https://api.github.com/repos/%s/%s/dependency-graph/sbom", jsonData.Owner, jsonData.RepoName)
-	regex := `^https:\/\/api\.github\.com\/repos\/[a-zA-Z0-9_-]+\/[a-zA-Z0-9_-]+\/dependency-graph\/sbom$`
-	re := regexp.MustCompile(regex)
-
-	if !re.MatchString(sbomUrl) {
-		log.Error().Msgf("owner (%s) and repo name (%s) are invalid", jsonData.Owner, jsonData.RepoName)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
-		return
-	}
-
-	log.Info().Msgf("Fetching SBOM for https://github.com/%s/%s repo", jsonData.Owner, jsonData.RepoName)
-
-	req, err := http.NewRequest(http.MethodGet, sbomUrl, nil)
-	if err != nil {
-		log.Error().Err(err).Msgf("failed to generate req for github.com/%s/%s", jsonData.Owner, jsonData.RepoName)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate request to fetch sbom github api"})
-		return
-	}
-	req.Header.Add("Accept", "application/vnd.github+json")
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", config.DefaultConfig.GithubToken))
-	req.Header.Add("X-GitHub-Api-Version", "2022-11-28")
-
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-		log.Error().Err(err).Msgf("failed to fetch sbom from github.com/%s/%s", jsonData.Owner, jsonData.RepoName)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch sbom github api"})
-		return
-	}
-	defer res.Body.Close()
-
-	if res.StatusCode != 200 {
-		log.Error().Err(err).Msgf("failed to fetch sbom from github.com/%s/%s. Expected 200 received %d from api", jsonData.Owner, jsonData.RepoName, res.StatusCode)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch sbom github api"})
-		return
-	}
-
-	// Read the response body into a buffer
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		log.Error().Err(err).Msg("Failed to read github api response body into buffer")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process the response body"})
-		return
-	}
-
-	var respPayload types.GithubRepoImportResponseSchema
-	if err := json.Unmarshal(body, &respPayload); err != nil {
-		log.Error().Err(err).Msg("failed to parse github api resp to json")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process the response body"})
-		return
-	}
-
-	sbomByte, err := json.Marshal(respPayload.Sbom)
-	if err != nil {
-		log.Error().Err(err).Msg("failed to convert github api sbom resp to json")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process the github repo sbom"})
-
-		return
-	}
-
-	// Convert the buffer into an io.ReadSeekCloser (using a bytes.Reader)
-	readSeekCloser := &types.ReadSeekCloser{Reader: bytes.NewReader(sbomByte)}
-
-	// Create a buffer to store the converted SBOM output
-	outputBuffer := &bytes.Buffer{}
-	outputStream := &types.WriteCloser{
-		Buffer: outputBuffer,
-	}
-
-	if err := sbomconvert.ConvertSbom(readSeekCloser, outputStream); err != nil {
-		log.Error().Err(err).Msg("failed to convert sbom")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to convert sbom"})
-		return
-	}
-
-	decoder := cyclonedx.NewBOMDecoder(outputStream, cyclonedx.BOMFileFormatJSON)
-	var bom cyclonedx.BOM
-	if err := decoder.Decode(&bom); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid SBOM format"})
-		return
-	}
-
-	// Store component SBOM
-	componentId, err := s.store.AddComponentSbom(bom)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "failed to upload component SBOM",
-		})
-		return
-	}
-
-	// TODO: add task to process sbom component
-
-	c.JSON(http.StatusOK, gin.H{"message": "SBOM uploaded successfully", "id": componentId})
-}
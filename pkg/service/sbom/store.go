@@ -2,11 +2,22 @@ package sbom
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
+	"io"
+	"strings"
 	"time"
 
 	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/config"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/db"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/sbomquality"
 	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/utils"
 	"github.com/rs/zerolog/log"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -16,29 +27,289 @@ import (
 
 const COMPONENT_SBOM_COLLECTION = "component_sbom"
 
+// sbom lists only include metadata of sboms. Content of sboms stored before blob storage was
+// introduced is kept in db and excluded as well, so that all sboms are listed the same way.
+var metadataProjection = bson.M{
+	"original_document":  0,
+	"components":         0,
+	"services":           0,
+	"externalreferences": 0,
+	"dependencies":       0,
+	"compositions":       0,
+	"properties":         0,
+	"vulnerabilities":    0,
+	"annotations":        0,
+	"formulation":        0,
+	"declarations":       0,
+	"definitions":        0,
+}
+
+var blobProjection = bson.M{"document_blob": 1, "original_blob": 1}
+
 type ComponentSbomStore struct {
 	db         *mongo.Database
 	collection *mongo.Collection
+	blobStore  types.BlobStore
 }
 
-func NewComponentSbomStore(db *mongo.Database) *ComponentSbomStore {
-	collection := db.Collection(COMPONENT_SBOM_COLLECTION)
-	// TODO: create index if not exists
+// sbom content is kept in blob storage as sboms may exceed max mongo document size, only
+// metadata used for searching sboms is stored in db
+func NewComponentSbomStore(mdb *mongo.Database, blobStore types.BlobStore) *ComponentSbomStore {
+	collection := mdb.Collection(COMPONENT_SBOM_COLLECTION)
+
+	// sboms stored before content hashing was introduced do not have a hash
+	db.EnsureIndex(collection, mongo.IndexModel{
+		Keys: bson.D{{Key: "content_hash", Value: 1}},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+			"content_hash": bson.M{"$type": "string"},
+		}),
+	})
+
+	// same revision of a bom must have same content
+	db.EnsureIndex(collection, mongo.IndexModel{
+		Keys: bson.D{{Key: "serialnumber", Value: 1}, {Key: "version", Value: 1}},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+			"serialnumber": bson.M{"$type": "string", "$gt": ""},
+		}),
+	})
+
+	// used by sbom search
+	for _, keys := range []bson.D{
+		{{Key: "metadata.component.name", Value: 1}},
+		{{Key: "metadata.component.packageurl", Value: 1}},
+		{{Key: "metadata.component.hashes.value", Value: 1}},
+		{{Key: "metadata.component.supplier.name", Value: 1}},
+		{{Key: "metadata.supplier.name", Value: 1}},
+		{{Key: "metadata.properties.name", Value: 1}, {Key: "metadata.properties.value", Value: 1}},
+	} {
+		db.EnsureIndex(collection, mongo.IndexModel{Keys: keys})
+	}
+
+	// used to link provenance attestations to sboms of same artifact
+	db.EnsureIndex(collection, mongo.IndexModel{Keys: bson.D{{Key: "artifact_digests", Value: 1}}})
 
 	return &ComponentSbomStore{
-		db:         db,
+		db:         mdb,
 		collection: collection,
+		blobStore:  blobStore,
+	}
+}
+
+func newBlobContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), time.Duration(config.DefaultConfig.BlobTimeout)*time.Second)
+}
+
+// stores cyclonedx json and uploaded document of sbom in blob storage, keys are derived from
+// content hash which is unique for each stored sbom. Returns sbom without content to be stored
+// in db.
+func (c *ComponentSbomStore) putBlobs(sbom types.Sbom) (types.Sbom, error) {
+	content, err := json.Marshal(sbom.ToBom())
+	if err != nil {
+		return sbom, err
+	}
+
+	ctx, cancel := newBlobContext()
+	defer cancel()
+
+	sbom.DocumentBlob = fmt.Sprintf("sbom/%s.json", sbom.ContentHash)
+	if err := c.blobStore.Put(ctx, sbom.DocumentBlob, content); err != nil {
+		return sbom, err
+	}
+
+	if sbom.OriginalDocument != "" {
+		sbom.OriginalBlob = fmt.Sprintf("sbom/%s.original", sbom.ContentHash)
+		if err := c.blobStore.Put(ctx, sbom.OriginalBlob, []byte(sbom.OriginalDocument)); err != nil {
+			c.deleteBlobs(sbom)
+			return sbom, err
+		}
+	}
+
+	return sbom.WithoutContent(), nil
+}
+
+// deletes blobs of sbom, missing blobs are ignored
+func (c *ComponentSbomStore) deleteBlobs(sbom types.Sbom) {
+	ctx, cancel := newBlobContext()
+	defer cancel()
+
+	for _, key := range []string{sbom.DocumentBlob, sbom.OriginalBlob} {
+		if key == "" {
+			continue
+		}
+		if err := c.blobStore.Delete(ctx, key); err != nil && !errors.Is(err, types.ErrBlobNotFound) {
+			log.Error().Err(err).Msgf("failed to delete blob %s of sbom %s", key, sbom.Id)
+		}
+	}
+}
+
+// reads sbom content from blob storage, sboms stored before blob storage was introduced keep
+// their content in db
+func (c *ComponentSbomStore) loadContent(sbom *types.Sbom) error {
+	if sbom.DocumentBlob == "" {
+		return nil
+	}
+
+	ctx, cancel := newBlobContext()
+	defer cancel()
+
+	reader, err := c.blobStore.Get(ctx, sbom.DocumentBlob)
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to fetch content of sbom %s", sbom.Id)
+		return err
 	}
+	defer reader.Close()
+
+	var bom cyclonedx.BOM
+	if err := json.NewDecoder(reader).Decode(&bom); err != nil {
+		log.Error().Err(err).Msgf("failed to decode content of sbom %s", sbom.Id)
+		return err
+	}
+	sbom.SetContent(&bom)
+
+	return nil
+}
+
+// returns uploaded document of sbom, ErrBlobNotFound is returned for sboms without uploaded
+// document such as generated or merged sboms
+func (c *ComponentSbomStore) GetOriginalDocument(sbom types.Sbom) (io.ReadCloser, error) {
+	if sbom.OriginalDocument != "" {
+		return io.NopCloser(strings.NewReader(sbom.OriginalDocument)), nil
+	} else if sbom.OriginalBlob == "" {
+		return nil, types.ErrBlobNotFound
+	}
+
+	// reader outlives this call, so blob timeout isn't applied to it
+	return c.blobStore.Get(context.Background(), sbom.OriginalBlob)
 }
 
-func (c *ComponentSbomStore) AddComponentSbom(sbom cyclonedx.BOM) (string, error) {
-	result, err := c.collection.InsertOne(context.TODO(), sbom)
+// returns sha256 hash of canonical cyclonedx json of the sbom. Fields which only depend on
+// the format sbom was uploaded in are ignored so same bom always has the same hash.
+func getContentHash(sbom types.Sbom) (string, error) {
+	bom := sbom.ToBom()
+	bom.XMLName = xml.Name{}
+	bom.XMLNS = ""
+	bom.JSONSchema = ""
+	bom.BOMFormat = cyclonedx.BOMFormat
+
+	content, err := json.Marshal(bom)
 	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// returns id of sbom matching the filter
+func (c *ComponentSbomStore) getSbomId(filter interface{}, findOptions ...*options.FindOneOptions) (string, error) {
+	var sbom types.Sbom
+	findOptions = append(findOptions, options.FindOne().SetProjection(bson.M{"_id": 1}))
+	if err := c.collection.FindOne(context.TODO(), filter, findOptions...).Decode(&sbom); err != nil {
+		return "", err
+	}
+
+	return sbom.Id, nil
+}
+
+// Stores sbom and links it to previous revision of the same bom. Returns id of existing sbom
+// along with ErrDuplicateSbom when sbom with same content is already stored.
+func (c *ComponentSbomStore) AddComponentSbom(sbom types.Sbom) (string, error) {
+	// ignore provided id and blobs
+	sbom.Id = ""
+	sbom.DocumentBlob, sbom.OriginalBlob = "", ""
+
+	contentHash, err := getContentHash(sbom)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to compute sbom content hash")
+		return "", err
+	}
+	sbom.ContentHash = contentHash
+
+	if sbom.Quality == nil {
+		quality := sbomquality.Score(sbom)
+		sbom.Quality = &quality
+	}
+
+	existingId, err := c.getSbomId(bson.M{"content_hash": contentHash})
+	if err == nil {
+		log.Info().Msgf("sbom with content hash %s already exists with id %s", contentHash, existingId)
+		return existingId, types.ErrDuplicateSbom
+	} else if err != mongo.ErrNoDocuments {
+		log.Error().Err(err).Msg("failed to check for duplicate sbom")
+		return "", err
+	}
+
+	if sbom.SerialNumber != "" {
+		// revisions may be uploaded out of order, link to highest lower version
+		previousId, err := c.getSbomId(
+			bson.M{"serialnumber": sbom.SerialNumber, "version": bson.M{"$lt": sbom.Version}},
+			options.FindOne().SetSort(bson.M{"version": -1}),
+		)
+		if err == nil {
+			sbom.PreviousRevisionId = previousId
+		} else if err != mongo.ErrNoDocuments {
+			log.Error().Err(err).Msgf("failed to fetch previous revision of sbom %s", sbom.SerialNumber)
+			return "", err
+		}
+	}
+
+	stored, err := c.putBlobs(sbom)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to store sbom content in blob storage")
+		return "", err
+	}
+
+	result, err := c.collection.InsertOne(context.TODO(), stored)
+	if mongo.IsDuplicateKeyError(err) {
+		// same sbom might have been stored by a concurrent upload, which uses the same blobs
+		if existingId, findErr := c.getSbomId(bson.M{"content_hash": contentHash}); findErr == nil {
+			return existingId, types.ErrDuplicateSbom
+		}
+
+		c.deleteBlobs(stored)
+		return "", types.ErrSbomRevisionConflict
+	} else if err != nil {
 		log.Error().Err(err).Msg("failed to insert sbom")
+		c.deleteBlobs(stored)
 		return "", err
 	}
+	id := (result.InsertedID).(primitive.ObjectID).Hex()
+
+	if sbom.SerialNumber != "" {
+		// next revision uploaded before this one is now preceded by this sbom
+		err := c.collection.FindOneAndUpdate(
+			context.TODO(),
+			bson.M{"serialnumber": sbom.SerialNumber, "version": bson.M{"$gt": sbom.Version}},
+			bson.M{"$set": bson.M{"previous_revision_id": id}},
+			options.FindOneAndUpdate().SetSort(bson.M{"version": 1}).SetProjection(bson.M{"_id": 1}),
+		).Err()
+		if err != nil && err != mongo.ErrNoDocuments {
+			log.Error().Err(err).Msgf("failed to link next revision of sbom %s", id)
+		}
+	}
 
-	return (result.InsertedID).(primitive.ObjectID).Hex(), nil
+	return id, nil
+}
+
+// returns all revisions of a bom sorted by version
+func (c *ComponentSbomStore) GetSbomRevisions(serialNumber string, duration int) ([]types.Sbom, error) {
+	var sboms []types.Sbom
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(duration)*time.Second)
+	defer cancel()
+
+	findOptions := options.Find().SetSort(bson.M{"version": 1}).SetProjection(metadataProjection)
+	cursor, err := c.collection.Find(ctx, bson.M{"serialnumber": serialNumber}, findOptions)
+	if err != nil {
+		return sboms, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &sboms); err != nil {
+		return sboms, err
+	}
+
+	return sboms, nil
 }
 
 func (c *ComponentSbomStore) ValidateIds(ids []string) error {
@@ -92,6 +363,7 @@ func (c *ComponentSbomStore) GetPaginatedSboms(page, limit, duration int) ([]typ
 	findOptions := options.Find()
 	findOptions.SetSkip(int64(skip))
 	findOptions.SetLimit(int64(limit))
+	findOptions.SetProjection(metadataProjection)
 
 	// Query MongoDB
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(duration)*time.Second)
@@ -130,7 +402,7 @@ func (c *ComponentSbomStore) GetSbomById(idParam string, duration int) (types.Sb
 		return sbom, err
 	}
 
-	return sbom, nil
+	return sbom, c.loadContent(&sbom)
 }
 
 func (c *ComponentSbomStore) GetSbomByName(name string, duration int) ([]types.Sbom, error) {
@@ -140,7 +412,7 @@ func (c *ComponentSbomStore) GetSbomByName(name string, duration int) ([]types.S
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(duration)*time.Second)
 	defer cancel()
 
-	cursor, err := c.collection.Find(ctx, bson.M{"metadata.component.name": name})
+	cursor, err := c.collection.Find(ctx, bson.M{"metadata.component.name": name}, options.Find().SetProjection(metadataProjection))
 	if err != nil {
 		return sboms, err
 	}
@@ -154,26 +426,239 @@ func (c *ComponentSbomStore) GetSbomByName(name string, duration int) ([]types.S
 	return sboms, nil
 }
 
-func (c *ComponentSbomStore) DeleteByIds(idParams []string, duration int) (int64, error) {
-	// Convert string IDs to ObjectIDs
-	var objectIDs []primitive.ObjectID
-	for _, id := range idParams {
-		objID, err := primitive.ObjectIDFromHex(id)
-		if err != nil {
-			log.Error().Err(err).Msgf("Invalid ObjectID %s: %v", id, err)
-			continue
-		}
-		objectIDs = append(objectIDs, objID)
+// returns filter matching sboms using metadata component and metadata properties
+func (c *ComponentSbomStore) GetSearchFilter(query types.SearchQuery) interface{} {
+	conditions := []bson.M{}
+	if name := utils.BuildNameFilter(query); name != nil {
+		conditions = append(conditions, bson.M{"metadata.component.name": name})
+	}
+	if query.Purl != "" {
+		conditions = append(conditions, bson.M{"metadata.component.packageurl": utils.BuildPurlFilter(query.Purl)})
+	}
+	if query.Hash != "" {
+		conditions = append(conditions, bson.M{"metadata.component.hashes": utils.BuildHashFilter(query.Hash, query.HashAlgorithm)})
+	}
+	if query.Supplier != "" {
+		conditions = append(conditions, bson.M{"$or": []bson.M{
+			{"metadata.supplier.name": query.Supplier},
+			{"metadata.component.supplier.name": query.Supplier},
+		}})
+	}
+	for _, property := range query.Properties {
+		conditions = append(conditions, bson.M{"metadata.properties": bson.M{
+			"$elemMatch": bson.M{"name": property.Name, "value": property.Value},
+		}})
 	}
 
-	// Query MongoDB
+	if len(conditions) == 0 {
+		return bson.M{}
+	}
+
+	return bson.M{"$and": conditions}
+}
+
+func (c *ComponentSbomStore) GetSbomsUsingFilter(filter interface{}, page, limit, duration int) ([]types.Sbom, error) {
+	var sboms []types.Sbom
+
+	findOptions := options.Find().
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetProjection(metadataProjection)
+
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(duration)*time.Second)
 	defer cancel()
 
-	// Define the filter to match any of the ObjectIDs
-	filter := bson.M{"_id": bson.M{"$in": objectIDs}}
+	cursor, err := c.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return sboms, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &sboms); err != nil {
+		return sboms, err
+	}
+
+	return sboms, nil
+}
 
-	result, err := c.collection.DeleteMany(ctx, filter)
+// returns ids of all sboms matching the filter
+func (c *ComponentSbomStore) GetSbomIdsUsingFilter(filter interface{}, duration int) ([]string, error) {
+	ids := []string{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(duration)*time.Second)
+	defer cancel()
+
+	cursor, err := c.collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return ids, err
+	}
+	defer cursor.Close(ctx)
+
+	var sboms []types.Sbom
+	if err := cursor.All(ctx, &sboms); err != nil {
+		return ids, err
+	}
+
+	for _, sbom := range sboms {
+		ids = append(ids, sbom.Id)
+	}
+
+	return ids, nil
+}
+
+// returns quality of sboms indexed by sbom id, sboms stored before quality scoring was
+// introduced have nil quality
+func (c *ComponentSbomStore) GetSbomQualities(ids []string, duration int) (map[string]*types.SbomQuality, error) {
+	qualities := map[string]*types.SbomQuality{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(duration)*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": bson.M{"$in": utils.GetMongoObjectIds(ids)}}
+	cursor, err := c.collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"quality": 1}))
+	if err != nil {
+		return qualities, err
+	}
+	defer cursor.Close(ctx)
+
+	var sboms []types.Sbom
+	if err := cursor.All(ctx, &sboms); err != nil {
+		return qualities, err
+	}
+
+	for _, sbom := range sboms {
+		qualities[sbom.Id] = sbom.Quality
+	}
+
+	return qualities, nil
+}
+
+func (c *ComponentSbomStore) UpdateQuality(sbomId string, quality types.SbomQuality, duration int) error {
+	objID, err := primitive.ObjectIDFromHex(sbomId)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(duration)*time.Second)
+	defer cancel()
+
+	if _, err := c.collection.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": bson.M{"quality": quality}}); err != nil {
+		log.Error().Err(err).Msgf("failed to update quality of sbom %s", sbomId)
+		return err
+	}
+
+	return nil
+}
+
+// stores signature verification of sbom using trusted keys of project. Unverified result does not
+// replace a verified one, so a later unsigned upload of the same sbom keeps the project verification.
+func (c *ComponentSbomStore) UpdateSignature(sbomId, projectId string, signature types.SbomSignature, duration int) error {
+	objID, err := primitive.ObjectIDFromHex(sbomId)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(duration)*time.Second)
+	defer cancel()
+
+	field := "project_signatures." + projectId
+	filter := bson.M{"_id": objID}
+	if !signature.Verified {
+		filter[field+".verified"] = bson.M{"$ne": true}
+	}
+
+	if _, err := c.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{field: signature}}); err != nil {
+		log.Error().Err(err).Msgf("failed to update signature verification of sbom %s for project %s", sbomId, projectId)
+		return err
+	}
+
+	return nil
+}
+
+// adds digests of artifact described by sbom
+func (c *ComponentSbomStore) AddArtifactDigests(sbomId string, digests []string, duration int) error {
+	objID, err := primitive.ObjectIDFromHex(sbomId)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(duration)*time.Second)
+	defer cancel()
+
+	if _, err := c.collection.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$addToSet": bson.M{"artifact_digests": bson.M{"$each": digests}}}); err != nil {
+		log.Error().Err(err).Msgf("failed to add artifact digests of sbom %s", sbomId)
+		return err
+	}
+
+	return nil
+}
+
+// sets provenance of sboms describing artifact with any of the digests, returns number of linked
+// sboms. Only sboms with given ids are linked, so that provenance verified using keys of a project
+// is never linked to sboms of other projects.
+func (c *ComponentSbomStore) LinkProvenance(sbomIds, digests []string, provenance types.Provenance, duration int) (int64, error) {
+	if len(sbomIds) == 0 || len(digests) == 0 {
+		return 0, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(duration)*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": bson.M{"$in": utils.GetMongoObjectIds(sbomIds)}, "artifact_digests": bson.M{"$in": digests}}
+	result, err := c.collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"provenance": provenance}})
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to link provenance attestation %s to sboms", provenance.AttestationId)
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// stores analysis completeness status of sbom
+func (c *ComponentSbomStore) UpdateAnalysis(sbomId string, analysis types.SbomAnalysis, duration int) error {
+	objID, err := primitive.ObjectIDFromHex(sbomId)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(duration)*time.Second)
+	defer cancel()
+
+	if _, err := c.collection.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": bson.M{"analysis": analysis}}); err != nil {
+		log.Error().Err(err).Msgf("failed to update analysis status of sbom %s", sbomId)
+		return err
+	}
+
+	return nil
+}
+
+// returns ids and blob keys of existing sboms
+func (c *ComponentSbomStore) GetSbomBlobs(ids []string, duration int) ([]types.Sbom, error) {
+	sboms := []types.Sbom{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(duration)*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": bson.M{"$in": utils.GetMongoObjectIds(ids)}}
+	cursor, err := c.collection.Find(ctx, filter, options.Find().SetProjection(blobProjection))
+	if err != nil {
+		log.Error().Err(err).Msg("failed to fetch blobs of sboms")
+		return sboms, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &sboms); err != nil {
+		log.Error().Err(err).Msg("failed to fetch blobs of sboms")
+		return sboms, err
+	}
+
+	return sboms, nil
+}
+
+// deletes sboms from db without deleting their blobs, so that deletion can be part of a
+// transaction. Blobs should be deleted using DeleteBlobs once deletion is committed.
+func (c *ComponentSbomStore) DeleteDocuments(ctx context.Context, ids []string) (int64, error) {
+	result, err := c.collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": utils.GetMongoObjectIds(ids)}})
 	if err != nil {
 		log.Error().Err(err).Msgf("Failed to delete documents: %v", err)
 		return -1, err
@@ -182,6 +667,37 @@ func (c *ComponentSbomStore) DeleteByIds(idParams []string, duration int) (int64
 	return result.DeletedCount, nil
 }
 
+// deletes blobs of deleted sboms
+func (c *ComponentSbomStore) DeleteBlobs(sboms []types.Sbom) {
+	for _, sbom := range sboms {
+		c.deleteBlobs(sbom)
+	}
+}
+
+// runs fn in a transaction of sbom database, see db.WithTransaction
+func (c *ComponentSbomStore) WithTransaction(duration int, fn func(ctx context.Context) error) (bool, error) {
+	return db.WithTransaction(c.db.Client(), duration, fn)
+}
+
+func (c *ComponentSbomStore) DeleteByIds(idParams []string, duration int) (int64, error) {
+	// blobs are deleted once sboms are deleted
+	sboms, err := c.GetSbomBlobs(idParams, duration)
+	if err != nil {
+		return -1, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(duration)*time.Second)
+	defer cancel()
+
+	deleted, err := c.DeleteDocuments(ctx, idParams)
+	if err != nil {
+		return -1, err
+	}
+	c.DeleteBlobs(sboms)
+
+	return deleted, nil
+}
+
 func (c *ComponentSbomStore) DeleteById(idParam string, duration int) (int64, error) {
 	return c.DeleteByIds([]string{idParam}, duration)
 }
@@ -0,0 +1,78 @@
+package scan
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dmdhrumilmistry/defect-detect/pkg/config"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type ScanHandler struct {
+	store     types.ScanStore
+	authStore types.AuthStore
+}
+
+func NewScanHandler(store types.ScanStore, authStore types.AuthStore) *ScanHandler {
+	return &ScanHandler{
+		store:     store,
+		authStore: authStore,
+	}
+}
+
+func (s *ScanHandler) RegisterRoutes(r *gin.Engine) {
+	// api v1
+	r.GET("/api/v1/scans", s.GetScans)
+
+	log.Info().Msg("Scan routes registered")
+}
+
+// curl "http://localhost:8080/api/v1/scans?project_ids=676f0bac3da126bf929f246c&statuses=failed"
+func (s *ScanHandler) GetScans(c *gin.Context) {
+	// Get page and limit from query parameters
+	pageStr := c.DefaultQuery("page", "1")
+	limitStr := c.DefaultQuery("limit", "10")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page number"})
+		return
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 || limit >= 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit number"})
+		return
+	}
+
+	filter := utils.BuildDynamicContainsFilter(map[string][]string{
+		"project_id": utils.Split(c.DefaultQuery("project_ids", ""), ","),
+		"status":     utils.Split(c.DefaultQuery("statuses", ""), ","),
+		"trigger":    utils.Split(c.DefaultQuery("triggers", ""), ","),
+	})
+
+	runs, err := s.store.GetScanRunsUsingFilter(filter, page, limit, config.DefaultConfig.DbQueryTimeout)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to fetch scan runs")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse data"})
+		return
+	}
+
+	total, err := s.store.GetTotalCount(filter)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get total scan runs")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse data"})
+		return
+	}
+
+	// Build response
+	c.JSON(http.StatusOK, gin.H{
+		"data":  runs,
+		"page":  page,
+		"limit": limit,
+		"total": total,
+	})
+}
@@ -0,0 +1,289 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dmdhrumilmistry/defect-detect/pkg/config"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// value used in project scan schedule to disable scheduled rescans
+const SCHEDULE_OFF = "off"
+
+type Scheduler struct {
+	store          types.ScanStore
+	projectStore   types.ProjectStore
+	sbomStore      types.SbomStore
+	componentStore types.ComponentStore
+	jobStore       types.JobStore
+
+	defaultSchedule string
+	interval        time.Duration
+	lockTtl         time.Duration
+	owner           string
+}
+
+func NewScheduler(store types.ScanStore, projectStore types.ProjectStore, sbomStore types.SbomStore, componentStore types.ComponentStore, jobStore types.JobStore) *Scheduler {
+	hostname, err := os.Hostname()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get hostname for scheduler owner id")
+		hostname = "unknown"
+	}
+
+	return &Scheduler{
+		store:          store,
+		projectStore:   projectStore,
+		sbomStore:      sbomStore,
+		componentStore: componentStore,
+		jobStore:       jobStore,
+
+		defaultSchedule: config.DefaultConfig.ScanSchedule,
+		interval:        time.Duration(config.DefaultConfig.ScanSchedulerInterval) * time.Second,
+		lockTtl:         time.Duration(config.DefaultConfig.ScanLockTtlInSeconds) * time.Second,
+		owner:           fmt.Sprintf("%s-%s", hostname, uuid.New().String()),
+	}
+}
+
+// ValidateSchedule returns error if schedule is neither empty, "off" nor a valid cron expression
+func ValidateSchedule(schedule string) error {
+	schedule = strings.TrimSpace(schedule)
+	if schedule == "" || strings.ToLower(schedule) == SCHEDULE_OFF {
+		return nil
+	}
+
+	_, err := cron.ParseStandard(schedule)
+	return err
+}
+
+// Start evaluates project schedules periodically until ctx is cancelled
+func (s *Scheduler) Start(ctx context.Context) {
+	if s.interval <= 0 {
+		log.Warn().Msg("invalid scan scheduler interval, scheduled rescans are disabled")
+		return
+	}
+
+	if s.defaultSchedule != "" {
+		if _, err := cron.ParseStandard(s.defaultSchedule); err != nil {
+			log.Error().Err(err).Msgf("invalid default scan schedule %s", s.defaultSchedule)
+			s.defaultSchedule = ""
+		}
+	}
+
+	log.Info().Msgf("Starting scan scheduler %s with default schedule %q", s.owner, s.defaultSchedule)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	lastTick := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Stopping scan scheduler")
+			return
+		case now := <-ticker.C:
+			s.RunDueScans(lastTick, now)
+			lastTick = now
+		}
+	}
+}
+
+// returns project schedule, falls back to default schedule
+func (s *Scheduler) getProjectSchedule(project types.Project) string {
+	schedule := strings.TrimSpace(project.ScanSchedule)
+	if schedule == "" {
+		return s.defaultSchedule
+	}
+
+	if strings.ToLower(schedule) == SCHEDULE_OFF {
+		return ""
+	}
+
+	return schedule
+}
+
+// RunDueScans queues rescans of projects which have a scheduled activation between from and to,
+// rescans are run by job runner
+func (s *Scheduler) RunDueScans(from, to time.Time) {
+	page := 1
+	limit := 50
+
+	for {
+		projects, err := s.projectStore.GetUsingFilter(bson.M{}, page, limit, config.DefaultConfig.DbQueryTimeout)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to fetch projects for scheduled rescans")
+			return
+		}
+
+		for _, project := range projects {
+			schedule := s.getProjectSchedule(project)
+			if schedule == "" {
+				continue
+			}
+
+			sched, err := cron.ParseStandard(schedule)
+			if err != nil {
+				log.Error().Err(err).Msgf("invalid scan schedule %q for project %s", schedule, project.Id)
+				continue
+			}
+
+			activation := sched.Next(from)
+			if activation.After(to) {
+				continue
+			}
+
+			// only one replica should execute a scheduled activation
+			lockId := fmt.Sprintf("%s:%d", project.Id, activation.Unix())
+			acquired, err := s.store.AcquireLock(lockId, s.owner, s.lockTtl)
+			if err != nil || !acquired {
+				log.Debug().Msgf("skipping scheduled rescan for project %s, lock not acquired", project.Id)
+				continue
+			}
+
+			jobId, err := s.jobStore.AddJob(types.Job{
+				Type:   types.JobTypeRescanProject,
+				Rescan: &types.ProjectRescanJob{ProjectId: project.Id, Schedule: schedule},
+			})
+			if err != nil {
+				log.Error().Err(err).Msgf("failed to queue scheduled rescan for project %s", project.Id)
+				continue
+			}
+			log.Info().Msgf("Queued scheduled rescan job %s for project %s", jobId, project.Id)
+		}
+
+		if len(projects) < limit {
+			return
+		}
+		page++
+	}
+}
+
+// rescans sbom locked by job and releases it once rescan is done
+func (s *Scheduler) rescanSbom(ctx context.Context, jobId, sbomId string) (int, error) {
+	defer func() {
+		if err := s.jobStore.SetJobSbom(jobId, ""); err != nil {
+			log.Error().Err(err).Msgf("failed to release sbom %s of job %s", sbomId, jobId)
+		}
+	}()
+
+	// sbom may have been deleted before it was locked
+	sbom, err := s.sbomStore.GetSbomById(sbomId, config.DefaultConfig.DbQueryTimeout)
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to fetch sbom %s for rescan", sbomId)
+		return 0, fmt.Errorf("failed to fetch sbom")
+	}
+
+	stored, err := s.componentStore.RescanComponentsUsingSbom(ctx, sbom)
+	if err != nil {
+		return stored, err
+	}
+
+	if analysis, err := s.componentStore.GetSbomAnalysis(sbomId); err == nil {
+		if err := s.sbomStore.UpdateAnalysis(sbomId, analysis, config.DefaultConfig.DbQueryTimeout); err != nil {
+			log.Error().Err(err).Msgf("failed to store analysis status of sbom %s", sbomId)
+		}
+	}
+
+	return stored, nil
+}
+
+// RescanProject re-analyzes sboms in retention window of the project and records the run. Error
+// is returned when project can't be fetched or rescan is interrupted by ctx, failures of
+// individual sboms are recorded on the run. Job becomes the active job of each sbom while it is
+// rescanned, sboms with another active job are skipped.
+func (s *Scheduler) RescanProject(ctx context.Context, request types.ProjectRescanJob, jobId string, progress types.ProgressFunc) (types.ScanRun, error) {
+	projects, err := s.projectStore.GetProjectById(request.ProjectId, config.DefaultConfig.DbQueryTimeout)
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to fetch project %s for rescan", request.ProjectId)
+		return types.ScanRun{}, err
+	} else if len(projects) == 0 {
+		return types.ScanRun{}, fmt.Errorf("project %s not found", request.ProjectId)
+	}
+	project := projects[0]
+
+	sbomIds := project.Sboms
+	if project.SbomsToRetain > 0 && len(sbomIds) > project.SbomsToRetain {
+		// latest sboms should be present at 0th position
+		sbomIds = sbomIds[:project.SbomsToRetain]
+	}
+
+	run := types.ScanRun{
+		ProjectId: project.Id,
+		JobId:     jobId,
+		SbomIds:   sbomIds,
+		Trigger:   types.ScanTriggerScheduled,
+		Schedule:  request.Schedule,
+		Status:    types.ScanStatusRunning,
+		Owner:     s.owner,
+		StartedAt: time.Now(),
+	}
+
+	runId, err := s.store.AddScanRun(run)
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to record scan run for project %s", project.Id)
+		return run, err
+	}
+	run.Id = runId
+	log.Info().Msgf("Started scan run %s for project %s", runId, project.Id)
+
+	var errs []string
+	for i, sbomId := range sbomIds {
+		if progress != nil {
+			progress(i, len(errs), len(sbomIds))
+		}
+
+		// rescan would race with analysis of the sbom or with its deletion
+		if err := s.jobStore.SetJobSbom(jobId, sbomId); errors.Is(err, types.ErrActiveJobExists) {
+			log.Info().Msgf("skipping rescan of sbom %s, sbom has another active job", sbomId)
+			run.SkippedSbomIds = append(run.SkippedSbomIds, sbomId)
+			continue
+		} else if err != nil {
+			errs = append(errs, fmt.Sprintf("sbom %s: failed to lock sbom", sbomId))
+			continue
+		}
+
+		stored, err := s.rescanSbom(ctx, jobId, sbomId)
+		if errors.Is(err, context.Canceled) {
+			// runner is shutting down, rescan is run again once job is resumed
+			run.Status = types.ScanStatusFailed
+			run.Error = "rescan interrupted by shutdown"
+			run.FinishedAt = time.Now()
+			if err := s.store.UpdateScanRun(run, config.DefaultConfig.DbQueryTimeout); err != nil {
+				log.Error().Err(err).Msgf("failed to update scan run %s", runId)
+			}
+			return run, err
+		} else if err != nil {
+			log.Error().Err(err).Msgf("failed to rescan sbom %s", sbomId)
+			errs = append(errs, fmt.Sprintf("sbom %s: %v", sbomId, err))
+			continue
+		}
+		run.ComponentsScanned += stored
+	}
+
+	if progress != nil {
+		progress(len(sbomIds), len(errs), len(sbomIds))
+	}
+
+	run.FinishedAt = time.Now()
+	run.DurationMs = run.FinishedAt.Sub(run.StartedAt).Milliseconds()
+	run.Status = types.ScanStatusSucceeded
+	if len(errs) > 0 {
+		run.Status = types.ScanStatusFailed
+		run.Error = strings.Join(errs, "; ")
+	}
+
+	if err := s.store.UpdateScanRun(run, config.DefaultConfig.DbQueryTimeout); err != nil {
+		log.Error().Err(err).Msgf("failed to update scan run %s", runId)
+	}
+	log.Info().Msgf("Scan run %s for project %s completed with status %s in %dms", runId, project.Id, run.Status, run.DurationMs)
+
+	return run, nil
+}
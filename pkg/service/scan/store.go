@@ -0,0 +1,134 @@
+package scan
+
+import (
+	"context"
+	"time"
+
+	"github.com/dmdhrumilmistry/defect-detect/pkg/db"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const SCAN_COLLECTION = "scan"
+const SCAN_LOCK_COLLECTION = "scan_lock"
+
+type ScanStore struct {
+	db             *mongo.Database
+	collection     *mongo.Collection
+	lockCollection *mongo.Collection
+}
+
+func NewScanStore(mdb *mongo.Database) *ScanStore {
+	collection := mdb.Collection(SCAN_COLLECTION)
+	lockCollection := mdb.Collection(SCAN_LOCK_COLLECTION)
+
+	db.EnsureIndex(collection, mongo.IndexModel{
+		Keys: bson.D{{Key: "project_id", Value: 1}, {Key: "started_at", Value: -1}},
+	})
+
+	// expired locks are removed by mongo
+	db.EnsureIndex(lockCollection, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+
+	return &ScanStore{
+		db:             mdb,
+		collection:     collection,
+		lockCollection: lockCollection,
+	}
+}
+
+func (s *ScanStore) AddScanRun(run types.ScanRun) (string, error) {
+	result, err := s.collection.InsertOne(context.TODO(), run)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to insert scan run")
+		return "", err
+	}
+
+	return (result.InsertedID).(primitive.ObjectID).Hex(), nil
+}
+
+func (s *ScanStore) UpdateScanRun(run types.ScanRun, duration int) error {
+	objectId, err := primitive.ObjectIDFromHex(run.Id)
+	if err != nil {
+		log.Error().Err(err).Msg("invalid object id")
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(duration)*time.Second)
+	defer cancel()
+
+	run.Id = ""
+	if _, err := s.collection.ReplaceOne(ctx, bson.M{"_id": objectId}, run); err != nil {
+		log.Error().Err(err).Msgf("failed to update scan run with id: %s", objectId.Hex())
+		return err
+	}
+
+	return nil
+}
+
+func (s *ScanStore) GetTotalCount(filter interface{}) (int64, error) {
+	// Get total count of documents
+	total, err := s.collection.CountDocuments(context.TODO(), filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// returns scan runs matching filter with latest runs first
+func (s *ScanStore) GetScanRunsUsingFilter(filter interface{}, page, limit, duration int) ([]types.ScanRun, error) {
+	var runs []types.ScanRun
+
+	// Calculate skip
+	skip := (page - 1) * limit
+
+	// MongoDB query options
+	findOptions := options.Find()
+	findOptions.SetSkip(int64(skip))
+	findOptions.SetLimit(int64(limit))
+	findOptions.SetSort(bson.D{{Key: "started_at", Value: -1}})
+
+	// Query MongoDB
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(duration)*time.Second)
+	defer cancel()
+
+	cursor, err := s.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return runs, err
+	}
+	defer cursor.Close(ctx)
+
+	// Parse results
+	if err := cursor.All(ctx, &runs); err != nil {
+		return runs, err
+	}
+
+	return runs, nil
+}
+
+// AcquireLock tries to create lock document with provided id. Returns false without error
+// when lock is already held by another owner.
+func (s *ScanStore) AcquireLock(lockId, owner string, ttl time.Duration) (bool, error) {
+	lock := types.ScanLock{
+		Id:        lockId,
+		Owner:     owner,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+
+	if _, err := s.lockCollection.InsertOne(context.TODO(), lock); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		log.Error().Err(err).Msgf("failed to acquire lock %s", lockId)
+		return false, err
+	}
+
+	return true, nil
+}
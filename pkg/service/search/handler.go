@@ -0,0 +1,147 @@
+package search
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/dmdhrumilmistry/defect-detect/pkg/config"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+var searchScopes = []string{types.SearchScopeAll, types.SearchScopeSboms, types.SearchScopeComponents}
+
+type SearchHandler struct {
+	sbomStore      types.SbomStore
+	componentStore types.ComponentStore
+	authStore      types.AuthStore
+}
+
+func NewSearchHandler(sbomStore types.SbomStore, componentStore types.ComponentStore, authStore types.AuthStore) *SearchHandler {
+	return &SearchHandler{
+		sbomStore:      sbomStore,
+		componentStore: componentStore,
+		authStore:      authStore,
+	}
+}
+
+func (s *SearchHandler) RegisterRoutes(r *gin.Engine) {
+	// api v1
+	r.GET("/api/v1/search", s.Search)
+
+	log.Info().Msg("Search routes registered")
+}
+
+// returns search query from query params. Properties are passed as name=value.
+func getSearchQuery(c *gin.Context) types.SearchQuery {
+	query := types.SearchQuery{
+		Name:          c.Query("name"),
+		NamePrefix:    c.Query("name_prefix"),
+		NameRegex:     c.Query("name_regex"),
+		Purl:          c.Query("purl"),
+		Hash:          c.Query("hash"),
+		HashAlgorithm: c.Query("hash_alg"),
+		Supplier:      c.Query("supplier"),
+	}
+
+	for _, property := range c.QueryArray("property") {
+		name, value, _ := strings.Cut(property, "=")
+		query.Properties = append(query.Properties, types.SearchProperty{Name: name, Value: value})
+	}
+
+	return query
+}
+
+// searches sboms using metadata and components of all sboms
+// curl "http://localhost:8080/api/v1/search?purl=pkg:npm/lodash&property=git:commit=1a2b3c"
+func (s *SearchHandler) Search(c *gin.Context) {
+	// Get page and limit from query parameters
+	pageStr := c.DefaultQuery("page", "1")
+	limitStr := c.DefaultQuery("limit", "10")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page number"})
+		return
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 || limit >= 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit number"})
+		return
+	}
+
+	scope := c.DefaultQuery("scope", types.SearchScopeAll)
+	if !slices.Contains(searchScopes, scope) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid scope, supported scopes: " + strings.Join(searchScopes, ", ")})
+		return
+	}
+
+	query := getSearchQuery(c)
+	if err := query.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := gin.H{
+		"page":  page,
+		"limit": limit,
+	}
+
+	if scope != types.SearchScopeComponents {
+		filter := s.sbomStore.GetSearchFilter(query)
+		sboms, err := s.sbomStore.GetSbomsUsingFilter(filter, page, limit, config.DefaultConfig.DbQueryTimeout)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to search sboms")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search sboms"})
+			return
+		}
+
+		total, err := s.sbomStore.GetTotalCount(filter)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to get total sboms")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search sboms"})
+			return
+		}
+
+		response["sboms"] = gin.H{"data": sboms, "total": total}
+	}
+
+	if scope != types.SearchScopeSboms {
+		// components do not have sbom metadata, so they are limited to sboms matching properties
+		var sbomIds []string
+		if len(query.Properties) > 0 {
+			sbomIds, err = s.sbomStore.GetSbomIdsUsingFilter(s.sbomStore.GetSearchFilter(types.SearchQuery{Properties: query.Properties}), config.DefaultConfig.DbQueryTimeout)
+			if err != nil {
+				log.Error().Err(err).Msg("failed to search sboms using properties")
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search components"})
+				return
+			}
+		}
+
+		componentQuery := query
+		componentQuery.Properties = nil
+		filter := s.componentStore.GetSearchFilter(componentQuery, sbomIds)
+
+		components, err := s.componentStore.GetComponentsUsingFilter(filter, page, limit, config.DefaultConfig.DbQueryTimeout)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to search components")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search components"})
+			return
+		}
+
+		total, err := s.componentStore.GetComponentTotalCount(filter)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to get total components")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search components"})
+			return
+		}
+
+		response["components"] = gin.H{"data": components, "total": total}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,148 @@
+package vex
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dmdhrumilmistry/defect-detect/pkg/config"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/utils"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/vexformat"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type VexHandler struct {
+	store          types.VexStore
+	componentStore types.ComponentStore
+	authStore      types.AuthStore
+}
+
+func NewVexHandler(store types.VexStore, componentStore types.ComponentStore, authStore types.AuthStore) *VexHandler {
+	return &VexHandler{
+		store:          store,
+		componentStore: componentStore,
+		authStore:      authStore,
+	}
+}
+
+func (v *VexHandler) RegisterRoutes(r *gin.Engine) {
+	// api v1
+	r.POST("/api/v1/vex", v.UploadVex)
+	r.GET("/api/v1/vex", v.GetStatements)
+
+	log.Info().Msg("Vex routes registered")
+}
+
+// stores statements of cyclonedx vex or openvex document and applies them to analyzed components
+// curl -X POST -F "vex=@vex.openvex.json" http://localhost:8080/api/v1/vex
+func (v *VexHandler) UploadVex(c *gin.Context) {
+	file, err := c.FormFile("vex")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to upload file"})
+		return
+	}
+
+	fileContent, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid file content"})
+		return
+	}
+	defer fileContent.Close()
+
+	content, err := io.ReadAll(fileContent)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid file content"})
+		return
+	}
+
+	format, statements, skipped, err := vexformat.Parse(content, file.Header.Get("Content-Type"), file.Filename)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to parse vex document")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid VEX document"})
+		return
+	} else if len(statements) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "VEX document does not have applicable statements", "skipped": skipped})
+		return
+	}
+
+	now := time.Now()
+	for i := range statements {
+		statements[i].CreatedAt = now
+	}
+
+	ids, err := v.store.UpsertStatements(statements)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store VEX statements"})
+		return
+	}
+	for i, id := range ids {
+		statements[i].Id = id
+	}
+
+	resp := gin.H{
+		"message":       "VEX statements stored successfully",
+		"format":        format,
+		"statement_ids": ids,
+		"skipped":       skipped,
+	}
+
+	// components analyzed later get statements applied during analysis
+	updated, err := v.componentStore.ApplyVexStatements(statements)
+	if err != nil {
+		resp["error"] = "failed to apply VEX statements to analyzed components"
+	}
+	resp["components_updated"] = updated
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// curl "http://localhost:8080/api/v1/vex?vuln_ids=CVE-2021-23337&statuses=not_affected"
+func (v *VexHandler) GetStatements(c *gin.Context) {
+	// Get page and limit from query parameters
+	pageStr := c.DefaultQuery("page", "1")
+	limitStr := c.DefaultQuery("limit", "10")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page number"})
+		return
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 || limit >= 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit number"})
+		return
+	}
+
+	filter := utils.BuildDynamicContainsFilter(map[string][]string{
+		"vuln_id":      utils.Split(c.DefaultQuery("vuln_ids", ""), ","),
+		"product_purl": utils.Split(c.DefaultQuery("product_purls", ""), ","),
+		"status":       utils.Split(c.DefaultQuery("statuses", ""), ","),
+		"document_id":  utils.Split(c.DefaultQuery("document_ids", ""), ","),
+	})
+
+	statements, err := v.store.GetStatementsUsingFilter(filter, page, limit, config.DefaultConfig.DbQueryTimeout)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to fetch vex statements")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse data"})
+		return
+	}
+
+	total, err := v.store.GetTotalCount(filter)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get total vex statements")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse data"})
+		return
+	}
+
+	// Build response
+	c.JSON(http.StatusOK, gin.H{
+		"data":  statements,
+		"page":  page,
+		"limit": limit,
+		"total": total,
+	})
+}
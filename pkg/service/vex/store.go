@@ -0,0 +1,188 @@
+package vex
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dmdhrumilmistry/defect-detect/pkg/db"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const VEX_STATEMENT_COLLECTION = "vex_statement"
+
+type VexStore struct {
+	db         *mongo.Database
+	collection *mongo.Collection
+}
+
+func NewVexStore(mdb *mongo.Database) *VexStore {
+	collection := mdb.Collection(VEX_STATEMENT_COLLECTION)
+
+	// statements are looked up using vuln ids of analyzed components
+	db.EnsureIndex(collection, mongo.IndexModel{
+		Keys: bson.D{{Key: "vuln_id", Value: 1}},
+	})
+	db.EnsureIndex(collection, mongo.IndexModel{
+		Keys: bson.D{{Key: "aliases", Value: 1}},
+	})
+	// statements stored before re-uploads were deduplicated do not have a key
+	db.EnsureIndex(collection, mongo.IndexModel{
+		Keys:    bson.D{{Key: "statement_key", Value: 1}},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"statement_key": bson.M{"$type": "string"}}),
+	})
+
+	return &VexStore{
+		db:         mdb,
+		collection: collection,
+	}
+}
+
+// returns key identifying statement of a document about vuln and product along with its subcomponents
+func getStatementKey(statement types.VexStatement) string {
+	subcomponentPurls := slices.Clone(statement.SubcomponentPurls)
+	slices.Sort(subcomponentPurls)
+
+	fields := append([]string{statement.DocumentId, statement.VulnId, statement.ProductPurl}, subcomponentPurls...)
+	hash := sha256.Sum256([]byte(strings.Join(fields, "\x00")))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// stores statements and returns their ids in the same order. Statements of the same document
+// about the same vuln and product replace stored ones, which keep their id and created_at.
+func (v *VexStore) UpsertStatements(statements []types.VexStatement) ([]string, error) {
+	ids := []string{}
+	if len(statements) == 0 {
+		return ids, nil
+	}
+
+	ctx := context.TODO()
+	keys := make([]string, 0, len(statements))
+	models := make([]mongo.WriteModel, 0, len(statements))
+	for _, statement := range statements {
+		statement.Id = ""
+		statement.Key = getStatementKey(statement)
+		keys = append(keys, statement.Key)
+
+		content, err := bson.Marshal(statement)
+		if err != nil {
+			return ids, err
+		}
+		var fields bson.M
+		if err := bson.Unmarshal(content, &fields); err != nil {
+			return ids, err
+		}
+		delete(fields, "created_at")
+
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"statement_key": statement.Key}).
+			SetUpdate(bson.M{"$set": fields, "$setOnInsert": bson.M{"created_at": statement.CreatedAt}}).
+			SetUpsert(true))
+	}
+
+	if _, err := v.collection.BulkWrite(ctx, models); err != nil {
+		log.Error().Err(err).Msg("failed to upsert vex statements")
+		return ids, err
+	}
+
+	cursor, err := v.collection.Find(ctx, bson.M{"statement_key": bson.M{"$in": keys}}, options.Find().SetProjection(bson.M{"statement_key": 1}))
+	if err != nil {
+		log.Error().Err(err).Msg("failed to fetch ids of upserted vex statements")
+		return ids, err
+	}
+	defer cursor.Close(ctx)
+
+	keyIds := map[string]string{}
+	for cursor.Next(ctx) {
+		var statement types.VexStatement
+		if err := cursor.Decode(&statement); err != nil {
+			return ids, err
+		}
+		keyIds[statement.Key] = statement.Id
+	}
+	if err := cursor.Err(); err != nil {
+		return ids, err
+	}
+
+	for _, key := range keys {
+		ids = append(ids, keyIds[key])
+	}
+
+	return ids, nil
+}
+
+// returns statements of vulns matching any of the ids or their aliases
+func (v *VexStore) GetStatementsByVulnIds(vulnIds []string, duration int) ([]types.VexStatement, error) {
+	var statements []types.VexStatement
+	if len(vulnIds) == 0 {
+		return statements, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(duration)*time.Second)
+	defer cancel()
+
+	filter := bson.M{"$or": []bson.M{
+		{"vuln_id": bson.M{"$in": vulnIds}},
+		{"aliases": bson.M{"$in": vulnIds}},
+	}}
+	cursor, err := v.collection.Find(ctx, filter)
+	if err != nil {
+		return statements, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &statements); err != nil {
+		return statements, err
+	}
+
+	return statements, nil
+}
+
+func (v *VexStore) GetTotalCount(filter interface{}) (int64, error) {
+	// Get total count of documents
+	total, err := v.collection.CountDocuments(context.TODO(), filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// returns statements matching filter with latest statements first
+func (v *VexStore) GetStatementsUsingFilter(filter interface{}, page, limit, duration int) ([]types.VexStatement, error) {
+	var statements []types.VexStatement
+
+	// Calculate skip
+	skip := (page - 1) * limit
+
+	// MongoDB query options
+	findOptions := options.Find()
+	findOptions.SetSkip(int64(skip))
+	findOptions.SetLimit(int64(limit))
+	findOptions.SetSort(bson.D{{Key: "timestamp", Value: -1}})
+
+	// Query MongoDB
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(duration)*time.Second)
+	defer cancel()
+
+	cursor, err := v.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return statements, err
+	}
+	defer cursor.Close(ctx)
+
+	// Parse results
+	if err := cursor.All(ctx, &statements); err != nil {
+		return statements, err
+	}
+
+	return statements, nil
+}
@@ -1,12 +1,58 @@
 package types
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 type Analyzer interface {
 	GetVulns(purl string) ([]Vuln, error)
 	GetPackageInfo(purl string) ([]PackageInfo, error)
 }
 
+const (
+	AnalyzerErrClassNetwork    = "network"
+	AnalyzerErrClassTimeout    = "timeout"
+	AnalyzerErrClassRateLimit  = "rate_limited"
+	AnalyzerErrClassHttpStatus = "http_status"
+	AnalyzerErrClassDecode     = "decode"
+	AnalyzerErrClassDisabled   = "disabled"
+	AnalyzerErrClassUnknown    = "unknown"
+)
+
+// AnalyzerErr wraps error returned by an analyzer along with analyzer name and error class
+type AnalyzerErr struct {
+	Analyzer string
+	Class    string
+	Err      error
+}
+
+func (e *AnalyzerErr) Error() string {
+	return fmt.Sprintf("%s analyzer (%s): %v", e.Analyzer, e.Class, e.Err)
+}
+
+func (e *AnalyzerErr) Unwrap() error {
+	return e.Err
+}
+
+// ApiStatusErr is returned when an analyzer api responds with unexpected status code
+type ApiStatusErr struct {
+	Api        string
+	StatusCode int
+}
+
+func (e *ApiStatusErr) Error() string {
+	return fmt.Sprintf("%s api returned status code %d instead of 200", e.Api, e.StatusCode)
+}
+
+// AnalyzerError is stored with component when analyzer fails to process it
+type AnalyzerError struct {
+	Analyzer  string    `json:"analyzer" bson:"analyzer"`
+	Class     string    `json:"class" bson:"class"`
+	Message   string    `json:"message" bson:"message"`
+	Timestamp time.Time `json:"timestamp" bson:"timestamp"`
+}
+
 // Auto generated struct code for OSV response schema
 type OsvQueryApiResponse struct {
 	Vulns         []Vuln `json:"vulns,omitempty"`
@@ -0,0 +1,42 @@
+package types
+
+import "time"
+
+type AttestationStore interface {
+	AddAttestation(attestation Attestation) (string, error)
+	GetTotalCount(filter interface{}) (int64, error)
+	GetAttestationsUsingFilter(filter interface{}, page, limit, duration int) ([]Attestation, error)
+	GetProvenanceByDigests(projectId string, digests []string, duration int) (*Provenance, error)
+}
+
+// Attestation is verified in-toto statement uploaded to a project in a DSSE envelope
+type Attestation struct {
+	Id             string               `json:"id" bson:"_id,omitempty"`
+	ProjectId      string               `json:"project_id" bson:"project_id"`
+	PayloadType    string               `json:"payload_type" bson:"payload_type"`
+	StatementType  string               `json:"statement_type" bson:"statement_type"`
+	PredicateType  string               `json:"predicate_type" bson:"predicate_type"`
+	Subjects       []AttestationSubject `json:"subjects" bson:"subjects"`
+	SubjectDigests []string             `json:"subject_digests" bson:"subject_digests"` // subject digests as <algorithm>:<digest>
+	SbomId         string               `json:"sbom_id,omitempty" bson:"sbom_id,omitempty"`
+	Provenance     *Provenance          `json:"provenance,omitempty" bson:"provenance,omitempty"`
+	Signature      SbomSignature        `json:"signature_verification" bson:"signature_verification"`
+	CreatedAt      time.Time            `json:"created_at" bson:"created_at"`
+}
+
+type AttestationSubject struct {
+	Name   string            `json:"name" bson:"name"`
+	Digest map[string]string `json:"digest" bson:"digest"`
+}
+
+// Provenance describes how artifact was built as per SLSA provenance attestation
+type Provenance struct {
+	AttestationId string    `json:"attestation_id,omitempty" bson:"attestation_id,omitempty"`
+	PredicateType string    `json:"predicate_type" bson:"predicate_type"`
+	BuilderId     string    `json:"builder_id,omitempty" bson:"builder_id,omitempty"`
+	BuildType     string    `json:"build_type,omitempty" bson:"build_type,omitempty"`
+	SourceRepo    string    `json:"source_repo,omitempty" bson:"source_repo,omitempty"`
+	Commit        string    `json:"commit,omitempty" bson:"commit,omitempty"`
+	Ref           string    `json:"ref,omitempty" bson:"ref,omitempty"`
+	CreatedAt     time.Time `json:"created_at" bson:"created_at"`
+}
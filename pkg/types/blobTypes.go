@@ -0,0 +1,17 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// BlobStore stores documents too large for mongo documents such as sboms and their original
+// uploads. Keys are slash separated paths such as sbom/<hash>.json.
+type BlobStore interface {
+	Put(ctx context.Context, key string, content []byte) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+var ErrBlobNotFound = fmt.Errorf("blob not found")
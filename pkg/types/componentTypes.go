@@ -1,6 +1,8 @@
 package types
 
 import (
+	"context"
+	"slices"
 	"time"
 
 	"github.com/dmdhrumilmistry/m-paf/pkg/socketdev"
@@ -8,15 +10,32 @@ import (
 
 type ComponentStore interface {
 	AddComponentUsingSbom(sbom Sbom) ([]string, error)
+	AnalyzeComponentsUsingSbom(ctx context.Context, sbom Sbom, progress ProgressFunc) (int, error)
+	RetryFailedComponents(ctx context.Context, sbom Sbom, progress ProgressFunc) (int, error)
+	GetSbomAnalysis(sbomId string) (SbomAnalysis, error)
+	RescanComponentsUsingSbom(ctx context.Context, sbom Sbom) (int, error)
 	GetComponentTotalCount(filter interface{}) (int64, error)
 	GetPaginatedComponents(page, limit, duration int) ([]Component, error)
 	GetComponentById(idParam string, duration int) ([]Component, error)
 	GetComponentByName(name string, duration int) ([]Component, error)
-	GetVulnerableComponents(componentNames, componentVersions, sbomIds, compTypes, compNames, purls, versions []string, page, limit, duration int) (components []Component, total int64, err error)
+	ForEachComponentBySbomId(sbomId string, duration int, visit func(Component) error) error
+	GetVulnerableComponents(componentNames, componentVersions, sbomIds, compTypes, compNames, purls, versions []string, includeSuppressed bool, page, limit, duration int) (components []Component, total int64, err error)
+	ApplyVexStatements(statements []VexStatement) (int64, error)
+	GetSearchFilter(query SearchQuery, sbomIds []string) interface{}
+	GetComponentsUsingFilter(filter interface{}, page, limit, duration int) ([]Component, error)
 	DeleteByIds(idParams []string, param string, duration int) (int64, error)
 	DeleteById(idParam string, param string, duration int) (int64, error)
+	CountBySbomIds(sbomIds []string, duration int) (components, findings int64, err error)
+	DeleteBySbomIds(ctx context.Context, sbomIds []string) (int64, error)
 }
 
+// ProgressFunc is called after each sbom component is analyzed
+type ProgressFunc func(processed, failed, total int)
+
+// ComponentsFunc passes stored components of an sbom to visit one at a time and stops at the
+// first error returned by visit
+type ComponentsFunc func(visit func(Component) error) error
+
 type Component struct {
 	Id               string   `json:"component_id" bson:"_id,omitempty"`
 	Name             string   `json:"name" bson:"name"`
@@ -26,10 +45,26 @@ type Component struct {
 	Type             string   `json:"type" bson:"type"`
 	ComponentName    string   `json:"component_name" bson:"component_name"`
 	ComponentVersion string   `json:"component_version" bson:"component_version"`
+	ComponentPurl    string   `json:"component_purl,omitempty" bson:"component_purl,omitempty"`
 	SbomId           string   `json:"sbom_id" bson:"sbom_id"`
 
+	// Search
+	Hashes   []ComponentHash `json:"hashes,omitempty" bson:"hashes,omitempty"`
+	Supplier string          `json:"supplier,omitempty" bson:"supplier,omitempty"`
+
+	// Analysis checkpoint
+	Ref        string    `json:"ref" bson:"ref,omitempty"`
+	BomRef     string    `json:"bom_ref,omitempty" bson:"bom_ref,omitempty"`
+	AnalyzedAt time.Time `json:"analyzed_at" bson:"analyzed_at"`
+
+	// Nested components and services
+	ParentRef    string          `json:"parent_ref,omitempty" bson:"parent_ref,omitempty"`
+	ParentBomRef string          `json:"parent_bom_ref,omitempty" bson:"parent_bom_ref,omitempty"`
+	Service      *ServiceDetails `json:"service,omitempty" bson:"service,omitempty"`
+
 	// Analyzers
-	Vulns []Vuln `json:"vulns" bson:"vulns"`
+	Vulns          []Vuln          `json:"vulns" bson:"vulns"`
+	AnalyzerErrors []AnalyzerError `json:"analyzer_errors,omitempty" bson:"analyzer_errors,omitempty"`
 
 	// M-Paf Analyzer
 	PackageInfos []PackageInfo `json:"package_infos,omitempty"`
@@ -38,6 +73,28 @@ type Component struct {
 	// Capabilities socketdev.Capabilities `json:"capabilities,omitempty"`
 }
 
+// ComponentHash is hash of component as per uploaded sbom, digest is stored in lower case
+type ComponentHash struct {
+	Algorithm string `json:"algorithm" bson:"algorithm"`
+	Value     string `json:"value" bson:"value"`
+}
+
+// ServiceDetails stores sbom service specific fields
+type ServiceDetails struct {
+	Provider             string               `json:"provider,omitempty" bson:"provider,omitempty"`
+	Group                string               `json:"group,omitempty" bson:"group,omitempty"`
+	Description          string               `json:"description,omitempty" bson:"description,omitempty"`
+	Endpoints            []string             `json:"endpoints,omitempty" bson:"endpoints,omitempty"`
+	Authenticated        *bool                `json:"authenticated,omitempty" bson:"authenticated,omitempty"`
+	CrossesTrustBoundary *bool                `json:"x-trust-boundary,omitempty" bson:"x_trust_boundary,omitempty"`
+	DataClassifications  []DataClassification `json:"data,omitempty" bson:"data,omitempty"`
+}
+
+type DataClassification struct {
+	Flow           string `json:"flow" bson:"flow"`
+	Classification string `json:"classification" bson:"classification"`
+}
+
 type PackageInfo struct {
 	// ID        string `json:"id"`
 	// Type      string `json:"type"`
@@ -74,4 +131,35 @@ type Vuln struct {
 
 	// EPSS Score
 	Epss Epss `json:"epss,omitempty"`
+
+	// Vex statement applied to the vuln
+	Vex *VexAnalysis `json:"vex,omitempty" bson:"vex,omitempty"`
+
+	// analyzers and sboms which reported the vuln
+	Sources []VulnSource `json:"sources,omitempty" bson:"sources,omitempty"`
+
+	// pedigree patch of the component which resolves the vuln
+	Patch *VulnPatch `json:"patch,omitempty" bson:"patch,omitempty"`
+}
+
+// IsSuppressed returns true for vulns which don't affect the component as per vex statement or pedigree patch
+func (v Vuln) IsSuppressed() bool {
+	return v.Patch != nil || (v.Vex != nil && slices.Contains(VexSuppressedStatuses, v.Vex.Status))
+}
+
+const (
+	VulnOriginAnalyzer = "analyzer" // detected by analyzer using component purl
+	VulnOriginSbom     = "sbom"     // present in vulnerabilities of uploaded sbom
+)
+
+type VulnSource struct {
+	Origin string `json:"origin" bson:"origin"`
+	Name   string `json:"name,omitempty" bson:"name,omitempty"`
+	Url    string `json:"url,omitempty" bson:"url,omitempty"`
+}
+
+type VulnPatch struct {
+	Type    string `json:"type" bson:"type"`
+	IssueId string `json:"issue_id" bson:"issue_id"`
+	DiffUrl string `json:"diff_url,omitempty" bson:"diff_url,omitempty"`
 }
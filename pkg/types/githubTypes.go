@@ -0,0 +1,95 @@
+package types
+
+import (
+	"context"
+	"time"
+)
+
+type GithubIntegrationStore interface {
+	AddIntegration(integration GithubIntegration) (string, error)
+	GetTotalCount(filter interface{}) (int64, error)
+	GetIntegrationsUsingFilter(filter interface{}, page, limit, duration int) ([]GithubIntegration, error)
+	GetIntegrationById(idParam string, duration int) (GithubIntegration, error)
+	DeleteById(idParam string, duration int) (int64, error)
+}
+
+type GithubImporter interface {
+	ImportRepo(ctx context.Context, request GithubImportJob) (GithubImportResult, error)
+}
+
+// GithubIntegration stores api url and token of a github or github enterprise account. Token is
+// never part of responses.
+type GithubIntegration struct {
+	Id        string    `json:"id" bson:"_id,omitempty"`
+	Name      string    `json:"name" bson:"name"`
+	ApiUrl    string    `json:"api_url" bson:"api_url"`
+	Token     string    `json:"-" bson:"token"`
+	HasToken  bool      `json:"has_token" bson:"-"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+}
+
+type GithubIntegrationRequestSchema struct {
+	Name   string `json:"name" binding:"required"`
+	ApiUrl string `json:"api_url"` // defaults to GITHUB_API_URL
+	Token  string `json:"token"`
+}
+
+// GithubImportOptions selects github account used for importing sboms. Api url and token default
+// to integration, which defaults to GITHUB_API_URL and GITHUB_TOKEN.
+type GithubImportOptions struct {
+	ApiUrl        string `json:"api_url"`
+	Token         string `json:"token"`
+	IntegrationId string `json:"integration_id"`
+	LinkProject   bool   `json:"link_project"` // add sbom to project linked to repo, project is created when missing
+}
+
+type GithubRepoImportRequestSchema struct {
+	Owner    string `json:"owner" binding:"required"`
+	RepoName string `json:"repo_name" binding:"required"`
+	Ref      string `json:"ref"` // branch or tag, defaults to default branch of repo
+	GithubImportOptions
+}
+
+type GithubBulkImportRequestSchema struct {
+	Owner string `json:"owner" binding:"required"` // org or user account
+	GithubImportOptions
+}
+
+type GithubRepoImportResponseSchema struct {
+	Sbom map[string]interface{}
+}
+
+// GithubImportJob describes repo imported by a job. Token provided with import request is only
+// used by synchronous imports and never stored, queued imports use token of integration.
+type GithubImportJob struct {
+	Owner         string `json:"owner" bson:"owner"`
+	RepoName      string `json:"repo_name" bson:"repo_name"`
+	Ref           string `json:"ref,omitempty" bson:"ref,omitempty"`
+	ApiUrl        string `json:"api_url,omitempty" bson:"api_url,omitempty"`
+	Token         string `json:"-" bson:"-"`
+	IntegrationId string `json:"integration_id,omitempty" bson:"integration_id,omitempty"`
+	LinkProject   bool   `json:"link_project" bson:"link_project"`
+
+	// import result
+	Result *GithubImportResult `json:"result,omitempty" bson:"result,omitempty"`
+}
+
+type GithubImportResult struct {
+	SbomId            string                `json:"sbom_id" bson:"sbom_id"`
+	Duplicate         bool                  `json:"duplicate" bson:"duplicate"`
+	Repository        string                `json:"repository" bson:"repository"`
+	Ref               string                `json:"ref" bson:"ref"`
+	DependencyChanges *GithubDependencyDiff `json:"dependency_changes,omitempty" bson:"dependency_changes,omitempty"`
+	ConversionLoss    *ConversionLoss       `json:"conversion_loss,omitempty" bson:"-"`
+	AnalysisJobId     string                `json:"analysis_job_id,omitempty" bson:"analysis_job_id,omitempty"`
+	ProjectId         string                `json:"project_id,omitempty" bson:"project_id,omitempty"`
+	ProjectCreated    bool                  `json:"project_created,omitempty" bson:"project_created,omitempty"`
+	ProjectError      string                `json:"project_error,omitempty" bson:"project_error,omitempty"`
+	Evicted           []EvictedSbom         `json:"evicted,omitempty" bson:"evicted,omitempty"`
+}
+
+// GithubDependencyDiff counts dependencies changed on non default ref compared to default branch
+type GithubDependencyDiff struct {
+	Added   int `json:"added" bson:"added"`
+	Removed int `json:"removed" bson:"removed"`
+}
@@ -0,0 +1,72 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	JobStatusQueued    = "queued"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusFailed    = "failed"
+	JobStatusCancelled = "cancelled"
+
+	JobTypeAnalyzeSbom           = "analyze_sbom"
+	JobTypeRetryFailedComponents = "retry_failed_components"
+	JobTypeImportGithubRepo      = "import_github_repo"
+	JobTypeRescanProject         = "rescan_project"
+)
+
+// ErrActiveJobExists is returned along with id of the queued or running job of sbom when another job is added for it
+var ErrActiveJobExists = fmt.Errorf("sbom already has a queued or running job")
+
+type JobStore interface {
+	AddJob(job Job) (string, error)
+	GetJobById(idParam string, duration int) (Job, error)
+	GetActiveJobBySbomId(sbomId string, duration int) (Job, error)
+	GetActiveJobsBySbomIds(sbomIds []string, duration int) ([]Job, error)
+	CancelQueuedJobsBySbomIds(ctx context.Context, sbomIds []string) (int64, error)
+	HasRunningJobs(ctx context.Context, sbomIds []string) (bool, error)
+	GetTotalCount(filter interface{}) (int64, error)
+	GetJobsUsingFilter(filter interface{}, page, limit, duration int) ([]Job, error)
+	ClaimNextJob(owner string) (Job, error)
+	UpdateProgress(idParam, owner string, processed, failed, total int) (cancelRequested bool, err error)
+	FinishJob(idParam, owner, status, errMsg string, componentsCount int) error
+	ReleaseJob(idParam, owner string) error
+	CancelJob(idParam string, duration int) (Job, error)
+	RequeueStaleJobs(staleAfter time.Duration, maxAttempts int) (int64, error)
+	UpdateGithubImport(idParam string, githubImport GithubImportJob) error
+	SetJobSbom(idParam, sbomId string) error
+}
+
+// Job is a persistent unit of background work such as sbom component analysis
+type Job struct {
+	Id     string `json:"job_id" bson:"_id,omitempty"`
+	Type   string `json:"type" bson:"type"`
+	SbomId string `json:"sbom_id" bson:"sbom_id"`
+	// sbom id of queued and running jobs, unique so a sbom has at most one active job
+	ActiveSbomId string `json:"-" bson:"active_sbom_id,omitempty"`
+	Status       string `json:"status" bson:"status"`
+	Error        string `json:"error,omitempty" bson:"error,omitempty"`
+
+	// repo imported by import_github_repo jobs
+	GithubImport *GithubImportJob `json:"github_import,omitempty" bson:"github_import,omitempty"`
+	// project rescanned by rescan_project jobs
+	Rescan *ProjectRescanJob `json:"rescan,omitempty" bson:"rescan,omitempty"`
+
+	// progress
+	Total           int  `json:"total" bson:"total"`
+	Processed       int  `json:"processed" bson:"processed"`
+	Failed          int  `json:"failed" bson:"failed"`
+	ComponentsCount int  `json:"components_count" bson:"components_count"`
+	CancelRequested bool `json:"cancel_requested" bson:"cancel_requested"`
+
+	Owner       string    `json:"owner,omitempty" bson:"owner,omitempty"`
+	Attempts    int       `json:"attempts" bson:"attempts"`
+	CreatedAt   time.Time `json:"created_at" bson:"created_at"`
+	StartedAt   time.Time `json:"started_at,omitempty" bson:"started_at,omitempty"`
+	HeartbeatAt time.Time `json:"heartbeat_at,omitempty" bson:"heartbeat_at,omitempty"`
+	FinishedAt  time.Time `json:"finished_at,omitempty" bson:"finished_at,omitempty"`
+}
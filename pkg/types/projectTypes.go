@@ -1,5 +1,7 @@
 package types
 
+import "context"
+
 type ProjectStore interface {
 	AddProject(project Project) (string, error)
 	GetTotalCount(filter interface{}) (int64, error)
@@ -10,6 +12,12 @@ type ProjectStore interface {
 	DeleteByIds(idParams []string, duration int) (int64, error)
 	DeleteById(idParam string, duration int) (int64, error)
 	ValidateIds(ids []string) error
+	AddSbom(ctx context.Context, projectId, sbomId string) ([]string, error)
+	AppendSboms(ctx context.Context, projectId string, sbomIds []string) error
+	GetProjectIdsBySbomIds(sbomIds []string, duration int) ([]string, error)
+	GetUsedSbomIds(ctx context.Context, sbomIds []string, excludeProjectId string) ([]string, error)
+	DeleteProject(ctx context.Context, idParam string) (int64, error)
+	RemoveSboms(ctx context.Context, sbomIds []string) (int64, error)
 }
 
 type Project struct {
@@ -19,4 +27,39 @@ type Project struct {
 	SbomsToRetain int      `json:"sboms_to_retain" bson:"sboms_to_retain" binding:"required,min=1,max=10"`
 	Sboms         []string `json:"sboms" bson:"sboms"`
 	Links         []string `json:"links" bson:"links" binding:"min=0,max=5"`
+
+	// cron expression overriding default rescan schedule. Use "off" to disable scheduled rescans
+	ScanSchedule string `json:"scan_schedule" bson:"scan_schedule"`
+
+	// sboms uploaded to project are rejected when their quality is lower. 0 disables the check
+	MinQualityScore       int  `json:"min_quality_score" bson:"min_quality_score" binding:"min=0,max=100"`
+	RequireNtiaCompliance bool `json:"require_ntia_compliance" bson:"require_ntia_compliance"`
+
+	// public keys used to verify signatures of sboms uploaded to project
+	TrustedKeys      []TrustedKey `json:"trusted_keys" bson:"trusted_keys" binding:"max=10,dive"`
+	RequireSignature bool         `json:"require_signature" bson:"require_signature"`
+
+	// quality of project sboms, only part of responses
+	SbomQuality []ProjectSbomQuality `json:"sbom_quality,omitempty" bson:"-"`
+}
+
+// TrustedKey is PEM encoded public key or certificate (ed25519, ECDSA or RSA)
+type TrustedKey struct {
+	Name      string `json:"name" bson:"name" binding:"required"`
+	PublicKey string `json:"public_key" bson:"public_key" binding:"required"`
+}
+
+type ProjectSbomQuality struct {
+	SbomId        string `json:"sbom_id"`
+	Score         *int   `json:"score"` // nil for sboms stored before quality scoring
+	NtiaCompliant bool   `json:"ntia_compliant"`
+}
+
+// EvictedSbom reports sbom removed from project after exceeding sboms_to_retain. Sboms used by
+// other projects are only removed from the project and are not deleted.
+type EvictedSbom struct {
+	SbomId            string `json:"sbom_id"`
+	Deleted           bool   `json:"deleted"`
+	ComponentsDeleted int64  `json:"components_deleted"`
+	Error             string `json:"error,omitempty"`
 }
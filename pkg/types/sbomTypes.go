@@ -2,24 +2,63 @@ package types
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
 
 	"github.com/CycloneDX/cyclonedx-go"
 )
 
 type SbomStore interface {
-	AddComponentSbom(sbom cyclonedx.BOM) (string, error)
+	AddComponentSbom(sbom Sbom) (string, error)
 	GetTotalCount(filter interface{}) (int64, error)
 	GetPaginatedSboms(page, limit, duration int) ([]Sbom, error)
 	GetSbomById(idParam string, duration int) (Sbom, error)
+	GetOriginalDocument(sbom Sbom) (io.ReadCloser, error)
 	GetSbomByName(name string, duration int) ([]Sbom, error)
 	DeleteByIds(idParams []string, duration int) (int64, error)
 	DeleteById(idParam string, duration int) (int64, error)
+	GetSbomBlobs(ids []string, duration int) ([]Sbom, error)
+	DeleteDocuments(ctx context.Context, ids []string) (int64, error)
+	DeleteBlobs(sboms []Sbom)
+	WithTransaction(duration int, fn func(ctx context.Context) error) (bool, error)
 	ValidateIds(ids []string) error
+	UpdateAnalysis(sbomId string, analysis SbomAnalysis, duration int) error
+	GetSbomRevisions(serialNumber string, duration int) ([]Sbom, error)
+	GetSearchFilter(query SearchQuery) interface{}
+	GetSbomsUsingFilter(filter interface{}, page, limit, duration int) ([]Sbom, error)
+	GetSbomIdsUsingFilter(filter interface{}, duration int) ([]string, error)
+	GetSbomQualities(ids []string, duration int) (map[string]*SbomQuality, error)
+	UpdateQuality(sbomId string, quality SbomQuality, duration int) error
+	UpdateSignature(sbomId, projectId string, signature SbomSignature, duration int) error
+	AddArtifactDigests(sbomId string, digests []string, duration int) error
+	LinkProvenance(sbomIds, digests []string, provenance Provenance, duration int) (int64, error)
+}
+
+var (
+	ErrDuplicateSbom        = fmt.Errorf("sbom with same content already exists")
+	ErrSbomRevisionConflict = fmt.Errorf("sbom with same serial number and version already exists with different content")
+	ErrSbomAnalysisRunning  = fmt.Errorf("sbom analysis is running, cancel its job or wait for it to finish before deleting sbom")
+)
+
+const (
+	SbomAnalysisComplete = "complete" // all components analyzed without errors
+	SbomAnalysisPartial  = "partial"  // some components failed analysis
+	SbomAnalysisFailed   = "failed"   // all components failed analysis
+)
+
+// SbomAnalysis stores completeness of sbom components analysis
+type SbomAnalysis struct {
+	Status           string    `json:"status" bson:"status"`
+	TotalComponents  int64     `json:"total_components" bson:"total_components"`
+	FailedComponents int64     `json:"failed_components" bson:"failed_components"`
+	AnalyzedAt       time.Time `json:"analyzed_at" bson:"analyzed_at"`
 }
 
 type Sbom struct {
-	Id string `json:"sbom_id" bson:"_id"`
+	Id string `json:"sbom_id" xml:"-" bson:"_id,omitempty"`
 
 	// XML specific fields
 	XMLName xml.Name `json:"-" xml:"bom"`
@@ -44,15 +83,205 @@ type Sbom struct {
 	Formulation        *[]cyclonedx.Formula           `json:"formulation,omitempty" xml:"formulation>formula,omitempty"`
 	Declarations       *cyclonedx.Declarations        `json:"declarations,omitempty" xml:"declarations,omitempty"`
 	Definitions        *cyclonedx.Definitions         `json:"definitions,omitempty" xml:"definitions,omitempty"`
+
+	// defect-detect specific fields
+	ContentHash        string                   `json:"content_hash,omitempty" xml:"-" bson:"content_hash,omitempty"`
+	PreviousRevisionId string                   `json:"previous_revision_id,omitempty" xml:"-" bson:"previous_revision_id,omitempty"` // sbom with same serial number and lower version
+	OriginalFormat     string                   `json:"original_format,omitempty" xml:"-" bson:"original_format,omitempty"`
+	OriginalDocument   string                   `json:"-" xml:"-" bson:"original_document,omitempty"` // uploaded document, only stored in db by sboms uploaded before blob storage was introduced
+	DocumentBlob       string                   `json:"-" xml:"-" bson:"document_blob,omitempty"`     // blob key of cyclonedx json of sbom
+	OriginalBlob       string                   `json:"-" xml:"-" bson:"original_blob,omitempty"`     // blob key of uploaded document
+	ConversionLoss     *ConversionLoss          `json:"conversion_loss,omitempty" xml:"-" bson:"conversion_loss,omitempty"`
+	Analysis           *SbomAnalysis            `json:"analysis,omitempty" xml:"-" bson:"analysis,omitempty"`
+	SourceSbomIds      []string                 `json:"source_sbom_ids,omitempty" xml:"-" bson:"source_sbom_ids,omitempty"` // sboms merged into this aggregate sbom
+	Quality            *SbomQuality             `json:"quality,omitempty" xml:"-" bson:"quality,omitempty"`
+	Signature          *SbomSignature           `json:"signature_verification,omitempty" xml:"-" bson:"signature_verification,omitempty"` // verification of upload which stored the sbom
+	ProjectSignatures  map[string]SbomSignature `json:"project_signatures,omitempty" xml:"-" bson:"project_signatures,omitempty"`         // verification using trusted keys of each project, keyed by project id
+	ArtifactDigests    []string                 `json:"artifact_digests,omitempty" xml:"-" bson:"artifact_digests,omitempty"`             // digests of artifact described by sbom, e.g. sha256:<hex>
+	Provenance         *Provenance              `json:"provenance,omitempty" xml:"-" bson:"provenance,omitempty"`
+}
+
+const (
+	SignatureTypeJsf      = "jsf"      // cyclonedx json signature embedded in sbom
+	SignatureTypeDetached = "detached" // signature of uploaded sbom file
+	SignatureTypeDsse     = "dsse"     // signature of in-toto attestation envelope carrying sbom
+)
+
+// SbomSignature stores result of verifying sbom signature using trusted keys of a project
+type SbomSignature struct {
+	Verified   bool      `json:"verified" bson:"verified"`
+	Type       string    `json:"type" bson:"type"`
+	Algorithm  string    `json:"algorithm,omitempty" bson:"algorithm,omitempty"`
+	KeyName    string    `json:"key_name,omitempty" bson:"key_name,omitempty"` // name of trusted key which verified signature
+	Signer     string    `json:"signer,omitempty" bson:"signer,omitempty"`     // certificate subject, jsf key id or dsse key id
+	Error      string    `json:"error,omitempty" bson:"error,omitempty"`
+	VerifiedAt time.Time `json:"verified_at" bson:"verified_at"`
+}
+
+const (
+	QualityCategoryNtia  = "ntia"  // NTIA minimum elements
+	QualityCategoryExtra = "extra" // checks beyond NTIA minimum elements
+)
+
+// SbomQuality scores sbom from 0 to 100 using NTIA minimum elements and extra checks
+type SbomQuality struct {
+	Score         int            `json:"score" bson:"score"`
+	NtiaCompliant bool           `json:"ntia_compliant" bson:"ntia_compliant"`
+	Checks        []QualityCheck `json:"checks" bson:"checks"`
+	ScoredAt      time.Time      `json:"scored_at" bson:"scored_at"`
+}
+
+// QualityCheck reports number of elements passing the check out of total checked elements
+type QualityCheck struct {
+	Name     string `json:"name" bson:"name"`
+	Category string `json:"category" bson:"category"`
+	Passed   int    `json:"passed" bson:"passed"`
+	Total    int    `json:"total" bson:"total"`
+	Score    int    `json:"score" bson:"score"`
+}
+
+const (
+	SbomValidationSchema   = "schema"   // sbom does not conform to cyclonedx schema
+	SbomValidationSemantic = "semantic" // sbom conforms to schema but is not usable
+)
+
+// SbomValidationError points to invalid part of sbom using json pointer
+type SbomValidationError struct {
+	Pointer string `json:"pointer"`
+	Type    string `json:"type"`
+	Message string `json:"message"`
 }
 
-type GithubRepoImportRequestSchema struct {
-	Owner    string `json:"owner" binding:"required"`
-	RepoName string `json:"repo_name" binding:"required"`
+// ConversionLoss reports data of the original document which is missing in converted cyclonedx sbom
+type ConversionLoss struct {
+	DroppedElements       []string       `json:"dropped_elements,omitempty" bson:"dropped_elements,omitempty"`
+	DroppedFields         []DroppedField `json:"dropped_fields,omitempty" bson:"dropped_fields,omitempty"`
+	UnmappedRelationships []string       `json:"unmapped_relationships,omitempty" bson:"unmapped_relationships,omitempty"`
 }
 
-type GithubRepoImportResponseSchema struct {
-	Sbom map[string]interface{}
+// DroppedField stores field and number of its values which were dropped during conversion
+type DroppedField struct {
+	Field string `json:"field" bson:"field"`
+	Count int    `json:"count" bson:"count"`
+}
+
+func (l *ConversionLoss) IsEmpty() bool {
+	return len(l.DroppedElements) == 0 && len(l.DroppedFields) == 0 && len(l.UnmappedRelationships) == 0
+}
+
+// NewSbom creates sbom to be stored from cyclonedx bom
+func NewSbom(bom *cyclonedx.BOM, originalFormat string) Sbom {
+	return Sbom{
+		XMLName:            bom.XMLName,
+		XMLNS:              bom.XMLNS,
+		JSONSchema:         bom.JSONSchema,
+		BOMFormat:          bom.BOMFormat,
+		SpecVersion:        bom.SpecVersion,
+		SerialNumber:       bom.SerialNumber,
+		Version:            bom.Version,
+		Metadata:           bom.Metadata,
+		Components:         bom.Components,
+		Services:           bom.Services,
+		ExternalReferences: bom.ExternalReferences,
+		Dependencies:       bom.Dependencies,
+		Compositions:       bom.Compositions,
+		Properties:         bom.Properties,
+		Vulnerabilities:    bom.Vulnerabilities,
+		Annotations:        bom.Annotations,
+		Formulation:        bom.Formulation,
+		Declarations:       bom.Declarations,
+		Definitions:        bom.Definitions,
+		OriginalFormat:     originalFormat,
+	}
+}
+
+// WithoutContent returns sbom without components and other bom elements, which are kept in blob
+// storage. Metadata is kept as sboms are searched using metadata.
+func (s Sbom) WithoutContent() Sbom {
+	s.Components = nil
+	s.Services = nil
+	s.ExternalReferences = nil
+	s.Dependencies = nil
+	s.Compositions = nil
+	s.Properties = nil
+	s.Vulnerabilities = nil
+	s.Annotations = nil
+	s.Formulation = nil
+	s.Declarations = nil
+	s.Definitions = nil
+	s.OriginalDocument = ""
+
+	return s
+}
+
+// SetContent sets components and other bom elements of sbom using bom read from blob storage
+func (s *Sbom) SetContent(bom *cyclonedx.BOM) {
+	s.Metadata = bom.Metadata
+	s.Components = bom.Components
+	s.Services = bom.Services
+	s.ExternalReferences = bom.ExternalReferences
+	s.Dependencies = bom.Dependencies
+	s.Compositions = bom.Compositions
+	s.Properties = bom.Properties
+	s.Vulnerabilities = bom.Vulnerabilities
+	s.Annotations = bom.Annotations
+	s.Formulation = bom.Formulation
+	s.Declarations = bom.Declarations
+	s.Definitions = bom.Definitions
+}
+
+// ToBom returns cyclonedx bom of stored sbom without defect-detect specific fields
+func (s Sbom) ToBom() *cyclonedx.BOM {
+	return &cyclonedx.BOM{
+		XMLName:            s.XMLName,
+		XMLNS:              s.XMLNS,
+		JSONSchema:         s.JSONSchema,
+		BOMFormat:          s.BOMFormat,
+		SpecVersion:        s.SpecVersion,
+		SerialNumber:       s.SerialNumber,
+		Version:            s.Version,
+		Metadata:           s.Metadata,
+		Components:         s.Components,
+		Services:           s.Services,
+		ExternalReferences: s.ExternalReferences,
+		Dependencies:       s.Dependencies,
+		Compositions:       s.Compositions,
+		Properties:         s.Properties,
+		Vulnerabilities:    s.Vulnerabilities,
+		Annotations:        s.Annotations,
+		Formulation:        s.Formulation,
+		Declarations:       s.Declarations,
+		Definitions:        s.Definitions,
+	}
+}
+
+// MergeSbomsRequestSchema describes root component of aggregate sbom built from stored sboms
+type MergeSbomsRequestSchema struct {
+	SbomIds []string `json:"sbom_ids" binding:"required,min=2,max=50,unique"`
+	Name    string   `json:"name" binding:"required"`
+	Version string   `json:"version"`
+	Group   string   `json:"group"`
+	Purl    string   `json:"purl"`
+}
+
+// DeleteSbomsRequestSchema lists sboms to be deleted along with their components and findings
+type DeleteSbomsRequestSchema struct {
+	SbomIds []string `json:"sbom_ids" binding:"required,min=1,max=100,unique"`
+	DryRun  bool     `json:"dry_run"`
+}
+
+// SbomDeletion reports deleted sboms, their components and findings, and projects sboms were
+// removed from. Dry runs report what would be deleted.
+type SbomDeletion struct {
+	DryRun            bool     `json:"dry_run"`
+	Transaction       bool     `json:"transaction"` // false for dry runs and servers without transaction support
+	SbomIds           []string `json:"sbom_ids"`
+	NotFound          []string `json:"not_found"`
+	ComponentsDeleted int64    `json:"components_deleted"`
+	FindingsDeleted   int64    `json:"findings_deleted"`
+	ProjectIds        []string `json:"project_ids"`
+	CancelledJobIds   []string `json:"cancelled_job_ids"` // queued jobs of sboms
+	RunningJobIds     []string `json:"running_job_ids"`   // sboms can not be deleted while their jobs are running
 }
 
 type ReadSeekCloser struct {
@@ -75,3 +304,47 @@ func (w *WriteCloser) Close() error {
 	// No-op: bytes.Buffer doesn't need to be closed
 	return nil
 }
+
+// SbomDiff lists changes between base and head sboms. Components are matched by purl without
+// version, falling back to group and name for components without purl.
+type SbomDiff struct {
+	BaseSbomId      string                `json:"base_sbom_id"`
+	HeadSbomId      string                `json:"head_sbom_id"`
+	Added           []DiffComponent       `json:"added"`
+	Removed         []DiffComponent       `json:"removed"`
+	VersionChanged  []DiffComponentChange `json:"version_changed"`
+	LicenseChanged  []DiffComponentChange `json:"license_changed"`
+	IntroducedVulns []DiffVuln            `json:"introduced_vulns"`
+	ResolvedVulns   []DiffVuln            `json:"resolved_vulns"`
+
+	// vulns are read from stored components, so they are incomplete until both sboms are analyzed
+	BaseAnalyzed bool `json:"base_analyzed"`
+	HeadAnalyzed bool `json:"head_analyzed"`
+}
+
+type DiffComponent struct {
+	Key      string   `json:"key"`
+	Name     string   `json:"name"`
+	Group    string   `json:"group,omitempty"`
+	Purl     string   `json:"purl,omitempty"`
+	Versions []string `json:"versions"`
+	Licenses []string `json:"licenses"`
+}
+
+type DiffComponentChange struct {
+	Key          string   `json:"key"`
+	Name         string   `json:"name"`
+	Group        string   `json:"group,omitempty"`
+	BaseVersions []string `json:"base_versions"`
+	HeadVersions []string `json:"head_versions"`
+	BaseLicenses []string `json:"base_licenses"`
+	HeadLicenses []string `json:"head_licenses"`
+}
+
+type DiffVuln struct {
+	Id               string `json:"id"`
+	Summary          string `json:"summary,omitempty"`
+	ComponentKey     string `json:"component_key"`
+	ComponentName    string `json:"component_name"`
+	ComponentVersion string `json:"component_version"`
+}
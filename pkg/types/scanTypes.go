@@ -0,0 +1,57 @@
+package types
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	ScanStatusRunning   = "running"
+	ScanStatusSucceeded = "succeeded"
+	ScanStatusFailed    = "failed"
+
+	ScanTriggerScheduled = "scheduled"
+)
+
+type ScanStore interface {
+	AddScanRun(run ScanRun) (string, error)
+	UpdateScanRun(run ScanRun, duration int) error
+	GetTotalCount(filter interface{}) (int64, error)
+	GetScanRunsUsingFilter(filter interface{}, page, limit, duration int) ([]ScanRun, error)
+	AcquireLock(lockId, owner string, ttl time.Duration) (bool, error)
+}
+
+type ProjectScanner interface {
+	RescanProject(ctx context.Context, request ProjectRescanJob, jobId string, progress ProgressFunc) (ScanRun, error)
+}
+
+// ProjectRescanJob describes scheduled activation rescanned by rescan_project jobs
+type ProjectRescanJob struct {
+	ProjectId string `json:"project_id" bson:"project_id"`
+	Schedule  string `json:"schedule" bson:"schedule"`
+}
+
+// ScanRun records a single background re-analysis of a project's retained sboms
+type ScanRun struct {
+	Id                string    `json:"scan_id" bson:"_id,omitempty"`
+	ProjectId         string    `json:"project_id" bson:"project_id"`
+	JobId             string    `json:"job_id,omitempty" bson:"job_id,omitempty"`
+	SbomIds           []string  `json:"sbom_ids" bson:"sbom_ids"`
+	SkippedSbomIds    []string  `json:"skipped_sbom_ids,omitempty" bson:"skipped_sbom_ids,omitempty"` // sboms which had another active job
+	Trigger           string    `json:"trigger" bson:"trigger"`
+	Schedule          string    `json:"schedule" bson:"schedule"`
+	Status            string    `json:"status" bson:"status"`
+	Error             string    `json:"error,omitempty" bson:"error,omitempty"`
+	ComponentsScanned int       `json:"components_scanned" bson:"components_scanned"`
+	Owner             string    `json:"owner" bson:"owner"`
+	StartedAt         time.Time `json:"started_at" bson:"started_at"`
+	FinishedAt        time.Time `json:"finished_at,omitempty" bson:"finished_at,omitempty"`
+	DurationMs        int64     `json:"duration_ms" bson:"duration_ms"`
+}
+
+// ScanLock is used to make sure a scheduled slot is only executed by a single replica
+type ScanLock struct {
+	Id        string    `bson:"_id"`
+	Owner     string    `bson:"owner"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
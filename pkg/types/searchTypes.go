@@ -0,0 +1,76 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+)
+
+const (
+	SearchScopeAll        = "all"
+	SearchScopeSboms      = "sboms"
+	SearchScopeComponents = "components"
+)
+
+// max length of name regex to avoid expensive queries
+const maxSearchRegexLength = 256
+
+// SearchQuery holds criteria used to search sboms and components, empty criteria are ignored
+type SearchQuery struct {
+	Name       string // exact name
+	NamePrefix string
+	NameRegex  string
+
+	// purl without version matches all versions of the package
+	Purl string
+
+	Hash          string
+	HashAlgorithm string
+	Supplier      string
+
+	// sbom metadata properties, such as git commit
+	Properties []SearchProperty
+}
+
+type SearchProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+func (q SearchQuery) IsEmpty() bool {
+	return q.Name == "" && q.NamePrefix == "" && q.NameRegex == "" && q.Purl == "" && q.Hash == "" && q.Supplier == "" && len(q.Properties) == 0
+}
+
+func (q SearchQuery) Validate() error {
+	if q.IsEmpty() {
+		return fmt.Errorf("at least one search criteria is required")
+	}
+
+	names := 0
+	for _, name := range []string{q.Name, q.NamePrefix, q.NameRegex} {
+		if name != "" {
+			names++
+		}
+	}
+	if names > 1 {
+		return fmt.Errorf("only one of name, name_prefix and name_regex can be used")
+	}
+
+	if len(q.NameRegex) > maxSearchRegexLength {
+		return fmt.Errorf("name_regex can not be longer than %d characters", maxSearchRegexLength)
+	}
+	if _, err := regexp.Compile(q.NameRegex); err != nil {
+		return fmt.Errorf("invalid name_regex: %v", err)
+	}
+
+	if q.HashAlgorithm != "" && q.Hash == "" {
+		return fmt.Errorf("hash_alg requires hash")
+	}
+
+	for _, property := range q.Properties {
+		if property.Name == "" {
+			return fmt.Errorf("property name is required")
+		}
+	}
+
+	return nil
+}
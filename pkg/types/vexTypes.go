@@ -0,0 +1,65 @@
+package types
+
+import "time"
+
+const (
+	VexStatusNotAffected        = "not_affected"
+	VexStatusAffected           = "affected"
+	VexStatusFixed              = "fixed"
+	VexStatusUnderInvestigation = "under_investigation"
+)
+
+// findings with these vex statuses are hidden from vulnerable components unless requested
+var VexSuppressedStatuses = []string{VexStatusNotAffected, VexStatusFixed}
+
+type VexStore interface {
+	UpsertStatements(statements []VexStatement) ([]string, error)
+	GetStatementsByVulnIds(vulnIds []string, duration int) ([]VexStatement, error)
+	GetTotalCount(filter interface{}) (int64, error)
+	GetStatementsUsingFilter(filter interface{}, page, limit, duration int) ([]VexStatement, error)
+}
+
+// VexStatement states impact of a vulnerability on a product, parsed from uploaded
+// CycloneDX VEX or OpenVEX document
+type VexStatement struct {
+	Id         string `json:"id" bson:"_id,omitempty"`
+	DocumentId string `json:"document_id" bson:"document_id"` // serial number or @id of uploaded document, content hash when it has neither
+	Format     string `json:"format" bson:"format"`
+	Key        string `json:"-" bson:"statement_key,omitempty"` // hash of document id, vuln id and product, statements of re-uploaded documents replace stored ones
+
+	VulnId  string   `json:"vuln_id" bson:"vuln_id"`
+	Aliases []string `json:"aliases,omitempty" bson:"aliases,omitempty"`
+
+	// statement applies to components matching product purl, or to components matching
+	// subcomponent purls in sboms whose root component matches product purl
+	ProductPurl       string   `json:"product_purl" bson:"product_purl"`
+	SubcomponentPurls []string `json:"subcomponent_purls,omitempty" bson:"subcomponent_purls,omitempty"`
+
+	Status          string    `json:"status" bson:"status"`
+	Justification   string    `json:"justification,omitempty" bson:"justification,omitempty"`
+	ImpactStatement string    `json:"impact_statement,omitempty" bson:"impact_statement,omitempty"`
+	ActionStatement string    `json:"action_statement,omitempty" bson:"action_statement,omitempty"`
+	Author          string    `json:"author,omitempty" bson:"author,omitempty"`
+	Timestamp       time.Time `json:"timestamp" bson:"timestamp"`
+	CreatedAt       time.Time `json:"created_at" bson:"created_at"`
+}
+
+// VexAnalysis is latest vex statement applied to a component vuln
+type VexAnalysis struct {
+	StatementId     string    `json:"statement_id" bson:"statement_id"`
+	Status          string    `json:"status" bson:"status"`
+	Justification   string    `json:"justification,omitempty" bson:"justification,omitempty"`
+	ImpactStatement string    `json:"impact_statement,omitempty" bson:"impact_statement,omitempty"`
+	ActionStatement string    `json:"action_statement,omitempty" bson:"action_statement,omitempty"`
+	Author          string    `json:"author,omitempty" bson:"author,omitempty"`
+	Timestamp       time.Time `json:"timestamp" bson:"timestamp"`
+}
+
+// returns true when both analyses are of the same statement content, timestamps are compared
+// as instants since stored ones are decoded in UTC
+func (a VexAnalysis) Equal(other VexAnalysis) bool {
+	timestamp, otherTimestamp := a.Timestamp, other.Timestamp
+	a.Timestamp, other.Timestamp = time.Time{}, time.Time{}
+
+	return a == other && timestamp.Equal(otherTimestamp)
+}
@@ -77,7 +77,7 @@ func ExcludeParamsFromStruct(data interface{}, params []string) (bson.M, error)
 		fieldValue := v.Field(i)
 
 		// Skip the field if match is found
-		if slices.Contains(params, strings.ToLower(field.Name)) || field.Tag.Get("bson") == "_id" {
+		if slices.Contains(params, strings.ToLower(field.Name)) || field.Tag.Get("bson") == "_id" || field.Tag.Get("bson") == "-" {
 			continue
 		}
 
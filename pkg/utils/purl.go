@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"strings"
+
+	"github.com/package-url/packageurl-go"
+)
+
+// returns purl without version, qualifiers and subpath so different versions of a package
+// have same value. Empty string is returned for invalid purls.
+func CanonicalPurl(purl string) string {
+	if purl == "" {
+		return ""
+	}
+
+	parsed, err := packageurl.FromString(purl)
+	if err != nil {
+		return ""
+	}
+
+	return packageurl.NewPackageURL(strings.ToLower(parsed.Type), parsed.Namespace, parsed.Name, "", nil, "").ToString()
+}
+
+// returns true when purl refers to package of pattern purl. Pattern without version matches
+// all versions of the package, qualifiers and subpath are ignored.
+func PurlMatches(pattern, purl string) bool {
+	patternKey := CanonicalPurl(pattern)
+	if patternKey == "" || patternKey != CanonicalPurl(purl) {
+		return false
+	}
+
+	parsedPattern, _ := packageurl.FromString(pattern)
+	if parsedPattern.Version == "" {
+		return true
+	}
+
+	parsedPurl, _ := packageurl.FromString(purl)
+	return parsedPattern.Version == parsedPurl.Version
+}
+
+// returns false for valid purls without version. Such purls match every version of the package
+// so vulns of all historical releases would be reported for them.
+func PurlHasVersion(purl string) bool {
+	parsed, err := packageurl.FromString(purl)
+	return err != nil || parsed.Version != ""
+}
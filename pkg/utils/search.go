@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+	"github.com/package-url/packageurl-go"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// returns filter matching name of search query, nil when query does not have name criteria.
+// Prefix is matched using anchored regex so that index on name field can be used.
+func BuildNameFilter(query types.SearchQuery) interface{} {
+	switch {
+	case query.Name != "":
+		return query.Name
+	case query.NamePrefix != "":
+		return primitive.Regex{Pattern: "^" + regexp.QuoteMeta(query.NamePrefix)}
+	case query.NameRegex != "":
+		return primitive.Regex{Pattern: query.NameRegex}
+	}
+
+	return nil
+}
+
+// returns filter matching purl. Purls with version are matched exactly, while purls without
+// version match all versions, qualifiers and subpaths of the package.
+func BuildPurlFilter(purl string) interface{} {
+	parsed, err := packageurl.FromString(purl)
+	if err != nil || parsed.Version != "" {
+		return purl
+	}
+
+	return primitive.Regex{Pattern: "^" + regexp.QuoteMeta(strings.TrimSuffix(purl, "/")) + `([@?#]|$)`}
+}
+
+// returns $elemMatch filter for cyclonedx hashes. Hex digests are matched in both given and
+// lower case since boms are not consistent about it.
+func BuildHashFilter(hash, algorithm string) bson.M {
+	elemMatch := bson.M{"value": bson.M{"$in": []string{hash, strings.ToLower(hash)}}}
+	if algorithm != "" {
+		elemMatch["algorithm"] = algorithm
+	}
+
+	return bson.M{"$elemMatch": elemMatch}
+}
@@ -40,3 +40,19 @@ func FindRegexMatchEle(pattern string, s []string) string {
 
 	return ""
 }
+
+// returns elements of slices without duplicates, keeping order of first occurrence
+func MergeUnique(slices ...[]string) []string {
+	seen := map[string]bool{}
+	merged := []string{}
+	for _, slice := range slices {
+		for _, ele := range slice {
+			if !seen[ele] {
+				seen[ele] = true
+				merged = append(merged, ele)
+			}
+		}
+	}
+
+	return merged
+}
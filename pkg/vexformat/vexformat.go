@@ -0,0 +1,328 @@
+package vexformat
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/sbomformat"
+	"github.com/dmdhrumilmistry/defect-detect/pkg/types"
+)
+
+type Format string
+
+const (
+	OpenVex      Format = "openvex"
+	CycloneDxVex Format = "cyclonedx-vex"
+)
+
+var ErrUnknownFormat = fmt.Errorf("unable to detect vex format")
+
+// cyclonedx impact analysis states mapped to vex statuses
+var cycloneDxStatuses = map[cyclonedx.ImpactAnalysisState]string{
+	cyclonedx.IASNotAffected:          types.VexStatusNotAffected,
+	cyclonedx.IASFalsePositive:        types.VexStatusNotAffected,
+	cyclonedx.IASResolved:             types.VexStatusFixed,
+	cyclonedx.IASResolvedWithPedigree: types.VexStatusFixed,
+	cyclonedx.IASExploitable:          types.VexStatusAffected,
+	cyclonedx.IASInTriage:             types.VexStatusUnderInvestigation,
+}
+
+var openVexStatuses = map[string]bool{
+	types.VexStatusNotAffected:        true,
+	types.VexStatusAffected:           true,
+	types.VexStatusFixed:              true,
+	types.VexStatusUnderInvestigation: true,
+}
+
+// returns true for json documents with openvex context
+func isOpenVex(content []byte) bool {
+	var header struct {
+		Context string `json:"@context"`
+	}
+	if err := json.Unmarshal(content, &header); err != nil {
+		return false
+	}
+
+	return strings.Contains(header.Context, "openvex")
+}
+
+// documents without serial number or @id are identified by hash of their content, so uploading
+// the same document again replaces its statements
+func setDocumentIds(statements []types.VexStatement, content []byte) {
+	hash := sha256.Sum256(content)
+	for i := range statements {
+		if statements[i].DocumentId == "" {
+			statements[i].DocumentId = "sha256:" + hex.EncodeToString(hash[:])
+		}
+	}
+}
+
+// Parse detects format of vex document and returns its statements. Statements which can not
+// be applied, such as ones without purl products, are reported as skipped.
+func Parse(content []byte, contentType, fileName string) (Format, []types.VexStatement, []string, error) {
+	if isOpenVex(bytes.TrimSpace(content)) {
+		statements, skipped, err := parseOpenVex(content)
+		setDocumentIds(statements, content)
+		return OpenVex, statements, skipped, err
+	}
+
+	format, err := sbomformat.Detect(content, contentType, fileName)
+	if err != nil || format.IsSpdx() {
+		return "", nil, nil, ErrUnknownFormat
+	}
+
+	bom, err := sbomformat.Decode(content, format)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	statements, skipped := parseCycloneDxVex(bom)
+	setDocumentIds(statements, content)
+	return CycloneDxVex, statements, skipped, nil
+}
+
+// parses rfc3339 timestamp, returns current time for empty or invalid timestamps
+func parseTimestamp(timestamps ...string) time.Time {
+	for _, timestamp := range timestamps {
+		if parsed, err := time.Parse(time.RFC3339, timestamp); err == nil {
+			return parsed
+		}
+	}
+
+	return time.Now()
+}
+
+// indexes purls of bom components using their bom-ref
+func indexPurls(components *[]cyclonedx.Component, purls map[string]string) {
+	if components == nil {
+		return
+	}
+
+	for _, component := range *components {
+		if component.BOMRef != "" && component.PackageURL != "" {
+			purls[component.BOMRef] = component.PackageURL
+		}
+		indexPurls(component.Components, purls)
+	}
+}
+
+func parseCycloneDxVex(bom *cyclonedx.BOM) ([]types.VexStatement, []string) {
+	statements := []types.VexStatement{}
+	skipped := []string{}
+	if bom.Vulnerabilities == nil {
+		return statements, skipped
+	}
+
+	purls := map[string]string{}
+	if bom.Metadata != nil && bom.Metadata.Component != nil {
+		indexPurls(&[]cyclonedx.Component{*bom.Metadata.Component}, purls)
+	}
+	indexPurls(bom.Components, purls)
+
+	var documentTimestamp string
+	if bom.Metadata != nil {
+		documentTimestamp = bom.Metadata.Timestamp
+	}
+
+	for _, vulnerability := range *bom.Vulnerabilities {
+		if vulnerability.Analysis == nil || vulnerability.Affects == nil {
+			skipped = append(skipped, fmt.Sprintf("%s: analysis and affects are required", vulnerability.ID))
+			continue
+		}
+
+		status, exists := cycloneDxStatuses[vulnerability.Analysis.State]
+		if !exists {
+			skipped = append(skipped, fmt.Sprintf("%s: unsupported analysis state %q", vulnerability.ID, vulnerability.Analysis.State))
+			continue
+		}
+
+		var aliases []string
+		if vulnerability.References != nil {
+			for _, reference := range *vulnerability.References {
+				if reference.ID != "" {
+					aliases = append(aliases, reference.ID)
+				}
+			}
+		}
+
+		var actionStatement string
+		if vulnerability.Analysis.Response != nil {
+			responses := []string{}
+			for _, response := range *vulnerability.Analysis.Response {
+				responses = append(responses, string(response))
+			}
+			actionStatement = strings.Join(responses, ", ")
+		}
+
+		for _, affects := range *vulnerability.Affects {
+			purl, exists := purls[affects.Ref]
+			if !exists && strings.HasPrefix(affects.Ref, "pkg:") {
+				purl = affects.Ref
+			}
+			if purl == "" {
+				// bom-links to components of other boms can not be resolved
+				skipped = append(skipped, fmt.Sprintf("%s: affected ref %q does not have purl", vulnerability.ID, affects.Ref))
+				continue
+			}
+
+			statements = append(statements, types.VexStatement{
+				DocumentId:      bom.SerialNumber,
+				Format:          string(CycloneDxVex),
+				VulnId:          vulnerability.ID,
+				Aliases:         aliases,
+				ProductPurl:     purl,
+				Status:          status,
+				Justification:   string(vulnerability.Analysis.Justification),
+				ImpactStatement: vulnerability.Analysis.Detail,
+				ActionStatement: actionStatement,
+				Timestamp:       parseTimestamp(vulnerability.Analysis.LastUpdated, vulnerability.Analysis.FirstIssued, documentTimestamp),
+			})
+		}
+	}
+
+	return statements, skipped
+}
+
+// openvex documents before v0.2.0 used plain strings for vulnerability and products
+type openVexDocument struct {
+	Id         string             `json:"@id"`
+	Author     string             `json:"author"`
+	Timestamp  string             `json:"timestamp"`
+	Statements []openVexStatement `json:"statements"`
+}
+
+type openVexStatement struct {
+	Vulnerability   json.RawMessage   `json:"vulnerability"`
+	Products        []json.RawMessage `json:"products"`
+	Subcomponents   []json.RawMessage `json:"subcomponents"`
+	Status          string            `json:"status"`
+	Justification   string            `json:"justification"`
+	ImpactStatement string            `json:"impact_statement"`
+	ActionStatement string            `json:"action_statement"`
+	Timestamp       string            `json:"timestamp"`
+}
+
+type openVexVulnerability struct {
+	Id      string   `json:"@id"`
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases"`
+}
+
+type openVexComponent struct {
+	Id            string            `json:"@id"`
+	Identifiers   map[string]string `json:"identifiers"`
+	Subcomponents []json.RawMessage `json:"subcomponents"`
+}
+
+func parseOpenVexVulnerability(raw json.RawMessage) (string, []string) {
+	var name string
+	if err := json.Unmarshal(raw, &name); err == nil {
+		return name, nil
+	}
+
+	var vulnerability openVexVulnerability
+	if err := json.Unmarshal(raw, &vulnerability); err != nil {
+		return "", nil
+	}
+
+	if vulnerability.Name == "" {
+		return vulnerability.Id, vulnerability.Aliases
+	}
+
+	return vulnerability.Name, vulnerability.Aliases
+}
+
+// returns purl of openvex product or subcomponent along with its subcomponents
+func parseOpenVexComponent(raw json.RawMessage) (string, []json.RawMessage) {
+	var id string
+	if err := json.Unmarshal(raw, &id); err == nil {
+		if strings.HasPrefix(id, "pkg:") {
+			return id, nil
+		}
+		return "", nil
+	}
+
+	var component openVexComponent
+	if err := json.Unmarshal(raw, &component); err != nil {
+		return "", nil
+	}
+
+	if purl := component.Identifiers["purl"]; purl != "" {
+		return purl, component.Subcomponents
+	}
+	if strings.HasPrefix(component.Id, "pkg:") {
+		return component.Id, component.Subcomponents
+	}
+
+	return "", component.Subcomponents
+}
+
+func parseOpenVex(content []byte) ([]types.VexStatement, []string, error) {
+	var document openVexDocument
+	if err := json.Unmarshal(content, &document); err != nil {
+		return nil, nil, err
+	}
+
+	statements := []types.VexStatement{}
+	skipped := []string{}
+	for i, statement := range document.Statements {
+		vulnId, aliases := parseOpenVexVulnerability(statement.Vulnerability)
+		if vulnId == "" {
+			skipped = append(skipped, fmt.Sprintf("statement %d: vulnerability is required", i))
+			continue
+		}
+
+		if !openVexStatuses[statement.Status] {
+			skipped = append(skipped, fmt.Sprintf("%s: unsupported status %q", vulnId, statement.Status))
+			continue
+		}
+
+		for _, rawProduct := range statement.Products {
+			productPurl, rawSubcomponents := parseOpenVexComponent(rawProduct)
+			if productPurl == "" {
+				skipped = append(skipped, fmt.Sprintf("%s: product %s does not have purl", vulnId, string(rawProduct)))
+				continue
+			}
+
+			// subcomponents were part of statement before v0.2.0
+			rawSubcomponents = append(rawSubcomponents, statement.Subcomponents...)
+
+			var subcomponentPurls []string
+			for _, rawSubcomponent := range rawSubcomponents {
+				if subcomponentPurl, _ := parseOpenVexComponent(rawSubcomponent); subcomponentPurl != "" {
+					subcomponentPurls = append(subcomponentPurls, subcomponentPurl)
+				} else {
+					skipped = append(skipped, fmt.Sprintf("%s: subcomponent %s does not have purl", vulnId, string(rawSubcomponent)))
+				}
+			}
+
+			if len(rawSubcomponents) > 0 && len(subcomponentPurls) == 0 {
+				// applying statement to whole product would suppress unrelated findings
+				continue
+			}
+
+			statements = append(statements, types.VexStatement{
+				DocumentId:        document.Id,
+				Format:            string(OpenVex),
+				VulnId:            vulnId,
+				Aliases:           aliases,
+				ProductPurl:       productPurl,
+				SubcomponentPurls: subcomponentPurls,
+				Status:            statement.Status,
+				Justification:     statement.Justification,
+				ImpactStatement:   statement.ImpactStatement,
+				ActionStatement:   statement.ActionStatement,
+				Author:            document.Author,
+				Timestamp:         parseTimestamp(statement.Timestamp, document.Timestamp),
+			})
+		}
+	}
+
+	return statements, skipped, nil
+}